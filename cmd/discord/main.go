@@ -23,10 +23,26 @@ import (
 	"github.com/keshon/server-domme/internal/command/core/maintenance"
 	"github.com/keshon/server-domme/internal/command/discipline"
 	"github.com/keshon/server-domme/internal/command/media"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/command/music/crossfade"
+	"github.com/keshon/server-domme/internal/command/music/filter"
 	"github.com/keshon/server-domme/internal/command/music/history"
+	"github.com/keshon/server-domme/internal/command/music/loop"
+	"github.com/keshon/server-domme/internal/command/music/manage"
 	"github.com/keshon/server-domme/internal/command/music/next"
+	"github.com/keshon/server-domme/internal/command/music/nowplaying"
+	"github.com/keshon/server-domme/internal/command/music/pause"
 	"github.com/keshon/server-domme/internal/command/music/play"
+	"github.com/keshon/server-domme/internal/command/music/playlist"
+	"github.com/keshon/server-domme/internal/command/music/queue"
+	"github.com/keshon/server-domme/internal/command/music/radio"
+	"github.com/keshon/server-domme/internal/command/music/record"
+	"github.com/keshon/server-domme/internal/command/music/resume"
+	"github.com/keshon/server-domme/internal/command/music/seek"
+	"github.com/keshon/server-domme/internal/command/music/soundboard"
+	"github.com/keshon/server-domme/internal/command/music/stats"
 	"github.com/keshon/server-domme/internal/command/music/stop"
+	"github.com/keshon/server-domme/internal/command/music/volume"
 	"github.com/keshon/server-domme/internal/command/purge"
 	"github.com/keshon/server-domme/internal/command/roll"
 	"github.com/keshon/server-domme/internal/command/shortlink"
@@ -44,7 +60,7 @@ func registerCommands(bot *discord.Bot, log zerolog.Logger) {
 	command.Register(&about.About{}, mw...)
 	command.Register(&help.Help{}, mw...)
 	command.Register(&commands.Commands{}, mw...)
-	command.Register(&maintenance.Maintenance{}, mw...)
+	command.Register(&maintenance.Maintenance{Bot: bot}, mw...)
 
 	command.Register(&announce.AnnounceCommand{}, mw...)
 	command.Register(&announce.ManageAnnounceCommand{}, mw...)
@@ -62,7 +78,7 @@ func registerCommands(bot *discord.Bot, log zerolog.Logger) {
 	command.Register(&media.UploadMediaCommand{}, mw...)
 	command.Register(&media.ManageMediaCommand{}, mw...)
 
-	command.Register(&purge.PurgeCommand{}, mw...)
+	command.Register(&purge.PurgeCommand{Bot: bot}, mw...)
 	command.Register(&roll.RollCommand{}, mw...)
 	command.Register(&shortlink.ShortlinkCommand{}, mw...)
 
@@ -76,6 +92,23 @@ func registerCommands(bot *discord.Bot, log zerolog.Logger) {
 	command.Register(&next.Next{Bot: bot}, mw...)
 	command.Register(&stop.Stop{Bot: bot}, mw...)
 	command.Register(&history.History{Bot: bot}, mw...)
+	command.Register(&stats.MusicStats{Bot: bot}, mw...)
+	command.Register(&manage.ManageMusicCommand{}, mw...)
+	command.Register(&queue.Queue{Bot: bot}, mw...)
+	command.Register(&pause.Pause{Bot: bot}, mw...)
+	command.Register(&resume.Resume{Bot: bot}, mw...)
+	command.Register(&seek.Seek{Bot: bot}, mw...)
+	command.Register(&loop.Loop{Bot: bot}, mw...)
+	command.Register(&volume.Volume{Bot: bot}, mw...)
+	command.Register(&crossfade.Crossfade{Bot: bot}, mw...)
+	command.Register(&filter.Filter{Bot: bot}, mw...)
+	command.Register(&nowplaying.NowPlaying{Bot: bot}, mw...)
+	command.Register(&playlist.Playlist{Bot: bot}, mw...)
+	command.Register(&radio.Radio{Bot: bot}, mw...)
+	command.Register(&soundboard.Soundboard{Bot: bot}, mw...)
+	command.Register(&soundboard.Sfx{Bot: bot}, mw...)
+	command.Register(&record.Record{Bot: bot}, mw...)
+	bot.SetPanelRenderer(common.RenderPlaybackPanel)
 }
 
 func main() {
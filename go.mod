@@ -2,7 +2,11 @@ module github.com/keshon/server-domme
 
 go 1.26
 
-require github.com/keshon/datastore v0.1.1
+require (
+	github.com/keshon/datastore v0.1.1
+	github.com/rs/zerolog v1.35.1
+	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+)
 
 require (
 	github.com/godeps/opus v1.0.3
@@ -22,11 +26,9 @@ require (
 	github.com/google/pprof v0.0.0-20260402051712-545e8a4df936 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.22 // indirect
-	github.com/rs/zerolog v1.35.1 // indirect
 	golang.org/x/crypto v0.50.0 // indirect
 	golang.org/x/sys v0.43.0 // indirect
 	golang.org/x/text v0.36.0 // indirect
-	gopkg.in/natefinch/lumberjack.v2 v2.2.1 // indirect
 )
 
 require (
@@ -43,3 +45,5 @@ require (
 )
 
 replace github.com/bwmarrin/discordgo => ./pkg/discordgo-fork-dev
+
+replace github.com/keshon/melodix => ./pkg/melodix-fork-dev
@@ -48,3 +48,10 @@ func (a *Adapter) Component(ctx *ComponentInteractionContext) error {
 	}
 	return nil
 }
+
+func (a *Adapter) Autocomplete(ctx *AutocompleteInteractionContext) error {
+	if ah, ok := a.Cmd.(AutocompleteHandler); ok {
+		return ah.Autocomplete(ctx)
+	}
+	return nil
+}
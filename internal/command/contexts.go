@@ -33,6 +33,14 @@ type ComponentInteractionContext struct {
 	AppLog    zerolog.Logger
 }
 
+type AutocompleteInteractionContext struct {
+	Session *discordgo.Session
+	Event   *discordgo.InteractionCreate
+	Storage *storage.Storage
+	Config  *config.Config
+	AppLog  zerolog.Logger
+}
+
 type MessageReactionContext struct {
 	Session *discordgo.Session
 	Event   *discordgo.MessageReactionAdd
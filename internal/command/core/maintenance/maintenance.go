@@ -5,10 +5,13 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord"
 	"github.com/keshon/server-domme/internal/discord/discordreply"
 )
 
-type Maintenance struct{}
+type Maintenance struct {
+	Bot discord.VoiceAPI
+}
 
 func (c *Maintenance) Name() string        { return "maintenance" }
 func (c *Maintenance) Description() string { return "Bot maintenance commands" }
@@ -38,6 +41,11 @@ func (c *Maintenance) SlashDefinition() *discordgo.ApplicationCommand {
 				Name:        "status",
 				Description: "Retrieve statistics about the guild",
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "voice",
+				Description: "Show voice transport metrics for this server",
+			},
 		},
 	}
 }
@@ -68,6 +76,8 @@ func (c *Maintenance) Run(ctx interface{}) error {
 		return runDownloadDB(s, e, *storage)
 	case "status":
 		return runStatus(s, e, *storage)
+	case "voice":
+		return runVoice(s, e, c.Bot, context.Config)
 	default:
 		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Description: fmt.Sprintf("Unknown subcommand: %s", sub.Name),
@@ -0,0 +1,67 @@
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/config"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/voice"
+)
+
+func runVoice(s *discordgo.Session, e *discordgo.InteractionCreate, bot discord.VoiceAPI, cfg *config.Config) error {
+	if bot == nil {
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "Voice is not available on this bot.",
+			Color:       discordreply.EmbedColor,
+		})
+	}
+	m, channelID, ok := bot.VoiceMetrics(e.GuildID)
+	if !ok {
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "No voice activity in this server since the bot started.",
+			Color:       discordreply.EmbedColor,
+		})
+	}
+
+	return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+		Title:       "🔊 Voice Transport",
+		Description: formatVoiceMetrics(m, channelID, cfg),
+		Color:       discordreply.EmbedColor,
+	})
+}
+
+func formatVoiceMetrics(m voice.VoiceMetrics, channelID string, cfg *config.Config) string {
+	connected := "not connected"
+	if channelID != "" {
+		connected = "<#" + channelID + ">"
+	}
+	mode := "hard"
+	if cfg != nil && cfg.PlayerTransportRecoveryMode != "" {
+		mode = cfg.PlayerTransportRecoveryMode
+	}
+	lastStream := "none yet"
+	if m.Streams > 0 {
+		lastStream = fmt.Sprintf("<t:%d:R>", m.LastStreamEnd.Unix())
+		if m.LastStreamErr != "" {
+			lastStream += fmt.Sprintf(" with `%s`", m.LastStreamErr)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Channel:** %s\n", connected)
+	fmt.Fprintf(&b, "**Joins:** %d (last %s, avg %s, max %s)\n", m.Joins, ms(m.LastJoin), ms(m.AvgJoin()), ms(m.MaxJoin))
+	fmt.Fprintf(&b, "**Frames sent:** %d (%s of audio)\n", m.FramesSent, (time.Duration(m.FramesSent) * 20 * time.Millisecond).Round(time.Second))
+	fmt.Fprintf(&b, "**Send wait:** avg %s, max %s, %d stall(s)\n", ms(m.AvgSendWait()), ms(m.MaxSendWait), m.SendStalls)
+	fmt.Fprintf(&b, "**Encoder errors:** %d\n", m.EncoderErrors)
+	fmt.Fprintf(&b, "**Transport errors:** %d (recovered %d soft, %d hard; mode %s)\n", m.TransportErrors, m.SoftRecoveries, m.HardRecoveries, mode)
+	fmt.Fprintf(&b, "**Streams:** %d, last ended %s", m.Streams, lastStream)
+	return b.String()
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
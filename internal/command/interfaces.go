@@ -29,6 +29,12 @@ type ComponentInteractionHandler interface {
 	Component(*ComponentInteractionContext) error
 }
 
+// AutocompleteHandler answers autocomplete requests for a slash command's options
+// (InteractionApplicationCommandAutocompleteResult with up to 25 choices).
+type AutocompleteHandler interface {
+	Autocomplete(*AutocompleteInteractionContext) error
+}
+
 type Meta interface {
 	Group() string
 	Category() string
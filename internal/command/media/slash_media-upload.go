@@ -173,6 +173,11 @@ func sanitizeCategory(cat string) string {
 }
 
 func saveUploadedFile(att *discordgo.MessageAttachment, guildID, category string) error {
+	return SaveAttachment(att, filepath.Join("assets", "media", guildID, category), att.Filename)
+}
+
+// SaveAttachment downloads an uploaded attachment into dir as name, creating dir when needed.
+func SaveAttachment(att *discordgo.MessageAttachment, dir, name string) error {
 	resp, err := http.Get(att.URL)
 	if err != nil {
 		return fmt.Errorf("failed to download attachment: %v", err)
@@ -183,12 +188,11 @@ func saveUploadedFile(att *discordgo.MessageAttachment, guildID, category string
 		return fmt.Errorf("bad response downloading file: %v", resp.Status)
 	}
 
-	dir := filepath.Join("assets", "media", guildID, category)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create dir: %v", err)
 	}
 
-	destPath := filepath.Join(dir, att.Filename)
+	destPath := filepath.Join(dir, name)
 	out, err := os.Create(destPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file: %v", err)
@@ -0,0 +1,33 @@
+package common
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+)
+
+// AttachmentSourceName is the source name recorded for tracks played from a Discord attachment.
+const AttachmentSourceName = "attachment"
+
+// ErrNotAudioAttachment is returned by AttachmentTrack for files that are neither audio nor video.
+var ErrNotAudioAttachment = errors.New("the attached file is not an audio file")
+
+// AttachmentTrack turns an uploaded audio (or video) file into a track streamed from its CDN link with ffmpeg.
+func AttachmentTrack(att *discordgo.MessageAttachment) (sources.TrackInfo, error) {
+	if att == nil || att.URL == "" {
+		return sources.TrackInfo{}, ErrNotAudioAttachment
+	}
+	ct := strings.ToLower(att.ContentType)
+	if !strings.HasPrefix(ct, "audio/") && !strings.HasPrefix(ct, "video/") && !local.IsAudioFile(att.Filename) {
+		return sources.TrackInfo{}, ErrNotAudioAttachment
+	}
+	return sources.TrackInfo{
+		URL:              att.URL,
+		Title:            local.Title(att.Filename),
+		SourceName:       AttachmentSourceName,
+		AvailableParsers: []string{"ffmpeg-link"},
+	}, nil
+}
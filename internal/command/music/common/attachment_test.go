@@ -0,0 +1,31 @@
+package common
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestAttachmentTrack(t *testing.T) {
+	t.Parallel()
+	ti, err := AttachmentTrack(&discordgo.MessageAttachment{
+		URL:         "https://cdn.discordapp.test/a/scene.ogg",
+		Filename:    "scene.ogg",
+		ContentType: "audio/ogg",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Title != "scene" || ti.SourceName != AttachmentSourceName || len(ti.AvailableParsers) != 1 || ti.AvailableParsers[0] != "ffmpeg-link" {
+		t.Fatalf("track: %+v", ti)
+	}
+
+	// Missing content type falls back to the file extension.
+	if _, err := AttachmentTrack(&discordgo.MessageAttachment{URL: "https://x.test/b.flac", Filename: "b.flac"}); err != nil {
+		t.Fatalf("flac by extension: %v", err)
+	}
+	if _, err := AttachmentTrack(&discordgo.MessageAttachment{URL: "https://x.test/c.png", Filename: "c.png", ContentType: "image/png"}); !errors.Is(err, ErrNotAudioAttachment) {
+		t.Fatalf("image: err = %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/voice"
+	"github.com/keshon/server-domme/internal/storage"
+	"github.com/rs/zerolog"
+)
+
+// IsDJ reports whether member may skip and stop without a vote. That is everyone while the guild has no
+// DJ role; otherwise members with the role, server managers and the requester of the current track.
+func IsDJ(store *storage.Storage, guildID string, member *discordgo.Member, p *player.Player) bool {
+	if store == nil || member == nil || member.User == nil {
+		return true
+	}
+	roleID, err := store.MusicDJRole(guildID)
+	if err != nil || roleID == "" {
+		return true
+	}
+	if member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0 {
+		return true
+	}
+	if slices.Contains(member.Roles, roleID) {
+		return true
+	}
+	if p != nil {
+		if track := p.CurrentTrack(); track != nil && track.RequestedBy == member.User.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// CastSkipVote adds member's vote to skip the current track and returns the reply text. counted reports
+// whether the vote was taken (otherwise msg explains why, for an ephemeral reply); passed reports that the
+// vote reached its threshold and the caller should skip now (see SkipOrStop). Only listeners in the bot's
+// voice channel may vote.
+func CastSkipVote(bot discord.VoiceAPI, guildID string, member *discordgo.Member, p *player.Player) (msg string, counted, passed bool) {
+	vs, err := bot.FindUserVoiceState(guildID, member.User.ID)
+	if err != nil || p.ChannelID() == "" || vs.ChannelID != p.ChannelID() {
+		return "Join the voice channel I'm playing in to vote.", false, false
+	}
+
+	votes, needed, passed, err := bot.VoteSkip(guildID, member.User.ID)
+	switch {
+	case errors.Is(err, voice.ErrAlreadyVoted):
+		return fmt.Sprintf("🗳 You already voted to skip this track (**%d/%d**).", votes, needed), false, false
+	case errors.Is(err, player.ErrNoTrackPlaying):
+		return "Nothing is playing right now.", false, false
+	case err != nil:
+		return fmt.Sprintf("Could not count the vote.\n\n**Error:** %v", err), false, false
+	case passed:
+		return fmt.Sprintf("🗳 Vote passed (**%d/%d**). ⏭ Skipped.", votes, needed), true, true
+	default:
+		return fmt.Sprintf("🗳 <@%s> voted to skip (**%d/%d**). Others can vote with ⏭ Skip on the now-playing panel or `/next`.",
+			member.User.ID, votes, needed), true, false
+	}
+}
+
+// ReplySkipVote answers a command with a vote that did not pass yet and refreshes the panel tally.
+// Votes that were not counted get an ephemeral reply.
+func ReplySkipVote(session *discordgo.Session, event *discordgo.InteractionCreate, bot discord.VoiceAPI, appLog zerolog.Logger, msg string, counted bool) {
+	if !counted {
+		discordreply.FollowupEmbedEphemeral(session, event, &discordgo.MessageEmbed{
+			Title:       "🗳 Vote skip",
+			Description: msg,
+		})
+		return
+	}
+	if _, err := bot.ShowPlaybackPanel(session, nil, event.GuildID, false); err != nil {
+		appLog.Warn().Str("guild_id", event.GuildID).Err(err).Msg("guild_status_update_failed")
+	}
+	if err := discordreply.FollowupEmbed(session, event, &discordgo.MessageEmbed{
+		Description: msg,
+		Color:       discordreply.EmbedColor,
+	}); err != nil {
+		appLog.Warn().Str("guild_id", event.GuildID).Err(err).Msg("followup_embed_failed")
+	}
+}
+
+// SkipOrStop skips to the next track, or stops playback when the queue is empty.
+func SkipOrStop(p *player.Player) error {
+	if len(p.Queue()) == 0 {
+		return p.Stop(false)
+	}
+	channelID := p.ChannelID()
+	p.Stop(false)
+	return p.PlayNext(channelID)
+}
@@ -60,8 +60,21 @@ func historyLine(id uint64, title, url, tail string) string {
 	return fmt.Sprintf("`%d` %s `%s`", id, title, tail)
 }
 
+// endReasonMarks flags plays that did not finish on their own; finished plays carry no mark.
+var endReasonMarks = map[string]string{
+	domain.MusicEndSkipped: "⏭",
+	domain.MusicEndError:   "⚠",
+}
+
+// FormatTimelineLine renders a history row; the tail is the date, then listened time and an end mark when recorded.
 func FormatTimelineLine(m domain.MusicPlayback) string {
 	tail := m.PlayedAt.Format("02 Jan 2006")
+	if m.ListenedMs > 0 {
+		tail += " " + FormatTrackTime(m.Listened())
+	}
+	if mark, ok := endReasonMarks[m.EndReason]; ok {
+		tail += " " + mark
+	}
 	title := displayTrackTitle(m.Title)
 	build := func(tt string) string {
 		return historyLine(m.ID, tt, m.URL, tail)
@@ -79,3 +92,14 @@ func FormatCountsLine(r domain.PlaybackCountRow) string {
 	title = fitTitleToLineLimit(title, build)
 	return build(title)
 }
+
+// FormatSkipsLine renders a "most skipped" row; the tail is skips out of plays.
+func FormatSkipsLine(r domain.PlaybackCountRow) string {
+	tail := fmt.Sprintf("⏭×%d/%d", r.Skips, r.Count)
+	title := displayTrackTitle(r.Title)
+	build := func(tt string) string {
+		return historyLine(r.RepresentativeID, tt, r.URL, tail)
+	}
+	title = fitTitleToLineLimit(title, build)
+	return build(title)
+}
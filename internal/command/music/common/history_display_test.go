@@ -55,3 +55,26 @@ func TestFormatCountsLineNoDate(t *testing.T) {
 		t.Fatalf("got %q", s)
 	}
 }
+
+func TestFormatTimelineLineListenedAndSkip(t *testing.T) {
+	t.Parallel()
+	m := domain.MusicPlayback{
+		ID:         8,
+		PlayedAt:   time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC),
+		Title:      "Hi",
+		ListenedMs: 192000,
+		EndReason:  domain.MusicEndSkipped,
+	}
+	s := FormatTimelineLine(m)
+	if !strings.HasSuffix(s, "`15 Mar 2026 3:12 ⏭`") {
+		t.Fatalf("got %q", s)
+	}
+}
+
+func TestFormatSkipsLine(t *testing.T) {
+	t.Parallel()
+	r := domain.PlaybackCountRow{RepresentativeID: 3, URL: "https://y.test/c", Title: "Song", Count: 5, Skips: 2}
+	if s := FormatSkipsLine(r); !strings.HasSuffix(s, "`⏭×2/5`") {
+		t.Fatalf("got %q", s)
+	}
+}
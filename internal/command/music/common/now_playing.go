@@ -0,0 +1,112 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+)
+
+// ErrInvalidSeekPosition is returned by ParseSeekPosition for input that is not ss, mm:ss or hh:mm:ss.
+var ErrInvalidSeekPosition = errors.New("invalid position, use mm:ss")
+
+// ParseSeekPosition parses "ss", "mm:ss" or "hh:mm:ss" into a duration.
+func ParseSeekPosition(s string) (time.Duration, error) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) == 0 || len(parts) > 3 {
+		return 0, ErrInvalidSeekPosition
+	}
+	var total int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, ErrInvalidSeekPosition
+		}
+		// Every field after the first is a base-60 digit.
+		if i > 0 && n >= 60 {
+			return 0, ErrInvalidSeekPosition
+		}
+		total = total*60 + n
+	}
+	return time.Duration(total) * time.Second, nil
+}
+
+// FormatTrackTime renders d as m:ss, or h:mm:ss for an hour or more.
+func FormatTrackTime(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	sec := int(d / time.Second)
+	h, m, s := sec/3600, (sec/60)%60, sec%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+// FormatPlaybackPosition renders "pos / total", or just pos when the duration is unknown (e.g. radio).
+func FormatPlaybackPosition(pos, total time.Duration) string {
+	if total <= 0 {
+		return FormatTrackTime(pos)
+	}
+	if pos > total {
+		pos = total
+	}
+	return FormatTrackTime(pos) + " / " + FormatTrackTime(total)
+}
+
+// LoopModeLabel is the human-readable description of a loop mode.
+func LoopModeLabel(mode player.LoopMode) string {
+	switch mode {
+	case player.LoopTrack:
+		return "🔂 Repeat track"
+	case player.LoopQueue:
+		return "🔁 Repeat queue"
+	case player.LoopAutoplay:
+		return "📻 Autoplay from history"
+	default:
+		return "➡️ Off"
+	}
+}
+
+func trackLink(track *parsers.TrackParse) string {
+	switch {
+	case track.Title != "" && track.URL != "":
+		return fmt.Sprintf("🎶 [%s](%s)", track.Title, track.URL)
+	case track.Title != "":
+		return "🎶 " + track.Title
+	case track.URL != "":
+		return "🎶 " + track.URL
+	default:
+		return "🎶 Unknown track"
+	}
+}
+
+// NowPlayingEmbed builds the guild status embed for the player's current track, including
+// the paused state and position. It returns nil when nothing is playing.
+func NowPlayingEmbed(p *player.Player) *discordgo.MessageEmbed {
+	track := p.CurrentTrack()
+	if track == nil {
+		return nil
+	}
+
+	title := statusEmoji(player.StatusPlaying) + " Now Playing"
+	if p.IsPaused() {
+		title = statusEmoji(player.StatusPaused) + " Paused"
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: trackLink(track),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: FormatPlaybackPosition(p.Position(), track.Duration),
+		},
+		Color: discordreply.EmbedColor,
+	}
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSeekPosition(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "45", want: 45 * time.Second},
+		{in: "1:30", want: 90 * time.Second},
+		{in: " 01:05 ", want: 65 * time.Second},
+		{in: "1:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{in: "90:00", want: 90 * time.Minute},
+		{in: "1:60", wantErr: true},
+		{in: "a:10", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "1:2:3:4", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseSeekPosition(tc.in)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidSeekPosition) {
+					t.Fatalf("err = %v, want ErrInvalidSeekPosition", err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Fatalf("got %v, %v; want %v", got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormatPlaybackPosition(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		pos, total time.Duration
+		want       string
+	}{
+		{83 * time.Second, 296 * time.Second, "1:23 / 4:56"},
+		{5 * time.Second, 0, "0:05"},
+		{3725 * time.Second, 2 * time.Hour, "1:02:05 / 2:00:00"},
+		{10 * time.Minute, 4 * time.Minute, "4:00 / 4:00"},
+	}
+	for _, tc := range cases {
+		if got := FormatPlaybackPosition(tc.pos, tc.total); got != tc.want {
+			t.Fatalf("FormatPlaybackPosition(%v, %v) = %q, want %q", tc.pos, tc.total, got, tc.want)
+		}
+	}
+}
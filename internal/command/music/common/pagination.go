@@ -0,0 +1,32 @@
+package common
+
+// LinesPerPage is the number of rows shown per embed page by paginated music views.
+const LinesPerPage = 15
+
+// Paginate clamps page to the available range and returns the lines for that page,
+// together with the effective page number and total page count (at least 1).
+func Paginate(lines []string, page int64, perPage int) ([]string, int64, int) {
+	if perPage < 1 {
+		perPage = LinesPerPage
+	}
+	totalPages := (len(lines) + perPage - 1) / perPage
+	if totalPages < 1 {
+		totalPages = 1
+	}
+	if page < 1 {
+		page = 1
+	}
+	if page > int64(totalPages) {
+		page = int64(totalPages)
+	}
+
+	start := int((page - 1) * int64(perPage))
+	if start >= len(lines) {
+		return nil, page, totalPages
+	}
+	end := start + perPage
+	if end > len(lines) {
+		end = len(lines)
+	}
+	return lines[start:end], page, totalPages
+}
@@ -0,0 +1,43 @@
+package common
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestPaginate(t *testing.T) {
+	t.Parallel()
+	lines := make([]string, 32)
+	for i := range lines {
+		lines[i] = fmt.Sprint(i)
+	}
+	tests := []struct {
+		name      string
+		page      int64
+		wantPage  int64
+		wantFirst string
+		wantLen   int
+	}{
+		{"first", 1, 1, "0", 15},
+		{"last partial", 3, 3, "30", 2},
+		{"below range", 0, 1, "0", 15},
+		{"above range", 9, 3, "30", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, page, total := Paginate(lines, tt.page, LinesPerPage)
+			if total != 3 {
+				t.Fatalf("total = %d, want 3", total)
+			}
+			if page != tt.wantPage || len(got) != tt.wantLen || got[0] != tt.wantFirst {
+				t.Fatalf("page=%d len=%d first=%q", page, len(got), got[0])
+			}
+		})
+	}
+
+	got, page, total := Paginate(nil, 2, LinesPerPage)
+	if len(got) != 0 || page != 1 || total != 1 {
+		t.Fatalf("empty: len=%d page=%d total=%d", len(got), page, total)
+	}
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/voice"
+)
+
+// PanelComponentPrefix is the custom ID prefix of the now-playing panel buttons. It equals the name of
+// the nowplaying command so the component router hands the clicks to that command.
+const PanelComponentPrefix = "nowplaying"
+
+// Panel button actions, sent as "nowplaying:<action>".
+const (
+	PanelActionPause   = "pause"
+	PanelActionSkip    = "skip"
+	PanelActionStop    = "stop"
+	PanelActionLoop    = "loop"
+	PanelActionShuffle = "shuffle"
+)
+
+// NextLoopMode cycles off → track → queue → autoplay → off, as the panel's Loop button does.
+func NextLoopMode(mode player.LoopMode) player.LoopMode {
+	switch mode {
+	case player.LoopOff:
+		return player.LoopTrack
+	case player.LoopTrack:
+		return player.LoopQueue
+	case player.LoopQueue:
+		return player.LoopAutoplay
+	default:
+		return player.LoopOff
+	}
+}
+
+func requesterLabel(track *parsers.TrackParse) string {
+	if track.RequestedBy == "" {
+		return "—"
+	}
+	return fmt.Sprintf("<@%s>", track.RequestedBy)
+}
+
+// RenderPlaybackPanel builds the guild now-playing panel: the current track with requester, elapsed/total
+// time, queue length and loop mode, plus the control buttons; the Skip button shows a running vote-skip tally.
+// Live radio streams also get an "On air" field with the song title from the stream metadata.
+// With nothing playing it renders an idle embed without buttons.
+func RenderPlaybackPanel(p *player.Player, st voice.PanelState) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := NowPlayingEmbed(p)
+	if embed == nil {
+		return &discordgo.MessageEmbed{
+			Title:       statusEmoji(player.StatusStopped) + " Nothing playing",
+			Description: "Add tracks with `/play`.",
+			Color:       discordreply.EmbedColor,
+		}, []discordgo.MessageComponent{}
+	}
+
+	track := p.CurrentTrack()
+	embed.Footer = nil
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Requested by", Value: requesterLabel(track), Inline: true},
+		{Name: "Time", Value: FormatPlaybackPosition(p.Position(), track.Duration), Inline: true},
+		{Name: "Queue", Value: fmt.Sprintf("%d track(s)", len(p.Queue())), Inline: true},
+		{Name: "Loop", Value: LoopModeLabel(p.LoopMode()), Inline: true},
+	}
+	if title := p.StreamTitle(); title != "" {
+		embed.Fields = append([]*discordgo.MessageEmbedField{{Name: "On air", Value: title}}, embed.Fields...)
+	}
+
+	pauseLabel := "⏸ Pause"
+	if p.IsPaused() {
+		pauseLabel = "▶️ Resume"
+	}
+	skipLabel := "⏭ Skip"
+	if st.SkipVotes > 0 {
+		skipLabel = fmt.Sprintf("⏭ Skip (%d/%d)", st.SkipVotes, st.SkipVotesNeeded)
+	}
+	button := func(label, action string) discordgo.Button {
+		return discordgo.Button{Label: label, Style: discordgo.SecondaryButton, CustomID: PanelComponentPrefix + ":" + action}
+	}
+	return embed, []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			button(pauseLabel, PanelActionPause),
+			button(skipLabel, PanelActionSkip),
+			button("⏹ Stop", PanelActionStop),
+			button("🔁 Loop", PanelActionLoop),
+			button("🔀 Shuffle", PanelActionShuffle),
+		}},
+	}
+}
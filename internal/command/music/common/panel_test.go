@@ -0,0 +1,19 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/player"
+)
+
+func TestNextLoopModeCycles(t *testing.T) {
+	t.Parallel()
+	want := []player.LoopMode{player.LoopTrack, player.LoopQueue, player.LoopAutoplay, player.LoopOff}
+	mode := player.LoopOff
+	for _, w := range want {
+		mode = NextLoopMode(mode)
+		if mode != w {
+			t.Fatalf("NextLoopMode = %q, want %q", mode, w)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package common
+
+import (
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+// FormatQueueLine renders one queued track as `pos` [title](url) `source`, reusing the history line layout.
+func FormatQueueLine(pos int, t parsers.TrackParse) string {
+	tail := strings.TrimSpace(t.SourceInfo.SourceName)
+	if tail == "" {
+		tail = t.CurrentParser
+	}
+	title := displayTrackTitle(t.Title)
+	build := func(tt string) string {
+		return historyLine(uint64(pos), tt, t.URL, tail)
+	}
+	title = fitTitleToLineLimit(title, build)
+	return build(title)
+}
+
+// FormatPlaylistLine renders one saved playlist track in the same layout as queue lines.
+func FormatPlaylistLine(pos int, t domain.MusicPlayback) string {
+	tail := strings.TrimSpace(t.SourceName)
+	if tail == "" {
+		tail = t.CurrentParser
+	}
+	title := displayTrackTitle(t.Title)
+	build := func(tt string) string {
+		return historyLine(uint64(pos), tt, t.URL, tail)
+	}
+	title = fitTitleToLineLimit(title, build)
+	return build(title)
+}
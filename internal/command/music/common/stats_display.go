@@ -0,0 +1,91 @@
+package common
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+// statsBarWidth is the length of a full activity bar.
+const statsBarWidth = 10
+
+// FormatListenHours renders d as hours with one decimal, e.g. "3.5 h".
+func FormatListenHours(d time.Duration) string {
+	return fmt.Sprintf("%.1f h", d.Hours())
+}
+
+// FormatRequesterLine renders a top requester row: rank, mention, plays and listened hours.
+func FormatRequesterLine(rank int, r domain.PlaybackRequesterRow) string {
+	return fmt.Sprintf("`%d.` <@%s> — %d plays · %s", rank, r.UserID, r.Plays, FormatListenHours(r.Listened))
+}
+
+// FormatBucketLine renders one activity bucket with a bar scaled against maxPlays.
+func FormatBucketLine(b domain.PlaybackBucket, size domain.PlaybackBucketSize, maxPlays int) string {
+	label := b.Start.Format("Mon 02 Jan")
+	if size == domain.BucketWeek {
+		label = b.Start.Format("wk 02 Jan")
+	}
+	filled := 0
+	if maxPlays > 0 {
+		filled = (b.Plays*statsBarWidth + maxPlays - 1) / maxPlays
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", statsBarWidth-filled)
+	return fmt.Sprintf("`%s` %s %d · %s", label, bar, b.Plays, FormatListenHours(b.Listened))
+}
+
+// StatsTracksCSV renders top tracks as CSV: rank, plays, skips, title, url, last played (RFC 3339, UTC).
+func StatsTracksCSV(rows []domain.PlaybackCountRow) ([]byte, error) {
+	records := [][]string{{"rank", "plays", "skips", "title", "url", "last_played"}}
+	for i, r := range rows {
+		records = append(records, []string{
+			strconv.Itoa(i + 1),
+			strconv.Itoa(r.Count),
+			strconv.Itoa(r.Skips),
+			r.Title,
+			r.URL,
+			r.LastPlayed.UTC().Format(time.RFC3339),
+		})
+	}
+	return writeCSV(records)
+}
+
+// StatsRequestersCSV renders top requesters as CSV: rank, user id, plays, listened seconds.
+func StatsRequestersCSV(rows []domain.PlaybackRequesterRow) ([]byte, error) {
+	records := [][]string{{"rank", "user_id", "plays", "listened_seconds"}}
+	for i, r := range rows {
+		records = append(records, []string{
+			strconv.Itoa(i + 1),
+			r.UserID,
+			strconv.Itoa(r.Plays),
+			strconv.FormatInt(int64(r.Listened/time.Second), 10),
+		})
+	}
+	return writeCSV(records)
+}
+
+// StatsActivityCSV renders activity buckets as CSV: bucket start date, plays, listened seconds.
+func StatsActivityCSV(buckets []domain.PlaybackBucket) ([]byte, error) {
+	records := [][]string{{"start", "plays", "listened_seconds"}}
+	for _, b := range buckets {
+		records = append(records, []string{
+			b.Start.Format("2006-01-02"),
+			strconv.Itoa(b.Plays),
+			strconv.FormatInt(int64(b.Listened/time.Second), 10),
+		})
+	}
+	return writeCSV(records)
+}
+
+func writeCSV(records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+func TestFormatBucketLineBar(t *testing.T) {
+	t.Parallel()
+	b := domain.PlaybackBucket{Start: time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), Plays: 5, Listened: 90 * time.Minute}
+	s := FormatBucketLine(b, domain.BucketWeek, 10)
+	if !strings.HasPrefix(s, "`wk 09 Mar` █████░░░░░ 5 · 1.5 h") {
+		t.Fatalf("got %q", s)
+	}
+}
+
+func TestStatsTracksCSVQuotesTitles(t *testing.T) {
+	t.Parallel()
+	rows := []domain.PlaybackCountRow{{
+		URL:        "https://x.test/a",
+		Title:      `Song, "live"`,
+		Count:      3,
+		Skips:      1,
+		LastPlayed: time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC),
+	}}
+	data, err := StatsTracksCSV(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "rank,plays,skips,title,url,last_played\n1,3,1,\"Song, \"\"live\"\"\",https://x.test/a,2026-03-15T12:00:00Z\n"
+	if string(data) != want {
+		t.Fatalf("got %q", data)
+	}
+}
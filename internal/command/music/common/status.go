@@ -0,0 +1,61 @@
+package common
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/rs/zerolog"
+)
+
+func statusEmoji(status player.Status) string {
+	switch status {
+	case player.StatusPlaying:
+		return "▶️"
+	case player.StatusAdded:
+		return "🎶"
+	case player.StatusStopped:
+		return "⏹"
+	case player.StatusPaused:
+		return "⏸"
+	case player.StatusResumed:
+		return "▶️"
+	case player.StatusError:
+		return "❌"
+	default:
+		return ""
+	}
+}
+
+// AnnouncePlayback brings the guild's now-playing panel up to date after a command changed playback.
+// If the panel was posted as this command's reply, nothing else is sent; otherwise the existing panel is
+// edited in place (the voice service keeps it current from then on) and msg answers the command.
+func AnnouncePlayback(session *discordgo.Session, event *discordgo.InteractionCreate, bot discord.VoiceAPI, appLog zerolog.Logger, msg string) {
+	posted, err := bot.ShowPlaybackPanel(session, event, event.GuildID, false)
+	if err != nil {
+		appLog.Warn().Str("guild_id", event.GuildID).Err(err).Msg("guild_status_update_failed")
+	}
+	if posted {
+		return
+	}
+	if err := discordreply.FollowupEmbed(session, event, &discordgo.MessageEmbed{
+		Description: msg,
+		Color:       discordreply.EmbedColor,
+	}); err != nil {
+		appLog.Warn().Str("guild_id", event.GuildID).Err(err).Msg("followup_embed_failed")
+	}
+}
+
+// ReplyPlaybackChange answers a pause/resume/seek with a short confirmation plus the current
+// now-playing embed. The guild panel itself is refreshed by the voice service from player events.
+func ReplyPlaybackChange(session *discordgo.Session, event *discordgo.InteractionCreate, p *player.Player, appLog zerolog.Logger, msg string) {
+	embeds := []*discordgo.MessageEmbed{{Description: msg, Color: discordreply.EmbedColor}}
+	if embed := NowPlayingEmbed(p); embed != nil {
+		embeds = append(embeds, embed)
+	}
+	if _, err := session.FollowupMessageCreate(event.Interaction, true, &discordgo.WebhookParams{
+		Embeds: embeds,
+	}); err != nil {
+		appLog.Warn().Str("guild_id", event.GuildID).Err(err).Msg("followup_embed_failed")
+	}
+}
@@ -0,0 +1,133 @@
+package crossfade
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+type Crossfade struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Crossfade) Name() string { return "crossfade" }
+func (c *Crossfade) Description() string {
+	return "Set crossfade and gapless transitions between tracks"
+}
+func (c *Crossfade) Group() string            { return "music" }
+func (c *Crossfade) Category() string         { return "🎵 Music" }
+func (c *Crossfade) UserPermissions() []int64 { return []int64{} }
+
+// discordgo requires a pointer for MinValue on slash options.
+var crossfadeMinValue = 0.0
+
+func (c *Crossfade) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "seconds",
+				Description: fmt.Sprintf("Overlap of consecutive tracks (0-%d, 0 turns crossfade off)", domain.MaxMusicCrossfadeSeconds),
+				Required:    false,
+				MinValue:    &crossfadeMinValue,
+				MaxValue:    domain.MaxMusicCrossfadeSeconds,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "gapless",
+				Description: "Start the next track without a pause",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func (c *Crossfade) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+	guildID := e.GuildID
+
+	var seconds *int64
+	var gapless *bool
+	for _, opt := range e.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "seconds":
+			v := opt.IntValue()
+			seconds = &v
+		case "gapless":
+			v := opt.BoolValue()
+			gapless = &v
+		}
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	var changes []string
+	if seconds != nil {
+		applied, err := c.Bot.SetCrossfade(guildID, int(*seconds))
+		if err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Failed to set crossfade.\n\n**Error:** %v", err),
+			})
+			return nil
+		}
+		changes = append(changes, fmt.Sprintf("Crossfade **%s**.", crossfadeLabel(applied)))
+	}
+	if gapless != nil {
+		if err := c.Bot.SetGapless(guildID, *gapless); err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Failed to set gapless playback.\n\n**Error:** %v", err),
+			})
+			return nil
+		}
+		changes = append(changes, fmt.Sprintf("Gapless playback **%s**.", onOff(*gapless)))
+	}
+
+	desc := strings.Join(changes, "\n")
+	if desc == "" {
+		desc = fmt.Sprintf("Crossfade: **%s**\nGapless playback: **%s**",
+			crossfadeLabel(c.Bot.Crossfade(guildID)), onOff(c.Bot.Gapless(guildID)))
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, &discordgo.MessageEmbed{
+		Title:       "🎚 Transitions",
+		Description: desc,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Crossfade needs tracks with a known length; others join back to back."},
+		Color:       discordreply.EmbedColor,
+	}); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "crossfade").Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+func crossfadeLabel(sec int) string {
+	if sec == 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d s", sec)
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
@@ -0,0 +1,101 @@
+package filter
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/voice/dsp"
+)
+
+type Filter struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Filter) Name() string { return "filter" }
+func (c *Filter) Description() string {
+	return "Apply an audio filter (bass boost, nightcore, 8D, karaoke)"
+}
+func (c *Filter) Group() string            { return "music" }
+func (c *Filter) Category() string         { return "🎵 Music" }
+func (c *Filter) UserPermissions() []int64 { return []int64{} }
+
+func (c *Filter) SlashDefinition() *discordgo.ApplicationCommand {
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, len(dsp.Presets))
+	for _, p := range dsp.Presets {
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: p.Label(), Value: string(p)})
+	}
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "preset",
+				Description: "Filter to apply; leave empty to show the current one",
+				Required:    false,
+				Choices:     choices,
+			},
+		},
+	}
+}
+
+func (c *Filter) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+	guildID := e.GuildID
+
+	var input string
+	for _, opt := range e.ApplicationCommandData().Options {
+		if opt.Name == "preset" {
+			input = opt.StringValue()
+		}
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	var desc string
+	if input == "" {
+		desc = fmt.Sprintf("Current filter: **%s**", c.Bot.Filter(guildID).Label())
+	} else {
+		preset, err := dsp.ParsePreset(input)
+		if err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Unknown filter **%s**.", input),
+			})
+			return nil
+		}
+		if err := c.Bot.SetFilter(guildID, preset); err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Failed to set the filter.\n\n**Error:** %v", err),
+			})
+			return nil
+		}
+		desc = fmt.Sprintf("Filter set to **%s**.", preset.Label())
+		if preset == dsp.PresetOff {
+			desc = "Filter turned **off**."
+		}
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, &discordgo.MessageEmbed{
+		Title:       "🎛 Filter",
+		Description: desc,
+		Color:       discordreply.EmbedColor,
+	}); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "filter").Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
@@ -35,13 +35,20 @@ func (c *History) SlashDefinition() *discordgo.ApplicationCommand {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "view",
-				Description: "Chronological list or plays per link",
+				Description: "Chronological list, plays per link or most skipped links",
 				Required:    false,
 				Choices: []*discordgo.ApplicationCommandOptionChoice{
 					{Name: "Timeline", Value: "timeline"},
 					{Name: "By URL", Value: "counts"},
+					{Name: "Most skipped", Value: "skipped"},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Only tracks requested by this member",
+				Required:    false,
+			},
 			{
 				Type:        discordgo.ApplicationCommandOptionInteger,
 				Name:        "page",
@@ -53,8 +60,6 @@ func (c *History) SlashDefinition() *discordgo.ApplicationCommand {
 	}
 }
 
-const historyLinesPerPage = 15
-
 const historyFooterReplay = "replay with `/play <id>`."
 
 func (c *History) Run(ctx interface{}) error {
@@ -69,6 +74,7 @@ func (c *History) Run(ctx interface{}) error {
 
 	var view = "timeline"
 	var page int64 = 1
+	var userID string
 	for _, opt := range e.ApplicationCommandData().Options {
 		switch opt.Name {
 		case "view":
@@ -77,6 +83,8 @@ func (c *History) Run(ctx interface{}) error {
 			}
 		case "page":
 			page = opt.IntValue()
+		case "user":
+			userID = opt.UserValue(nil).ID
 		}
 	}
 
@@ -121,6 +129,20 @@ func (c *History) Run(ctx interface{}) error {
 		return nil
 	}
 
+	var filterNote string
+	if userID != "" {
+		rows = domain.FilterPlaybackByRequester(rows, userID)
+		filterNote = fmt.Sprintf("Requested by <@%s>.\n", userID)
+		if len(rows) == 0 {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 History",
+				Description: fmt.Sprintf("No recorded plays requested by <@%s>.", userID),
+				Color:       discordreply.EmbedColor,
+			})
+			return nil
+		}
+	}
+
 	view = strings.ToLower(strings.TrimSpace(view))
 	if view == "" {
 		view = "timeline"
@@ -132,6 +154,17 @@ func (c *History) Run(ctx interface{}) error {
 	var footerExtra string
 
 	switch view {
+	case "skipped":
+		skipped := domain.MostSkipped(domain.AggregatePlaybackCounts(rows))
+		totalRows = len(skipped)
+		embedTitle = "🎵 Playback history (most skipped)"
+		footerExtra = "Skips/plays; " + historyFooterReplay
+		for _, r := range skipped {
+			lines = append(lines, common.FormatSkipsLine(r))
+		}
+		if len(lines) == 0 {
+			lines = append(lines, "No skipped tracks recorded yet.")
+		}
 	case "counts":
 		counts := domain.AggregatePlaybackCounts(rows)
 		totalRows = len(counts)
@@ -149,33 +182,14 @@ func (c *History) Run(ctx interface{}) error {
 		}
 	}
 
-	totalPages := (totalRows + historyLinesPerPage - 1) / historyLinesPerPage
-	if totalPages < 1 {
-		totalPages = 1
-	}
-	if page < 1 {
-		page = 1
-	}
-	if int64(totalPages) > 0 && page > int64(totalPages) {
-		page = int64(totalPages)
-	}
-
-	start := int((page - 1) * int64(historyLinesPerPage))
-	if start >= len(lines) {
-		start = 0
-		page = 1
-	}
-	end := start + historyLinesPerPage
-	if end > len(lines) {
-		end = len(lines)
-	}
+	pageLines, page, totalPages := common.Paginate(lines, page, common.LinesPerPage)
 
 	var b strings.Builder
-	for _, line := range lines[start:end] {
+	for _, line := range pageLines {
 		b.WriteString(line)
 		b.WriteByte('\n')
 	}
-	desc := strings.TrimSpace(b.String())
+	desc := filterNote + strings.TrimSpace(b.String())
 	if len(desc) > 4000 {
 		desc = desc[:3997] + "..."
 	}
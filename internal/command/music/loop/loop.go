@@ -0,0 +1,102 @@
+package loop
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+)
+
+type Loop struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Loop) Name() string             { return "loop" }
+func (c *Loop) Description() string      { return "Set what plays after a track ends" }
+func (c *Loop) Group() string            { return "music" }
+func (c *Loop) Category() string         { return "🎵 Music" }
+func (c *Loop) UserPermissions() []int64 { return []int64{} }
+
+func (c *Loop) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "mode",
+				Description: "Loop mode (omit to show the current one)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Off", Value: string(player.LoopOff)},
+					{Name: "Repeat track", Value: string(player.LoopTrack)},
+					{Name: "Repeat queue", Value: string(player.LoopQueue)},
+					{Name: "Autoplay from history", Value: string(player.LoopAutoplay)},
+				},
+			},
+		},
+	}
+}
+
+func (c *Loop) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+	guildID := e.GuildID
+
+	var raw string
+	for _, opt := range e.ApplicationCommandData().Options {
+		if opt.Name == "mode" {
+			raw = opt.StringValue()
+		}
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	if raw == "" {
+		discordreply.FollowupEmbed(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Loop",
+			Description: fmt.Sprintf("Current mode: **%s**", common.LoopModeLabel(c.Bot.LoopMode(guildID))),
+			Color:       discordreply.EmbedColor,
+		})
+		return nil
+	}
+
+	mode, err := player.ParseLoopMode(raw)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Loop",
+			Description: fmt.Sprintf("Unknown mode `%s`.", raw),
+		})
+		return nil
+	}
+
+	if err := c.Bot.SetLoopMode(guildID, mode); err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: fmt.Sprintf("Failed to save loop mode.\n\n**Error:** %v", err),
+		})
+		return nil
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, &discordgo.MessageEmbed{
+		Title:       "🎵 Loop",
+		Description: fmt.Sprintf("Loop mode set to **%s**.", common.LoopModeLabel(mode)),
+		Color:       discordreply.EmbedColor,
+	}); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "loop").Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
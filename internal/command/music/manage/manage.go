@@ -0,0 +1,142 @@
+package manage
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/domain"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+type ManageMusicCommand struct{}
+
+func (c *ManageMusicCommand) Name() string        { return "manage-music" }
+func (c *ManageMusicCommand) Description() string { return "Music settings" }
+func (c *ManageMusicCommand) Group() string       { return "music" }
+func (c *ManageMusicCommand) Category() string    { return "⚙️ Settings" }
+func (c *ManageMusicCommand) UserPermissions() []int64 {
+	return []int64{discordgo.PermissionAdministrator}
+}
+
+// discordgo requires pointers for MinValue/MaxValue on slash options.
+var votePercentMinValue = float64(domain.MinMusicSkipVotePercent)
+
+func (c *ManageMusicCommand) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set-dj-role",
+				Description: "Set the role that can skip and stop without a vote",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionRole,
+						Name:        "role",
+						Description: "Select a role from the server",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "reset-dj-role",
+				Description: "Remove the DJ role so everyone can skip and stop again",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "set-vote-threshold",
+				Description: "Set the share of listeners needed to pass a vote-skip",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "percent",
+						Description: fmt.Sprintf("Percent of listeners in the voice channel (default %d)", domain.DefaultMusicSkipVotePercent),
+						Required:    true,
+						MinValue:    &votePercentMinValue,
+						MaxValue:    domain.MaxMusicSkipVotePercent,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show the DJ role and vote-skip threshold",
+			},
+		},
+	}
+}
+
+func (c *ManageMusicCommand) Run(ctx interface{}) error {
+	context, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := context.Session
+	e := context.Event
+	storage := context.Storage
+
+	data := e.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "No subcommand provided.",
+		})
+	}
+
+	sub := data.Options[0]
+	return c.runManage(s, e, storage, sub)
+}
+
+func (c *ManageMusicCommand) runManage(s *discordgo.Session, e *discordgo.InteractionCreate, storage *storage.Storage, sub *discordgo.ApplicationCommandInteractionDataOption) error {
+	switch sub.Name {
+	case "set-dj-role":
+		roleID := sub.Options[0].RoleValue(s, e.GuildID).ID
+		if err := storage.SetMusicDJRole(e.GuildID, roleID); err != nil {
+			return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Description: fmt.Sprintf("Failed to set DJ role: %v", err),
+			})
+		}
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("DJ role set to <@&%s>. Other members now start a vote when they skip or stop.", roleID),
+		})
+
+	case "reset-dj-role":
+		if err := storage.SetMusicDJRole(e.GuildID, ""); err != nil {
+			return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Description: fmt.Sprintf("Failed to reset DJ role: %v", err),
+			})
+		}
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "DJ role removed. Everyone can skip and stop again.",
+		})
+
+	case "set-vote-threshold":
+		pct := domain.ClampMusicSkipVotePercent(int(sub.Options[0].IntValue()))
+		if err := storage.SetMusicSkipVotePercent(e.GuildID, pct); err != nil {
+			return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Description: fmt.Sprintf("Failed to set vote threshold: %v", err),
+			})
+		}
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("A vote-skip now passes with **%d%%** of the listeners in the voice channel.", pct),
+		})
+
+	case "show":
+		role := "not set (everyone can skip and stop)"
+		if roleID, _ := storage.MusicDJRole(e.GuildID); roleID != "" {
+			role = fmt.Sprintf("<@&%s>", roleID)
+		}
+		pct, _ := storage.MusicSkipVotePercent(e.GuildID)
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("**DJ role:** %s\n**Vote-skip threshold:** %d%% of listeners\n\nMembers with the DJ role, server managers and whoever queued the current track skip and stop directly; everyone else starts a vote.", role, pct),
+		})
+	}
+
+	return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+		Description: "Unknown subcommand.",
+	})
+}
@@ -74,6 +74,16 @@ func (c *Next) Run(ctx interface{}) error {
 		return nil
 	}
 
+	announce := "⏭ Skipped."
+	if !common.IsDJ(slashCtx.Storage, guildID, member, player) {
+		msg, counted, passed := common.CastSkipVote(c.Bot, guildID, member, player)
+		if !passed {
+			common.ReplySkipVote(s, e, c.Bot, slashCtx.AppLog, msg, counted)
+			return nil
+		}
+		announce = msg
+	}
+
 	player.Stop(false)
 	if err = player.PlayNext(voiceState.ChannelID); err != nil {
 		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
@@ -83,6 +93,6 @@ func (c *Next) Run(ctx interface{}) error {
 		return nil
 	}
 
-	common.ListenPlayerStatusSlash(s, e, player, c.Bot, guildID, slashCtx.AppLog)
+	common.AnnouncePlayback(s, e, c.Bot, slashCtx.AppLog, announce)
 	return nil
 }
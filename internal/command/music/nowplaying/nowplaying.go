@@ -0,0 +1,169 @@
+package nowplaying
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+)
+
+type NowPlaying struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *NowPlaying) Name() string             { return common.PanelComponentPrefix }
+func (c *NowPlaying) Description() string      { return "Show the now-playing panel with playback controls" }
+func (c *NowPlaying) Group() string            { return "music" }
+func (c *NowPlaying) Category() string         { return "🎵 Music" }
+func (c *NowPlaying) UserPermissions() []int64 { return []int64{} }
+
+func (c *NowPlaying) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+	}
+}
+
+func (c *NowPlaying) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil || p.CurrentTrack() == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Now Playing",
+			Description: "Nothing is playing right now.",
+		})
+		return nil
+	}
+
+	// Repost so the panel moves to the bottom of the channel; the old message stops being updated.
+	if _, err := c.Bot.ShowPlaybackPanel(s, e, e.GuildID, true); err != nil {
+		slashCtx.AppLog.Warn().Str("guild_id", e.GuildID).Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+// Component handles the panel buttons ("nowplaying:<action>"). Only listeners in the bot's voice
+// channel may use them; Skip and Stop from members without the DJ role cast a skip vote instead.
+func (c *NowPlaying) Component(ctx *command.ComponentInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+	action := strings.TrimPrefix(e.MessageComponentData().CustomID, common.PanelComponentPrefix+":")
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil {
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music service is not available.",
+		})
+	}
+
+	if e.Member == nil || e.Member.User == nil {
+		return nil
+	}
+	vs, err := c.Bot.FindUserVoiceState(e.GuildID, e.Member.User.ID)
+	if err != nil || p.ChannelID() == "" || vs.ChannelID != p.ChannelID() {
+		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Now Playing",
+			Description: "Join the voice channel I'm playing in to use these controls.",
+		})
+	}
+
+	run := func() error { return c.apply(e.GuildID, p, action) }
+	if (action == common.PanelActionSkip || action == common.PanelActionStop) && !common.IsDJ(ctx.Storage, e.GuildID, e.Member, p) {
+		// Without the DJ role, Skip and Stop vote to skip; the tally shows on the Skip button.
+		msg, counted, passed := common.CastSkipVote(c.Bot, e.GuildID, e.Member, p)
+		if !counted {
+			return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🗳 Vote skip",
+				Description: msg,
+			})
+		}
+		run = func() error { return nil }
+		if passed {
+			run = func() error { return common.SkipOrStop(p) }
+		}
+	}
+
+	// Skipping opens the next stream and stopping waits for playback to end, which can outlast
+	// Discord's 3s deadline: acknowledge first, then redraw the panel.
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		return fmt.Errorf("nowplaying: failed to acknowledge button: %w", err)
+	}
+	if err := run(); err != nil {
+		return discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Now Playing",
+			Description: panelErrorText(err),
+		})
+	}
+
+	embed, components := common.RenderPlaybackPanel(p, c.Bot.PanelState(e.GuildID))
+	if _, err := s.InteractionResponseEdit(e.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	}); err != nil {
+		return fmt.Errorf("nowplaying: failed to update panel: %w", err)
+	}
+	return nil
+}
+
+var errUnknownAction = errors.New("unknown panel action")
+
+func (c *NowPlaying) apply(guildID string, p *player.Player, action string) error {
+	switch action {
+	case common.PanelActionPause:
+		if p.IsPaused() {
+			return p.Resume()
+		}
+		return p.Pause()
+	case common.PanelActionSkip:
+		if len(p.Queue()) == 0 {
+			return player.ErrNoTracksInQueue
+		}
+		channelID := p.ChannelID()
+		p.Stop(false)
+		return p.PlayNext(channelID)
+	case common.PanelActionStop:
+		return p.Stop(true)
+	case common.PanelActionLoop:
+		return c.Bot.SetLoopMode(guildID, common.NextLoopMode(p.LoopMode()))
+	case common.PanelActionShuffle:
+		if len(p.Queue()) == 0 {
+			return player.ErrNoTracksInQueue
+		}
+		c.Bot.QueueShuffle(guildID)
+		return nil
+	default:
+		return errUnknownAction
+	}
+}
+
+func panelErrorText(err error) string {
+	switch {
+	case errors.Is(err, player.ErrNoTrackPlaying):
+		return "Nothing is playing right now."
+	case errors.Is(err, player.ErrNoTracksInQueue):
+		return "The queue is empty."
+	default:
+		return fmt.Sprintf("**Error:** %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package pause
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+)
+
+type Pause struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Pause) Name() string             { return "pause" }
+func (c *Pause) Description() string      { return "Pause the current track" }
+func (c *Pause) Group() string            { return "music" }
+func (c *Pause) Category() string         { return "🎵 Music" }
+func (c *Pause) UserPermissions() []int64 { return []int64{} }
+
+func (c *Pause) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+	}
+}
+
+func (c *Pause) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music service is not available.",
+		})
+		return nil
+	}
+
+	if err := p.Pause(); err != nil {
+		desc := fmt.Sprintf("Failed to pause.\n\n**Error:** %v", err)
+		if errors.Is(err, player.ErrNoTrackPlaying) {
+			desc = "Nothing is playing right now."
+		}
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Pause",
+			Description: desc,
+		})
+		return nil
+	}
+
+	common.ReplyPlaybackChange(s, e, p, slashCtx.AppLog, "⏸ Playback paused. Use `/resume` to continue.")
+	return nil
+}
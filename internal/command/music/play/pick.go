@@ -0,0 +1,229 @@
+package play
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/perm"
+	"github.com/rs/zerolog"
+)
+
+// pickResults is how many search results /play pick offers.
+const pickResults = 5
+
+// Discord caps select option labels and descriptions at 100 characters.
+const selectTextMax = 100
+
+// pickTTL is how long the search results behind a picker are kept; a later pick resolves the URL again.
+const pickTTL = 15 * time.Minute
+
+var (
+	pendingPicks   = make(map[string][]sources.TrackInfo)
+	pendingPicksMu sync.Mutex
+)
+
+// postPicker posts the search results of query as a select menu; the requester's choice is queued by
+// Component from the results kept here, so nothing is looked up again.
+func postPicker(s *discordgo.Session, e *discordgo.InteractionCreate, appLog zerolog.Logger, query, parser string, tracks []sources.TrackInfo) {
+	id := e.Interaction.ID
+	pendingPicksMu.Lock()
+	pendingPicks[id] = tracks
+	pendingPicksMu.Unlock()
+	time.AfterFunc(pickTTL, func() {
+		pendingPicksMu.Lock()
+		delete(pendingPicks, id)
+		pendingPicksMu.Unlock()
+	})
+
+	if _, err := s.FollowupMessageCreate(e.Interaction, true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{{
+			Description: fmt.Sprintf("🔎 Results for **%s**. Pick one to add it to the queue.", query),
+			Color:       discordreply.EmbedColor,
+		}},
+		Components: pickerComponents(e.Member.User.ID, parser, id, tracks),
+	}); err != nil {
+		appLog.Warn().Str("command", "play").Err(err).Msg("followup_embed_failed")
+	}
+}
+
+// pickedTrack returns the search result with url from the picker id, if it is still kept.
+func pickedTrack(id, url string) (sources.TrackInfo, bool) {
+	pendingPicksMu.Lock()
+	defer pendingPicksMu.Unlock()
+	for _, t := range pendingPicks[id] {
+		if t.URL == url {
+			return t, true
+		}
+	}
+	return sources.TrackInfo{}, false
+}
+
+// pickerComponents builds the select menu of tracks. Its custom ID carries who may pick, the parser
+// they asked for and the picker id its results are kept under: "play:pick:<userID>:<parser>:<id>".
+func pickerComponents(userID, parser, id string, tracks []sources.TrackInfo) []discordgo.MessageComponent {
+	options := make([]discordgo.SelectMenuOption, 0, len(tracks))
+	seen := make(map[string]bool, len(tracks))
+	for i, t := range tracks {
+		if t.URL == "" || seen[t.URL] || len(t.URL) > selectTextMax {
+			continue
+		}
+		seen[t.URL] = true
+		label := t.Title
+		if label == "" {
+			label = fmt.Sprintf("Result %d", i+1)
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       truncate(label, selectTextMax),
+			Value:       t.URL,
+			Description: t.URL,
+		})
+	}
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				CustomID:    "play:pick:" + userID + ":" + parser + ":" + id,
+				Placeholder: "Choose a track",
+				Options:     options,
+			},
+		}},
+	}
+}
+
+// truncate shortens s to at most n characters, ending it with an ellipsis when cut.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// pickedLabel returns the label of the option with value in the picker message, or "".
+func pickedLabel(msg *discordgo.Message, value string) string {
+	if msg == nil {
+		return ""
+	}
+	for _, row := range msg.Components {
+		ar, ok := row.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, c := range ar.Components {
+			menu, ok := c.(*discordgo.SelectMenu)
+			if !ok {
+				continue
+			}
+			for _, o := range menu.Options {
+				if o.Value == value {
+					return o.Label
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// Component queues the track picked from a /play pick menu.
+func (c *Play) Component(ctx *command.ComponentInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+	data := e.MessageComponentData()
+	parts := strings.Split(data.CustomID, ":")
+	if len(parts) != 5 || parts[0] != "play" || parts[1] != "pick" || len(data.Values) != 1 {
+		discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "Something smells off about this menu.",
+		})
+		return nil
+	}
+	if e.Member == nil || e.Member.User == nil {
+		return nil
+	}
+	if e.Member.User.ID != parts[2] {
+		discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "Only whoever searched can pick. Run `/play` with `pick` yourself.",
+		})
+		return nil
+	}
+	guildID, parser, url := e.GuildID, parts[3], data.Values[0]
+
+	voiceState, err := c.Bot.FindUserVoiceState(guildID, e.Member.User.ID)
+	if err != nil {
+		discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: fmt.Sprintf("%v", err),
+		})
+		return nil
+	}
+	permOK, err := perm.CheckBotVoicePermissions(s, voiceState.ChannelID)
+	if err != nil || !permOK {
+		discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: "I don't have permission to join or speak in that voice channel.",
+		})
+		return nil
+	}
+	p := c.Bot.GetOrCreatePlayer(guildID)
+	if p == nil {
+		discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music service is not available.",
+		})
+		return nil
+	}
+
+	// Resolving an expired pick and opening the stream can outlast Discord's 3s deadline.
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		return fmt.Errorf("play: failed to acknowledge pick: %w", err)
+	}
+
+	ti, ok := pickedTrack(parts[4], url)
+	if !ok {
+		tracks, resErr := c.Bot.ResolveTracks(guildID, url, "", parser)
+		if resErr != nil || len(tracks) == 0 {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Failed to resolve track: %v", resErr),
+			})
+			return nil
+		}
+		ti = tracks[0]
+	}
+	if ti.Title == "" {
+		ti.Title = pickedLabel(e.Message, url)
+	}
+	if err := p.EnqueueTrackInfoFor(ti, e.Member.User.ID); err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Queue Error",
+			Description: fmt.Sprintf("%v", err),
+		})
+		return nil
+	}
+	if !p.IsPlaying() {
+		_ = p.PlayNext(voiceState.ChannelID)
+	}
+
+	title := ti.Title
+	if title == "" {
+		title = url
+	}
+	if _, err := s.InteractionResponseEdit(e.Interaction, &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{{
+			Description: fmt.Sprintf("🎶 Added **%s** to the queue.", title),
+			Color:       discordreply.EmbedColor,
+		}},
+		Components: &[]discordgo.MessageComponent{},
+	}); err != nil {
+		return fmt.Errorf("play: failed to update picker: %w", err)
+	}
+	if _, err := c.Bot.ShowPlaybackPanel(s, e, guildID, false); err != nil {
+		ctx.AppLog.Warn().Str("guild_id", guildID).Err(err).Msg("guild_status_update_failed")
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package play
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+func TestPickerRoundTrip(t *testing.T) {
+	tracks := []sources.TrackInfo{
+		{URL: "https://www.youtube.com/watch?v=a", Title: strings.Repeat("x", 150)},
+		{URL: "https://www.youtube.com/watch?v=a", Title: "duplicate"},
+		{URL: "https://www.youtube.com/watch?v=b"},
+	}
+	components := pickerComponents("u1", "ytdlp-pipe", "i1", tracks)
+
+	// Read the menu back the way Discord hands it to Component.
+	b, err := json.Marshal(components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg discordgo.Message
+	if err := json.Unmarshal([]byte(`{"components":`+string(b)+`}`), &msg); err != nil {
+		t.Fatal(err)
+	}
+	menu := msg.Components[0].(*discordgo.ActionsRow).Components[0].(*discordgo.SelectMenu)
+	if menu.CustomID != "play:pick:u1:ytdlp-pipe:i1" {
+		t.Fatalf("custom id = %q", menu.CustomID)
+	}
+	if len(menu.Options) != 2 {
+		t.Fatalf("options = %d, want 2 (duplicates dropped)", len(menu.Options))
+	}
+	if got := pickedLabel(&msg, tracks[0].URL); len([]rune(got)) != selectTextMax || !strings.HasSuffix(got, "…") {
+		t.Fatalf("long label = %q", got)
+	}
+	if got := pickedLabel(&msg, tracks[2].URL); got != "Result 3" {
+		t.Fatalf("untitled label = %q", got)
+	}
+}
+
+func TestPickedTrackUsesSearchResult(t *testing.T) {
+	pendingPicksMu.Lock()
+	pendingPicks["i2"] = []sources.TrackInfo{{URL: "https://www.youtube.com/watch?v=a", Title: "A", AvailableParsers: []string{"ytdlp-link"}}}
+	pendingPicksMu.Unlock()
+
+	if ti, ok := pickedTrack("i2", "https://www.youtube.com/watch?v=a"); !ok || ti.Title != "A" || ti.AvailableParsers[0] != "ytdlp-link" {
+		t.Fatalf("picked = %+v, %v", ti, ok)
+	}
+	if _, ok := pickedTrack("i2", "https://www.youtube.com/watch?v=b"); ok {
+		t.Fatal("picked a URL that was not a result")
+	}
+	if _, ok := pickedTrack("expired", "https://www.youtube.com/watch?v=a"); ok {
+		t.Fatal("picked from an unknown picker")
+	}
+}
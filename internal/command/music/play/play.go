@@ -3,13 +3,16 @@ package play
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/sources"
 	"github.com/keshon/server-domme/internal/command"
 	"github.com/keshon/server-domme/internal/command/music/common"
 	"github.com/keshon/server-domme/internal/discord"
 	"github.com/keshon/server-domme/internal/discord/discordreply"
 	"github.com/keshon/server-domme/internal/discord/perm"
+	"github.com/keshon/server-domme/internal/discord/voice"
 	"github.com/keshon/server-domme/internal/storage"
 )
 
@@ -31,8 +34,14 @@ func (c *Play) SlashDefinition() *discordgo.ApplicationCommand {
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
 				Name:        "input",
-				Description: "Link, search query, or history id(s)",
-				Required:    true,
+				Description: "Link, search query, history id(s) or library track name",
+				Required:    false,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionAttachment,
+				Name:        "file",
+				Description: "Audio file to play",
+				Required:    false,
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionString,
@@ -42,6 +51,7 @@ func (c *Play) SlashDefinition() *discordgo.ApplicationCommand {
 					{Name: "YouTube", Value: "youtube"},
 					{Name: "SoundCloud", Value: "soundcloud"},
 					{Name: "Radio", Value: "radio"},
+					{Name: "Server library", Value: sources.Local},
 				},
 			},
 			{
@@ -54,8 +64,15 @@ func (c *Play) SlashDefinition() *discordgo.ApplicationCommand {
 					{Name: "kkdai pipe", Value: "kkdai-pipe"},
 					{Name: "kkdai link", Value: "kkdai-link"},
 					{Name: "ffmpeg direct link", Value: "ffmpeg-link"},
+					{Name: "ffmpeg radio (ICY titles)", Value: "ffmpeg-icy"},
+					{Name: "ffmpeg local file", Value: "ffmpeg-file"},
 				},
 			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "pick",
+				Description: fmt.Sprintf("Choose from the top %d search results instead of playing the first", pickResults),
+			},
 		},
 	}
 }
@@ -70,26 +87,39 @@ func (c *Play) Run(ctx interface{}) error {
 	e := slashCtx.Event
 	store := slashCtx.Storage
 
+	data := e.ApplicationCommandData()
 	var input, source, parser string
-	for _, opt := range e.ApplicationCommandData().Options {
+	var pick bool
+	var attachment *discordgo.MessageAttachment
+	for _, opt := range data.Options {
 		switch opt.Name {
 		case "input":
 			input = opt.StringValue()
+		case "file":
+			if id, ok := opt.Value.(string); ok && data.Resolved != nil {
+				attachment = data.Resolved.Attachments[id]
+			}
 		case "source":
 			source = opt.StringValue()
 		case "parser":
 			parser = opt.StringValue()
+		case "pick":
+			pick = opt.BoolValue()
 		}
 	}
 
-	if input == "" {
+	if input == "" && attachment == nil {
 		return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 			Title:       "🎵 Error",
-			Description: "Input is required.",
+			Description: "Give an input or attach an audio file.",
 		})
 	}
 
-	parsed, err := common.ParsePlayInput(input)
+	var parsed common.ParsedPlayInput
+	var err error
+	if input != "" {
+		parsed, err = common.ParsePlayInput(input)
+	}
 	if err != nil {
 		if errors.Is(err, common.ErrPlayInputTooManyItems) {
 			return discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
@@ -139,8 +169,47 @@ func (c *Play) Run(ctx interface{}) error {
 		return nil
 	}
 
-	switch parsed.Kind {
-	case common.PlayInputKindHistoryIDs:
+	added := 0
+	if attachment != nil {
+		ti, err := common.AttachmentTrack(attachment)
+		if err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: "The attached file is not an audio file. Attach an mp3, ogg, opus, flac, wav or m4a file.",
+			})
+			return nil
+		}
+		if err := p.EnqueueTrackInfoFor(ti, member.User.ID); err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Queue Error",
+				Description: fmt.Sprintf("%v", err),
+			})
+			return nil
+		}
+		added++
+	}
+
+	switch {
+	case input == "":
+	case parsed.Kind == common.PlayInputKindQuery && source == sources.Local:
+		ti, err := c.Bot.FindLibraryTrack(guildID, parsed.Query)
+		if err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Library",
+				Description: libraryErrorText(err, parsed.Query),
+			})
+			return nil
+		}
+		if err := p.EnqueueTrackInfoFor(ti, member.User.ID); err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Queue Error",
+				Description: fmt.Sprintf("%v", err),
+			})
+			return nil
+		}
+		added++
+
+	case parsed.Kind == common.PlayInputKindHistoryIDs:
 		if store == nil {
 			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 				Title:       "🎵 Error",
@@ -165,7 +234,7 @@ func (c *Play) Run(ctx interface{}) error {
 				return nil
 			}
 			ti := storage.TrackInfoFromMusicPlayback(mp)
-			if err := p.EnqueueTrackInfo(ti); err != nil {
+			if err := p.EnqueueTrackInfoFor(ti, member.User.ID); err != nil {
 				discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 					Title:       "🎵 Queue Error",
 					Description: fmt.Sprintf("%v", err),
@@ -173,8 +242,9 @@ func (c *Play) Run(ctx interface{}) error {
 				return nil
 			}
 		}
+		added += len(parsed.HistoryIDs)
 
-	case common.PlayInputKindURLs:
+	case parsed.Kind == common.PlayInputKindURLs:
 		for _, u := range parsed.URLs {
 			tracks, resErr := c.Bot.ResolveTracks(guildID, u, source, parser)
 			if resErr != nil || len(tracks) == 0 {
@@ -184,7 +254,7 @@ func (c *Play) Run(ctx interface{}) error {
 				})
 				return nil
 			}
-			if err := p.EnqueueTrackInfo(tracks[0]); err != nil {
+			if err := p.EnqueueTrackInfoFor(tracks[0], member.User.ID); err != nil {
 				discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 					Title:       "🎵 Queue Error",
 					Description: fmt.Sprintf("%v", err),
@@ -192,8 +262,25 @@ func (c *Play) Run(ctx interface{}) error {
 				return nil
 			}
 		}
+		added += len(parsed.URLs)
+
+	case parsed.Kind == common.PlayInputKindQuery && pick:
+		tracks, resErr := c.Bot.SearchTracks(guildID, parsed.Query, source, parser, pickResults)
+		if resErr != nil || len(tracks) == 0 {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Failed to search: %v", resErr),
+			})
+			return nil
+		}
+		// An attached file plays right away; the pick is queued when it is made.
+		if added > 0 && !p.IsPlaying() {
+			_ = p.PlayNext(voiceState.ChannelID)
+		}
+		postPicker(s, e, slashCtx.AppLog, parsed.Query, parser, tracks)
+		return nil
 
-	case common.PlayInputKindQuery:
+	case parsed.Kind == common.PlayInputKindQuery:
 		tracks, resErr := c.Bot.ResolveTracks(guildID, parsed.Query, source, parser)
 		if resErr != nil || len(tracks) == 0 {
 			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
@@ -202,19 +289,42 @@ func (c *Play) Run(ctx interface{}) error {
 			})
 			return nil
 		}
-		if err := p.EnqueueTrackInfo(tracks[0]); err != nil {
+		if err := p.EnqueueTrackInfoFor(tracks[0], member.User.ID); err != nil {
 			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
 				Title:       "🎵 Queue Error",
 				Description: fmt.Sprintf("%v", err),
 			})
 			return nil
 		}
+		added++
 	}
 
 	if !p.IsPlaying() {
 		_ = p.PlayNext(voiceState.ChannelID)
 	}
 
-	common.ListenPlayerStatusSlash(s, e, p, c.Bot, guildID, slashCtx.AppLog)
+	common.AnnouncePlayback(s, e, c.Bot, slashCtx.AppLog, fmt.Sprintf("🎶 Added %d track(s) to the queue.", added))
 	return nil
 }
+
+// libraryErrorText explains a failed library lookup, listing the candidates when the name was ambiguous.
+func libraryErrorText(err error, name string) string {
+	var amb *voice.LibraryAmbiguousError
+	switch {
+	case errors.Is(err, voice.ErrLibraryTrackNotFound):
+		return fmt.Sprintf("No track in the server library matches **%s**.", name)
+	case errors.As(err, &amb):
+		const maxShown = 10
+		shown := amb.Matches
+		if len(shown) > maxShown {
+			shown = shown[:maxShown]
+		}
+		text := fmt.Sprintf("**%s** matches %d tracks; be more specific:\n`%s`", name, len(amb.Matches), strings.Join(shown, "`\n`"))
+		if len(amb.Matches) > maxShown {
+			text += "\n…"
+		}
+		return text
+	default:
+		return fmt.Sprintf("Could not read the server library: %v", err)
+	}
+}
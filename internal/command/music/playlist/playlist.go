@@ -0,0 +1,585 @@
+package playlist
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/perm"
+	"github.com/keshon/server-domme/internal/domain"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+type Playlist struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Playlist) Name() string             { return "playlist" }
+func (c *Playlist) Description() string      { return "Save and replay track lists" }
+func (c *Playlist) Group() string            { return "music" }
+func (c *Playlist) Category() string         { return "🎵 Music" }
+func (c *Playlist) UserPermissions() []int64 { return []int64{} }
+
+// discordgo requires a pointer for MinValue on slash options.
+var playlistPositionMinValue = 1.0
+
+// playlistImportMaxBytes bounds the downloaded import attachment.
+const playlistImportMaxBytes = 512 << 10
+
+func nameOption(description string) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name",
+		Description: description,
+		Required:    true,
+		MaxLength:   storage.MusicPlaylistNameMaxRunes,
+	}
+}
+
+func (c *Playlist) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "create",
+				Description: "Create an empty playlist",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption("Playlist name")},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Add a link, history id(s) or the current track to a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Playlist name"),
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "input",
+						Description: "Link, search query or history id(s); leave empty for the current track",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a track from a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Playlist name"),
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "pos",
+						Description: "Track position as shown by /playlist show",
+						Required:    true,
+						MinValue:    &playlistPositionMinValue,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List this server's playlists",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "show",
+				Description: "Show the tracks of a playlist",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Playlist name"),
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "page",
+						Description: "Page number (default 1)",
+						Required:    false,
+						MinValue:    &playlistPositionMinValue,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Add all tracks of a playlist to the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					nameOption("Playlist name"),
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "shuffle",
+						Description: "Shuffle the queue afterwards",
+						Required:    false,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Delete a playlist",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption("Playlist name")},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "export",
+				Description: "Download a playlist as a JSON file",
+				Options:     []*discordgo.ApplicationCommandOption{nameOption("Playlist name")},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "import",
+				Description: "Import a playlist from a JSON file made by /playlist export",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionAttachment,
+						Name:        "file",
+						Description: "Exported playlist (.json)",
+						Required:    true,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "name",
+						Description: "Save under this name instead of the one in the file",
+						Required:    false,
+						MaxLength:   storage.MusicPlaylistNameMaxRunes,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "replace",
+						Description: "Overwrite an existing playlist with the same name",
+						Required:    false,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *Playlist) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	data := e.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Playlist",
+			Description: "No subcommand provided.",
+		})
+		return nil
+	}
+	if slashCtx.Storage == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Playlist storage is not available.",
+		})
+		return nil
+	}
+
+	sub := data.Options[0]
+	var embed *discordgo.MessageEmbed
+	switch sub.Name {
+	case "create":
+		embed = c.runCreate(slashCtx, sub)
+	case "add":
+		embed = c.runAdd(slashCtx, sub)
+	case "remove":
+		embed = c.runRemove(slashCtx, sub)
+	case "list":
+		embed = c.runList(slashCtx)
+	case "show":
+		embed = c.runShow(slashCtx, sub)
+	case "play":
+		// play answers through the now-playing panel itself.
+		c.runPlay(slashCtx, sub)
+		return nil
+	case "delete":
+		embed = c.runDelete(slashCtx, sub)
+	case "export":
+		embed = c.runExport(slashCtx, sub)
+	case "import":
+		embed = c.runImport(slashCtx, sub)
+	default:
+		embed = errorEmbed(fmt.Sprintf("Unknown subcommand: %s", sub.Name))
+	}
+	if embed == nil {
+		return nil
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, embed); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "playlist").Str("sub", sub.Name).Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+func stringOption(sub *discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, opt := range sub.Options {
+		if opt.Name == name {
+			return strings.TrimSpace(opt.StringValue())
+		}
+	}
+	return ""
+}
+
+func intOption(sub *discordgo.ApplicationCommandInteractionDataOption, name string, def int64) int64 {
+	for _, opt := range sub.Options {
+		if opt.Name == name {
+			return opt.IntValue()
+		}
+	}
+	return def
+}
+
+func boolOption(sub *discordgo.ApplicationCommandInteractionDataOption, name string) bool {
+	for _, opt := range sub.Options {
+		if opt.Name == name {
+			return opt.BoolValue()
+		}
+	}
+	return false
+}
+
+func errorEmbed(desc string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Playlist Error",
+		Description: desc,
+	}
+}
+
+func successEmbed(desc string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Playlist",
+		Description: desc,
+		Color:       discordreply.EmbedColor,
+	}
+}
+
+func playlistErrorEmbed(err error, name string) *discordgo.MessageEmbed {
+	switch {
+	case errors.Is(err, storage.ErrMusicPlaylistNotFound):
+		return errorEmbed(fmt.Sprintf("No playlist named **%s**. See `/playlist list`.", name))
+	case errors.Is(err, storage.ErrMusicPlaylistExists),
+		errors.Is(err, storage.ErrMusicPlaylistName),
+		errors.Is(err, storage.ErrMusicPlaylistLimit),
+		errors.Is(err, storage.ErrMusicPlaylistFull),
+		errors.Is(err, storage.ErrMusicPlaylistTrackRange),
+		errors.Is(err, storage.ErrMusicPlaylistImportShape),
+		errors.Is(err, storage.ErrMusicPlaylistImportTrack):
+		return errorEmbed(capitalize(err.Error()) + ".")
+	default:
+		return errorEmbed(fmt.Sprintf("**Error:** %v", err))
+	}
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (c *Playlist) runCreate(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	name := stringOption(sub, "name")
+	if err := ctx.Storage.CreateMusicPlaylist(ctx.Event.GuildID, name, ctx.Event.Member.User.ID, time.Now()); err != nil {
+		return playlistErrorEmbed(err, name)
+	}
+	return successEmbed(fmt.Sprintf("📁 Created playlist **%s**. Add tracks with `/playlist add`.", name))
+}
+
+func (c *Playlist) runAdd(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	name := stringOption(sub, "name")
+	tracks, errEmbed := c.tracksForAdd(ctx, stringOption(sub, "input"))
+	if errEmbed != nil {
+		return errEmbed
+	}
+
+	n, err := ctx.Storage.AddMusicPlaylistTracks(ctx.Event.GuildID, name, tracks)
+	if err != nil {
+		return playlistErrorEmbed(err, name)
+	}
+	if len(tracks) == 1 {
+		return successEmbed(fmt.Sprintf("➕ Added [%s](%s) to **%s** (%d tracks).", tracks[0].Title, tracks[0].URL, name, n))
+	}
+	return successEmbed(fmt.Sprintf("➕ Added %d tracks to **%s** (%d tracks).", len(tracks), name, n))
+}
+
+// tracksForAdd turns the add input into playlist entries: the current track when empty, history rows for ids,
+// otherwise resolver results (first match per link or query).
+func (c *Playlist) tracksForAdd(ctx *command.SlashInteractionContext, input string) ([]domain.MusicPlayback, *discordgo.MessageEmbed) {
+	guildID := ctx.Event.GuildID
+	if input == "" {
+		p := c.Bot.GetOrCreatePlayer(guildID)
+		if p == nil || p.CurrentTrack() == nil {
+			return nil, errorEmbed("Nothing is playing. Give a link, search query or history id(s).")
+		}
+		return []domain.MusicPlayback{storage.PlaylistTrackFromTrackParse(*p.CurrentTrack())}, nil
+	}
+
+	parsed, err := common.ParsePlayInput(input)
+	if err != nil {
+		if errors.Is(err, common.ErrPlayInputTooManyItems) {
+			return nil, errorEmbed("Too many tracks in one command.")
+		}
+		return nil, errorEmbed(fmt.Sprintf("Invalid input: %v", err))
+	}
+
+	var tracks []domain.MusicPlayback
+	switch parsed.Kind {
+	case common.PlayInputKindHistoryIDs:
+		for _, id := range parsed.HistoryIDs {
+			row, err := ctx.Storage.MusicPlayback(guildID, id)
+			if err != nil {
+				if errors.Is(err, storage.ErrMusicPlaybackNotFound) {
+					return nil, errorEmbed(fmt.Sprintf("Unknown history id `%d`.", id))
+				}
+				return nil, errorEmbed(fmt.Sprintf("Could not load history entry: %v", err))
+			}
+			tracks = append(tracks, storage.PlaylistTrackFromHistory(row))
+		}
+	case common.PlayInputKindURLs:
+		for _, u := range parsed.URLs {
+			resolved, err := c.Bot.ResolveTracks(guildID, u, "", "")
+			if err != nil || len(resolved) == 0 {
+				return nil, errorEmbed(fmt.Sprintf("Failed to resolve track: %v", err))
+			}
+			tracks = append(tracks, storage.PlaylistTrackFromTrackInfo(resolved[0]))
+		}
+	case common.PlayInputKindQuery:
+		resolved, err := c.Bot.ResolveTracks(guildID, parsed.Query, "", "")
+		if err != nil || len(resolved) == 0 {
+			return nil, errorEmbed(fmt.Sprintf("Failed to resolve track: %v", err))
+		}
+		tracks = append(tracks, storage.PlaylistTrackFromTrackInfo(resolved[0]))
+	}
+	return tracks, nil
+}
+
+func (c *Playlist) runRemove(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	name := stringOption(sub, "name")
+	pos := int(intOption(sub, "pos", 0))
+	removed, err := ctx.Storage.RemoveMusicPlaylistTrack(ctx.Event.GuildID, name, pos)
+	if err != nil {
+		return playlistErrorEmbed(err, name)
+	}
+	return successEmbed(fmt.Sprintf("🗑️ Removed `%d` [%s](%s) from **%s**.", pos, removed.Title, removed.URL, name))
+}
+
+func (c *Playlist) runList(ctx *command.SlashInteractionContext) *discordgo.MessageEmbed {
+	lists, err := ctx.Storage.ListMusicPlaylists(ctx.Event.GuildID)
+	if err != nil {
+		return playlistErrorEmbed(err, "")
+	}
+	if len(lists) == 0 {
+		return successEmbed("No playlists yet. Create one with `/playlist create`.")
+	}
+	lines := make([]string, 0, len(lists))
+	for _, pl := range lists {
+		lines = append(lines, fmt.Sprintf("**%s** — %d track(s)", pl.Name, len(pl.Tracks)))
+	}
+	embed := successEmbed(strings.Join(lines, "\n"))
+	embed.Title = "🎵 Playlists"
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: "Play one with `/playlist play <name>`."}
+	return embed
+}
+
+func (c *Playlist) runShow(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	name := stringOption(sub, "name")
+	pl, err := ctx.Storage.MusicPlaylist(ctx.Event.GuildID, name)
+	if err != nil {
+		return playlistErrorEmbed(err, name)
+	}
+	if len(pl.Tracks) == 0 {
+		embed := successEmbed("This playlist is empty. Add tracks with `/playlist add`.")
+		embed.Title = "🎵 " + pl.Name
+		return embed
+	}
+
+	lines := make([]string, 0, len(pl.Tracks))
+	for i, t := range pl.Tracks {
+		lines = append(lines, common.FormatPlaylistLine(i+1, t))
+	}
+	pageLines, page, totalPages := common.Paginate(lines, intOption(sub, "page", 1), common.LinesPerPage)
+	desc := strings.Join(pageLines, "\n")
+	if len(desc) > 4000 {
+		desc = desc[:3997] + "..."
+	}
+	embed := successEmbed(desc)
+	embed.Title = "🎵 " + pl.Name
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: fmt.Sprintf("Page %d/%d (%d tracks). Play with `/playlist play`.", page, totalPages, len(pl.Tracks)),
+	}
+	return embed
+}
+
+func (c *Playlist) runPlay(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	s, e := ctx.Session, ctx.Event
+	name := stringOption(sub, "name")
+
+	pl, err := ctx.Storage.MusicPlaylist(e.GuildID, name)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, playlistErrorEmbed(err, name))
+		return
+	}
+	if len(pl.Tracks) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed(fmt.Sprintf("**%s** is empty.", pl.Name)))
+		return
+	}
+
+	voiceState, err := c.Bot.FindUserVoiceState(e.GuildID, e.Member.User.ID)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: fmt.Sprintf("%v", err),
+		})
+		return
+	}
+	permOK, err := perm.CheckBotVoicePermissions(s, voiceState.ChannelID)
+	if err != nil || !permOK {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: "I don't have permission to join or speak in that voice channel.",
+		})
+		return
+	}
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music service is not available.",
+		})
+		return
+	}
+
+	added := 0
+	for _, t := range pl.Tracks {
+		if err := p.EnqueueTrackInfoFor(storage.TrackInfoFromMusicPlayback(t), e.Member.User.ID); err != nil {
+			ctx.AppLog.Warn().Str("guild_id", e.GuildID).Str("url", t.URL).Err(err).Msg("playlist_track_skipped")
+			continue
+		}
+		added++
+	}
+	if boolOption(sub, "shuffle") {
+		c.Bot.QueueShuffle(e.GuildID)
+	}
+	if !p.IsPlaying() {
+		_ = p.PlayNext(voiceState.ChannelID)
+	}
+
+	common.AnnouncePlayback(s, e, c.Bot, ctx.AppLog, fmt.Sprintf("🎶 Added %d track(s) from **%s** to the queue.", added, pl.Name))
+}
+
+func (c *Playlist) runDelete(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	name := stringOption(sub, "name")
+	if err := ctx.Storage.DeleteMusicPlaylist(ctx.Event.GuildID, name); err != nil {
+		return playlistErrorEmbed(err, name)
+	}
+	return successEmbed(fmt.Sprintf("🗑️ Deleted playlist **%s**.", name))
+}
+
+func (c *Playlist) runExport(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	s, e := ctx.Session, ctx.Event
+	name := stringOption(sub, "name")
+	pl, err := ctx.Storage.MusicPlaylist(e.GuildID, name)
+	if err != nil {
+		return playlistErrorEmbed(err, name)
+	}
+	data, err := storage.MarshalMusicPlaylist(pl)
+	if err != nil {
+		return playlistErrorEmbed(err, name)
+	}
+
+	if _, err := s.FollowupMessageCreate(e.Interaction, true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{successEmbed(fmt.Sprintf("📤 **%s** (%d tracks). Import it with `/playlist import`.", pl.Name, len(pl.Tracks)))},
+		Files: []*discordgo.File{{
+			Name:        exportFileName(pl.Name),
+			ContentType: "application/json",
+			Reader:      bytes.NewReader(data),
+		}},
+	}); err != nil {
+		ctx.AppLog.Warn().Str("command", "playlist").Str("sub", "export").Err(err).Msg("followup_file_failed")
+	}
+	return nil
+}
+
+// exportFileName keeps letters, digits, '-' and '_' from the playlist name.
+func exportFileName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 {
+		return "playlist.json"
+	}
+	return "playlist_" + b.String() + ".json"
+}
+
+func (c *Playlist) runImport(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	e := ctx.Event
+
+	var attachmentID string
+	for _, opt := range sub.Options {
+		if opt.Name == "file" {
+			attachmentID, _ = opt.Value.(string)
+		}
+	}
+	resolved := e.ApplicationCommandData().Resolved
+	if resolved == nil || resolved.Attachments[attachmentID] == nil {
+		return errorEmbed("Failed to get the uploaded file.")
+	}
+	attachment := resolved.Attachments[attachmentID]
+	if attachment.Size > playlistImportMaxBytes {
+		return errorEmbed("The file is too large for a playlist export.")
+	}
+
+	resp, err := http.Get(attachment.URL)
+	if err != nil {
+		return errorEmbed(fmt.Sprintf("Failed to download the uploaded file: %v", err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errorEmbed(fmt.Sprintf("Failed to download the uploaded file: %v", resp.Status))
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, playlistImportMaxBytes))
+	if err != nil {
+		return errorEmbed(fmt.Sprintf("Failed to read the uploaded file: %v", err))
+	}
+	pl, err := storage.UnmarshalMusicPlaylist(body)
+	if err != nil {
+		return playlistErrorEmbed(err, "")
+	}
+	if name := stringOption(sub, "name"); name != "" {
+		pl.Name = name
+	}
+	pl.CreatedBy = e.Member.User.ID
+	pl.CreatedAt = time.Now()
+
+	if err := ctx.Storage.ImportMusicPlaylist(e.GuildID, pl, boolOption(sub, "replace")); err != nil {
+		if errors.Is(err, storage.ErrMusicPlaylistExists) {
+			return errorEmbed(fmt.Sprintf("A playlist named **%s** already exists. Pick another `name` or set `replace`.", pl.Name))
+		}
+		return playlistErrorEmbed(err, pl.Name)
+	}
+	return successEmbed(fmt.Sprintf("📥 Imported **%s** (%d tracks).", pl.Name, len(pl.Tracks)))
+}
@@ -0,0 +1,263 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+)
+
+type Queue struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Queue) Name() string             { return "queue" }
+func (c *Queue) Description() string      { return "View and edit the upcoming tracks" }
+func (c *Queue) Group() string            { return "music" }
+func (c *Queue) Category() string         { return "🎵 Music" }
+func (c *Queue) UserPermissions() []int64 { return []int64{} }
+
+// discordgo requires a pointer for MinValue on slash options.
+var queuePositionMinValue = 1.0
+
+func (c *Queue) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "view",
+				Description: "Show the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "page",
+						Description: "Page number (default 1)",
+						Required:    false,
+						MinValue:    &queuePositionMinValue,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Remove a track from the queue",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "pos",
+						Description: "Queue position as shown by /queue view",
+						Required:    true,
+						MinValue:    &queuePositionMinValue,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "move",
+				Description: "Move a track to another queue position",
+				Options: []*discordgo.ApplicationCommandOption{
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "from",
+						Description: "Current queue position",
+						Required:    true,
+						MinValue:    &queuePositionMinValue,
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionInteger,
+						Name:        "to",
+						Description: "New queue position",
+						Required:    true,
+						MinValue:    &queuePositionMinValue,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "clear",
+				Description: "Remove all upcoming tracks (the current track keeps playing)",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "shuffle",
+				Description: "Shuffle the upcoming tracks",
+			},
+		},
+	}
+}
+
+func (c *Queue) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	data := e.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Queue",
+			Description: "No subcommand provided.",
+		})
+		return nil
+	}
+
+	sub := data.Options[0]
+	var embed *discordgo.MessageEmbed
+	switch sub.Name {
+	case "view":
+		embed = c.runView(e.GuildID, sub)
+	case "remove":
+		embed = c.runRemove(e.GuildID, sub)
+	case "move":
+		embed = c.runMove(e.GuildID, sub)
+	case "clear":
+		embed = c.runClear(e.GuildID)
+	case "shuffle":
+		embed = c.runShuffle(e.GuildID)
+	default:
+		embed = &discordgo.MessageEmbed{
+			Title:       "🎵 Queue",
+			Description: fmt.Sprintf("Unknown subcommand: %s", sub.Name),
+		}
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, embed); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "queue").Str("sub", sub.Name).Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+// truncate shortens s to at most limit runes, ending in "..." when cut. Embed limits count characters,
+// and cutting bytes could split a multi-byte title.
+func truncate(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit-3]) + "..."
+}
+
+func intOption(sub *discordgo.ApplicationCommandInteractionDataOption, name string, def int64) int64 {
+	for _, opt := range sub.Options {
+		if opt.Name == name {
+			return opt.IntValue()
+		}
+	}
+	return def
+}
+
+func (c *Queue) runView(guildID string, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	page := intOption(sub, "page", 1)
+	tracks := c.Bot.Queue(guildID)
+
+	var nowPlaying string
+	if p := c.Bot.ExistingPlayer(guildID); p != nil {
+		if t := p.CurrentTrack(); t != nil {
+			nowPlaying = fmt.Sprintf("**Now playing:** [%s](%s)\n\n", t.Title, t.URL)
+		}
+	}
+
+	if len(tracks) == 0 {
+		return &discordgo.MessageEmbed{
+			Title:       "🎵 Queue",
+			Description: nowPlaying + "The queue is empty. Add tracks with `/play`.",
+			Color:       discordreply.EmbedColor,
+		}
+	}
+
+	lines := make([]string, 0, len(tracks))
+	for i, t := range tracks {
+		lines = append(lines, common.FormatQueueLine(i+1, t))
+	}
+	pageLines, page, totalPages := common.Paginate(lines, page, common.LinesPerPage)
+
+	desc := nowPlaying + strings.Join(pageLines, "\n")
+	desc = truncate(desc, 4000)
+
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Queue",
+		Description: desc,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d/%d (%d tracks). Edit with `/queue remove|move|clear|shuffle`.", page, totalPages, len(tracks)),
+		},
+		Color: discordreply.EmbedColor,
+	}
+}
+
+func (c *Queue) runRemove(guildID string, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	pos := int(intOption(sub, "pos", 0))
+	track, err := c.Bot.QueueRemove(guildID, pos)
+	if err != nil {
+		return queueErrorEmbed(err, len(c.Bot.Queue(guildID)))
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Queue",
+		Description: fmt.Sprintf("🗑️ Removed `%d` [%s](%s) from the queue.", pos, track.Title, track.URL),
+		Color:       discordreply.EmbedColor,
+	}
+}
+
+func (c *Queue) runMove(guildID string, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	from := int(intOption(sub, "from", 0))
+	to := int(intOption(sub, "to", 0))
+	if err := c.Bot.QueueMove(guildID, from, to); err != nil {
+		return queueErrorEmbed(err, len(c.Bot.Queue(guildID)))
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Queue",
+		Description: fmt.Sprintf("↕️ Moved track from position `%d` to `%d`.", from, to),
+		Color:       discordreply.EmbedColor,
+	}
+}
+
+func (c *Queue) runClear(guildID string) *discordgo.MessageEmbed {
+	n := c.Bot.QueueClear(guildID)
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Queue",
+		Description: fmt.Sprintf("🧹 Cleared %d track(s) from the queue.", n),
+		Color:       discordreply.EmbedColor,
+	}
+}
+
+func (c *Queue) runShuffle(guildID string) *discordgo.MessageEmbed {
+	if len(c.Bot.Queue(guildID)) == 0 {
+		return queueErrorEmbed(player.ErrNoTracksInQueue, 0)
+	}
+	c.Bot.QueueShuffle(guildID)
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Queue",
+		Description: "🔀 Queue shuffled.",
+		Color:       discordreply.EmbedColor,
+	}
+}
+
+func queueErrorEmbed(err error, queueLen int) *discordgo.MessageEmbed {
+	desc := fmt.Sprintf("**Error:** %v", err)
+	switch {
+	case errors.Is(err, player.ErrNoTracksInQueue) || queueLen == 0:
+		desc = "The queue is empty."
+	case errors.Is(err, player.ErrQueueIndexRange):
+		desc = fmt.Sprintf("Position out of range. The queue has %d track(s).", queueLen)
+	}
+	return &discordgo.MessageEmbed{
+		Title:       "🎵 Queue Error",
+		Description: desc,
+	}
+}
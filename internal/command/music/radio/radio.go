@@ -0,0 +1,316 @@
+package radio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/perm"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+type Radio struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Radio) Name() string             { return "radio" }
+func (c *Radio) Description() string      { return "Play saved internet radio stations" }
+func (c *Radio) Group() string            { return "music" }
+func (c *Radio) Category() string         { return "🎵 Music" }
+func (c *Radio) UserPermissions() []int64 { return []int64{} }
+
+// radioAutocompleteMax is the Discord limit on autocomplete choices.
+const radioAutocompleteMax = 25
+
+func stationOption(autocomplete bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "name",
+		Description:  "Station name",
+		Required:     true,
+		MaxLength:    storage.MusicRadioStationNameMaxRunes,
+		Autocomplete: autocomplete,
+	}
+}
+
+func (c *Radio) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "play",
+				Description: "Tune in to a saved station",
+				Options:     []*discordgo.ApplicationCommandOption{stationOption(true)},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List this server's stations",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Save a station stream URL (admins)",
+				Options: []*discordgo.ApplicationCommandOption{
+					stationOption(false),
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "url",
+						Description: "Stream URL (Icecast/Shoutcast, .m3u or .pls)",
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Delete a saved station (admins)",
+				Options:     []*discordgo.ApplicationCommandOption{stationOption(true)},
+			},
+		},
+	}
+}
+
+func (c *Radio) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	data := e.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("No subcommand provided."))
+		return nil
+	}
+	if slashCtx.Storage == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Radio storage is not available.",
+		})
+		return nil
+	}
+
+	sub := data.Options[0]
+	if (sub.Name == "add" || sub.Name == "remove") && !canManageStations(e.Member) {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Only server admins can add or remove stations."))
+		return nil
+	}
+
+	var embed *discordgo.MessageEmbed
+	switch sub.Name {
+	case "play":
+		// play answers through the now-playing panel itself.
+		c.runPlay(slashCtx, sub)
+		return nil
+	case "list":
+		embed = c.runList(slashCtx)
+	case "add":
+		embed = c.runAdd(slashCtx, sub)
+	case "remove":
+		embed = c.runRemove(slashCtx, sub)
+	default:
+		embed = errorEmbed(fmt.Sprintf("Unknown subcommand: %s", sub.Name))
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, embed); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "radio").Str("sub", sub.Name).Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+// Autocomplete suggests saved stations whose name contains what the member has typed so far.
+func (c *Radio) Autocomplete(ctx *command.AutocompleteInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+
+	var typed string
+	data := e.ApplicationCommandData()
+	if len(data.Options) > 0 {
+		for _, opt := range data.Options[0].Options {
+			if opt.Focused {
+				typed = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+			}
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if ctx.Storage != nil {
+		stations, err := ctx.Storage.ListMusicRadioStations(e.GuildID)
+		if err != nil {
+			return err
+		}
+		for _, st := range stations {
+			if len(choices) == radioAutocompleteMax {
+				break
+			}
+			if typed == "" || strings.Contains(strings.ToLower(st.Name), typed) {
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: st.Name, Value: st.Name})
+			}
+		}
+	}
+
+	return s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// canManageStations reports whether member may add or remove stations: administrators and server managers.
+func canManageStations(member *discordgo.Member) bool {
+	return member != nil && member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0
+}
+
+func stringOption(sub *discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, opt := range sub.Options {
+		if opt.Name == name {
+			return strings.TrimSpace(opt.StringValue())
+		}
+	}
+	return ""
+}
+
+func errorEmbed(desc string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "📻 Radio Error",
+		Description: desc,
+	}
+}
+
+func successEmbed(desc string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "📻 Radio",
+		Description: desc,
+		Color:       discordreply.EmbedColor,
+	}
+}
+
+func radioErrorEmbed(err error, name string) *discordgo.MessageEmbed {
+	switch {
+	case errors.Is(err, storage.ErrMusicRadioStationNotFound):
+		return errorEmbed(fmt.Sprintf("No station named **%s**. See `/radio list`.", name))
+	case errors.Is(err, storage.ErrMusicRadioStationExists),
+		errors.Is(err, storage.ErrMusicRadioStationName),
+		errors.Is(err, storage.ErrMusicRadioStationLimit):
+		msg := err.Error()
+		return errorEmbed(strings.ToUpper(msg[:1]) + msg[1:] + ".")
+	default:
+		return errorEmbed(fmt.Sprintf("**Error:** %v", err))
+	}
+}
+
+func (c *Radio) runList(ctx *command.SlashInteractionContext) *discordgo.MessageEmbed {
+	stations, err := ctx.Storage.ListMusicRadioStations(ctx.Event.GuildID)
+	if err != nil {
+		return radioErrorEmbed(err, "")
+	}
+	if len(stations) == 0 {
+		return successEmbed("No stations yet. An admin can save one with `/radio add`.")
+	}
+	lines := make([]string, 0, len(stations))
+	for _, st := range stations {
+		lines = append(lines, fmt.Sprintf("**%s** — <%s>", st.Name, st.URL))
+	}
+	desc := strings.Join(lines, "\n")
+	if len(desc) > 4000 {
+		desc = desc[:3997] + "..."
+	}
+	embed := successEmbed(desc)
+	embed.Title = "📻 Radio stations"
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: "Tune in with `/radio play <name>`."}
+	return embed
+}
+
+func (c *Radio) runAdd(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	e := ctx.Event
+	name := stringOption(sub, "name")
+	url := stringOption(sub, "url")
+
+	// Check the URL the same way /play would, so a typo fails here rather than on air.
+	if _, err := c.Bot.ResolveTracks(e.GuildID, url, sources.Radio, ""); err != nil {
+		return errorEmbed(fmt.Sprintf("That does not look like a stream URL.\n\n**Error:** %v", err))
+	}
+	if err := ctx.Storage.AddMusicRadioStation(e.GuildID, name, url, e.Member.User.ID, time.Now()); err != nil {
+		return radioErrorEmbed(err, name)
+	}
+	return successEmbed(fmt.Sprintf("Saved station **%s**. Tune in with `/radio play`.", name))
+}
+
+func (c *Radio) runRemove(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	name := stringOption(sub, "name")
+	st, err := ctx.Storage.RemoveMusicRadioStation(ctx.Event.GuildID, name)
+	if err != nil {
+		return radioErrorEmbed(err, name)
+	}
+	return successEmbed(fmt.Sprintf("Removed station **%s**.", st.Name))
+}
+
+func (c *Radio) runPlay(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) {
+	s, e := ctx.Session, ctx.Event
+	name := stringOption(sub, "name")
+
+	st, err := ctx.Storage.MusicRadioStation(e.GuildID, name)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, radioErrorEmbed(err, name))
+		return
+	}
+
+	voiceState, err := c.Bot.FindUserVoiceState(e.GuildID, e.Member.User.ID)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: fmt.Sprintf("%v", err),
+		})
+		return
+	}
+	permOK, err := perm.CheckBotVoicePermissions(s, voiceState.ChannelID)
+	if err != nil || !permOK {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: "I don't have permission to join or speak in that voice channel.",
+		})
+		return
+	}
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music service is not available.",
+		})
+		return
+	}
+
+	tracks, err := c.Bot.ResolveTracks(e.GuildID, st.URL, sources.Radio, "")
+	if err != nil || len(tracks) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed(fmt.Sprintf("Could not tune in to **%s**.\n\n**Error:** %v", st.Name, err)))
+		return
+	}
+	ti := tracks[0]
+	ti.Title = st.Name
+	if err := p.EnqueueTrackInfoFor(ti, e.Member.User.ID); err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed(fmt.Sprintf("Could not queue **%s**.\n\n**Error:** %v", st.Name, err)))
+		return
+	}
+	if !p.IsPlaying() {
+		_ = p.PlayNext(voiceState.ChannelID)
+	}
+
+	common.AnnouncePlayback(s, e, c.Bot, ctx.AppLog, fmt.Sprintf("📻 Tuning in to **%s**.", st.Name))
+}
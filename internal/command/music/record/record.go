@@ -0,0 +1,347 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/config"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/perm"
+	"github.com/keshon/server-domme/internal/discord/voice"
+)
+
+// Uploads above Discord's default limit for bots, or with more files than a message holds, are linked
+// (or pointed to on the server) instead.
+const (
+	recordUploadMaxBytes = 10 << 20
+	recordUploadMaxFiles = 10
+)
+
+type Record struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Record) Name() string             { return "record" }
+func (c *Record) Description() string      { return "Record your voice channel, with everyone's consent" }
+func (c *Record) Group() string            { return "music" }
+func (c *Record) Category() string         { return "🎵 Music" }
+func (c *Record) UserPermissions() []int64 { return []int64{} }
+
+func (c *Record) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "start",
+				Description: "Start recording; only members who accept the prompt are captured",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "stop",
+				Description: "Stop recording and post the files (whoever started it, or admins)",
+			},
+		},
+	}
+}
+
+func (c *Record) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	data := e.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("No subcommand provided."))
+		return nil
+	}
+	if e.Member == nil || e.Member.User == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Recording only works in a server."))
+		return nil
+	}
+
+	switch sub := data.Options[0]; sub.Name {
+	case "start":
+		// start answers with its own message carrying the consent buttons.
+		c.runStart(slashCtx)
+	case "stop":
+		c.runStop(slashCtx)
+	default:
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed(fmt.Sprintf("Unknown subcommand: %s", sub.Name)))
+	}
+	return nil
+}
+
+func (c *Record) runStart(ctx *command.SlashInteractionContext) {
+	s, e := ctx.Session, ctx.Event
+	voiceState, err := c.Bot.FindUserVoiceState(e.GuildID, e.Member.User.ID)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: fmt.Sprintf("%v", err),
+		})
+		return
+	}
+	permOK, err := perm.CheckBotVoicePermissions(s, voiceState.ChannelID)
+	if err != nil || !permOK {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: "I don't have permission to join or speak in that voice channel.",
+		})
+		return
+	}
+
+	info, err := c.Bot.StartRecording(e.GuildID, voiceState.ChannelID, e.ChannelID, e.Member.User.ID)
+	switch {
+	case errors.Is(err, voice.ErrRecordingActive):
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("A recording is already running here. Stop it first with `/record stop`."))
+		return
+	case errors.Is(err, voice.ErrRecordingOtherChannel):
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Join the voice channel I'm in to record it."))
+		return
+	case err != nil:
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed(fmt.Sprintf("Could not start recording.\n\n**Error:** %v", err)))
+		return
+	}
+
+	if _, err := s.FollowupMessageCreate(e.Interaction, true, &discordgo.WebhookParams{
+		Embeds:     []*discordgo.MessageEmbed{consentEmbed(info)},
+		Components: consentButtons(info.ID),
+	}); err != nil {
+		ctx.AppLog.Warn().Str("command", "record").Str("sub", "start").Err(err).Msg("followup_embed_failed")
+	}
+}
+
+// consentEmbed is the consent prompt, listing who has answered so far.
+func consentEmbed(info voice.RecordingInfo) *discordgo.MessageEmbed {
+	desc := fmt.Sprintf(
+		"<@%s> is recording <#%s>.\n\nOnly members who press **Accept** are recorded. Press **Decline** at any time to stop being recorded.",
+		info.StartedBy, info.VoiceChannelID,
+	)
+	return &discordgo.MessageEmbed{
+		Title:       "⏺ Recording",
+		Description: desc,
+		Color:       discordreply.EmbedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Accepted", Value: mentions(info.Accepted), Inline: true},
+			{Name: "Declined", Value: mentions(info.Declined), Inline: true},
+		},
+		Timestamp: info.Started.Format(time.RFC3339),
+	}
+}
+
+func consentButtons(id string) []discordgo.MessageComponent {
+	prefix := fmt.Sprintf("record:%s", id)
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "✅ Accept", Style: discordgo.SecondaryButton, CustomID: prefix + ":accept"},
+			discordgo.Button{Label: "❌ Decline", Style: discordgo.SecondaryButton, CustomID: prefix + ":deny"},
+		}},
+	}
+}
+
+func mentions(userIDs []string) string {
+	if len(userIDs) == 0 {
+		return "—"
+	}
+	sort.Strings(userIDs)
+	out := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		out[i] = "<@" + id + ">"
+	}
+	return strings.Join(out, "\n")
+}
+
+// Component handles the consent buttons ("record:<recording id>:accept|deny"). The answer applies to
+// whoever clicks, and the prompt is updated to show it.
+func (c *Record) Component(ctx *command.ComponentInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+	parts := strings.Split(e.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 || parts[0] != "record" || (parts[2] != "accept" && parts[2] != "deny") {
+		discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "Something smells off about this button.",
+		})
+		return nil
+	}
+	if e.Member == nil || e.Member.User == nil {
+		return nil
+	}
+
+	info, err := c.Bot.SetRecordingConsent(e.GuildID, parts[1], e.Member.User.ID, parts[2] == "accept")
+	if errors.Is(err, voice.ErrNoRecording) {
+		discordreply.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "This recording is already over.",
+		})
+		return nil
+	}
+	if err != nil {
+		discordreply.RespondEmbedEphemeral(s, e, errorEmbed(fmt.Sprintf("Could not save your answer.\n\n**Error:** %v", err)))
+		return nil
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{consentEmbed(info)},
+			Components: consentButtons(info.ID),
+		},
+	}); err != nil {
+		return fmt.Errorf("record: failed to update message: %w", err)
+	}
+	return nil
+}
+
+// canStop reports whether member may stop the recording: whoever started it, administrators and server
+// managers.
+func canStop(member *discordgo.Member, info voice.RecordingInfo) bool {
+	return member.User.ID == info.StartedBy || member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0
+}
+
+func (c *Record) runStop(ctx *command.SlashInteractionContext) {
+	s, e := ctx.Session, ctx.Event
+	info, ok := c.Bot.Recording(e.GuildID)
+	if !ok {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Nothing is being recorded."))
+		return
+	}
+	if !canStop(e.Member, info) {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Only whoever started the recording, or an admin, can stop it."))
+		return
+	}
+
+	res, err := c.Bot.StopRecording(e.GuildID)
+	if errors.Is(err, voice.ErrNoRecording) {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Nothing is being recorded."))
+		return
+	}
+	if res == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed(fmt.Sprintf("Could not stop recording.\n\n**Error:** %v", err)))
+		return
+	}
+
+	embed, files := resultMessage(ctx.Config, res, err)
+	defer func() {
+		for _, f := range files {
+			if closer, ok := f.Reader.(*os.File); ok {
+				_ = closer.Close()
+			}
+		}
+	}()
+	if _, err := s.FollowupMessageCreate(e.Interaction, true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files:  files,
+	}); err != nil {
+		ctx.AppLog.Warn().Str("command", "record").Str("sub", "stop").Err(err).Msg("followup_embed_failed")
+	}
+}
+
+// resultMessage describes a finished recording and attaches its files when they fit in one message;
+// otherwise it links them (with cfg.RecordingsURL) or names the directory on the server. writeErr is an
+// error that cut the recording short.
+func resultMessage(cfg *config.Config, res *voice.RecordingResult, writeErr error) (*discordgo.MessageEmbed, []*discordgo.File) {
+	users := make([]string, 0, len(res.UserFiles))
+	for id := range res.UserFiles {
+		users = append(users, id)
+	}
+	sort.Strings(users)
+	paths := []string{res.MixFile}
+	for _, id := range users {
+		paths = append(paths, res.UserFiles[id])
+	}
+
+	desc := fmt.Sprintf("Recorded <#%s> for **%s**.", res.VoiceChannelID, res.Duration.Round(time.Second))
+	if res.Interrupted {
+		desc += "\nThe recording ended early because I left the voice channel."
+	}
+	if writeErr != nil {
+		desc += fmt.Sprintf("\n\n**Error:** %v", writeErr)
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "⏹ Recording stopped",
+		Description: desc,
+		Color:       discordreply.EmbedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Recorded members", Value: mentions(users)},
+		},
+	}
+
+	if files, ok := openFiles(paths); ok {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: "mixed.ogg has everyone; each member's track is named after their user ID."}
+		return embed, files
+	}
+
+	if cfg != nil && cfg.RecordingsURL != "" {
+		var links []string
+		for _, path := range paths {
+			rel, err := filepath.Rel(cfg.RecordingsDir, path)
+			if err != nil {
+				continue
+			}
+			links = append(links, fmt.Sprintf("[%s](%s/%s)", filepath.Base(path), strings.TrimSuffix(cfg.RecordingsURL, "/"), filepath.ToSlash(rel)))
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Download", Value: strings.Join(links, "\n")})
+		return embed, nil
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:  "Saved on the server",
+		Value: fmt.Sprintf("Too large to upload; the files are in `%s`.", res.Dir),
+	})
+	return embed, nil
+}
+
+// openFiles opens paths as attachments. It returns false (with nothing left open) when they exceed what
+// one message can carry.
+func openFiles(paths []string) ([]*discordgo.File, bool) {
+	if len(paths) > recordUploadMaxFiles {
+		return nil, false
+	}
+	var total int64
+	for _, path := range paths {
+		st, err := os.Stat(path)
+		if err != nil {
+			return nil, false
+		}
+		total += st.Size()
+	}
+	if total > recordUploadMaxBytes {
+		return nil, false
+	}
+	files := make([]*discordgo.File, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			for _, opened := range files {
+				_ = opened.Reader.(*os.File).Close()
+			}
+			return nil, false
+		}
+		files = append(files, &discordgo.File{Name: filepath.Base(path), ContentType: "audio/ogg", Reader: f})
+	}
+	return files, true
+}
+
+func errorEmbed(desc string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "⏺ Recording Error",
+		Description: desc,
+	}
+}
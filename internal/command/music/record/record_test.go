@@ -0,0 +1,66 @@
+package record
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keshon/server-domme/internal/config"
+	"github.com/keshon/server-domme/internal/discord/voice"
+)
+
+func writeRecording(t *testing.T, base string, mixSize int) *voice.RecordingResult {
+	t.Helper()
+	dir := filepath.Join(base, "g1", "20260101-120000")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	res := &voice.RecordingResult{
+		Dir:       dir,
+		MixFile:   filepath.Join(dir, "mixed.ogg"),
+		UserFiles: map[string]string{"u1": filepath.Join(dir, "u1.ogg")},
+	}
+	for path, size := range map[string]int{res.MixFile: mixSize, res.UserFiles["u1"]: 100} {
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res
+}
+
+func TestResultMessageAttachesSmallRecordings(t *testing.T) {
+	res := writeRecording(t, t.TempDir(), 1000)
+	embed, files := resultMessage(&config.Config{}, res, nil)
+	defer func() {
+		for _, f := range files {
+			_ = f.Reader.(*os.File).Close()
+		}
+	}()
+	if len(files) != 2 || files[0].Name != "mixed.ogg" || files[1].Name != "u1.ogg" {
+		t.Fatalf("files = %v", files)
+	}
+	if embed.Fields[0].Value != "<@u1>" {
+		t.Fatalf("recorded members = %q", embed.Fields[0].Value)
+	}
+}
+
+func TestResultMessageLinksLargeRecordings(t *testing.T) {
+	base := t.TempDir()
+	res := writeRecording(t, base, recordUploadMaxBytes)
+	cfg := &config.Config{RecordingsDir: base, RecordingsURL: "https://rec.example/"}
+	embed, files := resultMessage(cfg, res, nil)
+	if files != nil {
+		t.Fatalf("attached %d files over the limit", len(files))
+	}
+	links := embed.Fields[len(embed.Fields)-1].Value
+	if !strings.Contains(links, "(https://rec.example/g1/20260101-120000/mixed.ogg)") {
+		t.Fatalf("links = %q", links)
+	}
+
+	// Without a public URL the directory on the server is named.
+	embed, _ = resultMessage(&config.Config{RecordingsDir: base}, res, nil)
+	if got := embed.Fields[len(embed.Fields)-1].Value; !strings.Contains(got, res.Dir) {
+		t.Fatalf("server path field = %q", got)
+	}
+}
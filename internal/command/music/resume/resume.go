@@ -0,0 +1,98 @@
+package resume
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/voice"
+)
+
+type Resume struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Resume) Name() string { return "resume" }
+func (c *Resume) Description() string {
+	return "Resume the paused track or the queue saved before a restart"
+}
+func (c *Resume) Group() string            { return "music" }
+func (c *Resume) Category() string         { return "🎵 Music" }
+func (c *Resume) UserPermissions() []int64 { return []int64{} }
+
+func (c *Resume) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+	}
+}
+
+func (c *Resume) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music service is not available.",
+		})
+		return nil
+	}
+
+	if err := p.Resume(); err != nil {
+		if !p.IsPlaying() {
+			// Nothing to unpause: continue the session saved before a restart, if any.
+			if c.restoreSession(slashCtx) {
+				return nil
+			}
+		}
+		desc := fmt.Sprintf("Failed to resume.\n\n**Error:** %v", err)
+		if errors.Is(err, player.ErrNotPaused) {
+			desc = "Playback is not paused."
+		}
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Resume",
+			Description: desc,
+		})
+		return nil
+	}
+
+	common.ReplyPlaybackChange(s, e, p, slashCtx.AppLog, "▶️ Playback resumed.")
+	return nil
+}
+
+// restoreSession replies and returns true when a saved session was restored or failed to restore;
+// it returns false (without replying) when there is no saved session.
+func (c *Resume) restoreSession(slashCtx *command.SlashInteractionContext) bool {
+	s, e := slashCtx.Session, slashCtx.Event
+	n, err := c.Bot.RestoreSession(e.GuildID)
+	if errors.Is(err, voice.ErrNoSavedSession) {
+		return false
+	}
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Resume",
+			Description: fmt.Sprintf("Failed to restore the saved queue.\n\n**Error:** %v", err),
+		})
+		return true
+	}
+	common.AnnouncePlayback(s, e, c.Bot, slashCtx.AppLog, fmt.Sprintf("▶️ Restored %d track(s) from before the restart.", n))
+	return true
+}
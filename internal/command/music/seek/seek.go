@@ -0,0 +1,97 @@
+package seek
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+)
+
+type Seek struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Seek) Name() string             { return "seek" }
+func (c *Seek) Description() string      { return "Jump to a position in the current track" }
+func (c *Seek) Group() string            { return "music" }
+func (c *Seek) Category() string         { return "🎵 Music" }
+func (c *Seek) UserPermissions() []int64 { return []int64{} }
+
+func (c *Seek) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "position",
+				Description: "Position as mm:ss (or hh:mm:ss)",
+				Required:    true,
+			},
+		},
+	}
+}
+
+func (c *Seek) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	var input string
+	for _, opt := range e.ApplicationCommandData().Options {
+		if opt.Name == "position" {
+			input = opt.StringValue()
+		}
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	pos, err := common.ParseSeekPosition(input)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Seek",
+			Description: fmt.Sprintf("Could not read `%s`. Use `mm:ss`, e.g. `1:30`.", input),
+		})
+		return nil
+	}
+
+	p := c.Bot.GetOrCreatePlayer(e.GuildID)
+	if p == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music service is not available.",
+		})
+		return nil
+	}
+
+	if err := p.Seek(pos); err != nil {
+		desc := fmt.Sprintf("Failed to seek.\n\n**Error:** %v", err)
+		switch {
+		case errors.Is(err, player.ErrNoTrackPlaying):
+			desc = "Nothing is playing right now."
+		case errors.Is(err, player.ErrSeekOutOfRange):
+			desc = fmt.Sprintf("`%s` is past the end of the track.", common.FormatTrackTime(pos))
+		}
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Seek",
+			Description: desc,
+		})
+		return nil
+	}
+
+	common.ReplyPlaybackChange(s, e, p, slashCtx.AppLog, fmt.Sprintf("⏩ Jumped to `%s`.", common.FormatTrackTime(pos)))
+	return nil
+}
@@ -0,0 +1,132 @@
+package soundboard
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/perm"
+	"github.com/keshon/server-domme/internal/discord/voice"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+// SfxComponentPrefix is the custom ID prefix of the soundboard buttons ("sfx:<clip name>"). It equals
+// the name of the sfx command so the component router hands the clicks to it.
+const SfxComponentPrefix = "sfx"
+
+type Sfx struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Sfx) Name() string             { return SfxComponentPrefix }
+func (c *Sfx) Description() string      { return "Play a soundboard clip" }
+func (c *Sfx) Group() string            { return "music" }
+func (c *Sfx) Category() string         { return "🎵 Music" }
+func (c *Sfx) UserPermissions() []int64 { return []int64{} }
+
+func (c *Sfx) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options:     []*discordgo.ApplicationCommandOption{clipOption(true)},
+	}
+}
+
+func (c *Sfx) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := discordreply.RespondDeferredEphemeral(s, e); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	var name string
+	for _, opt := range e.ApplicationCommandData().Options {
+		if opt.Name == "name" {
+			name = strings.TrimSpace(opt.StringValue())
+		}
+	}
+
+	embed := playClip(c.Bot, s, slashCtx.Storage, e, name)
+	if embed == nil {
+		embed = &discordgo.MessageEmbed{
+			Title:       "🔊 Soundboard",
+			Description: fmt.Sprintf("Playing **%s**.", name),
+			Color:       discordreply.EmbedColor,
+		}
+	}
+	if err := discordreply.FollowupEmbedEphemeral(s, e, embed); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "sfx").Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+// Autocomplete suggests clips whose name contains what the member has typed so far.
+func (c *Sfx) Autocomplete(ctx *command.AutocompleteInteractionContext) error {
+	return respondClipChoices(ctx, ctx.Event.ApplicationCommandData().Options)
+}
+
+// Component handles the soundboard buttons ("sfx:<clip name>"). A played clip leaves the board as it is;
+// problems are answered privately.
+func (c *Sfx) Component(ctx *command.ComponentInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+	name := strings.TrimPrefix(e.MessageComponentData().CustomID, SfxComponentPrefix+":")
+
+	// Decoding a clip and joining voice can outlast Discord's 3s deadline, so acknowledge first.
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		return fmt.Errorf("sfx: failed to acknowledge button: %w", err)
+	}
+	if embed := playClip(c.Bot, s, ctx.Storage, e, name); embed != nil {
+		return discordreply.FollowupEmbedEphemeral(s, e, embed)
+	}
+	return nil
+}
+
+// playClip plays the named clip in the member's voice channel. It returns an error embed, or nil once
+// the clip is playing.
+func playClip(bot discord.VoiceAPI, s *discordgo.Session, store *storage.Storage, e *discordgo.InteractionCreate, name string) *discordgo.MessageEmbed {
+	if store == nil {
+		return errorEmbed("Soundboard storage is not available.")
+	}
+	if e.Member == nil || e.Member.User == nil {
+		return errorEmbed("The soundboard only works in a server.")
+	}
+	clip, err := store.SoundboardClip(e.GuildID, name)
+	if err != nil {
+		return clipErrorEmbed(err, name)
+	}
+
+	voiceState, err := bot.FindUserVoiceState(e.GuildID, e.Member.User.ID)
+	if err != nil {
+		return &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: fmt.Sprintf("%v", err),
+		}
+	}
+	permOK, err := perm.CheckBotVoicePermissions(s, voiceState.ChannelID)
+	if err != nil || !permOK {
+		return &discordgo.MessageEmbed{
+			Title:       "🎵 Voice Error",
+			Description: "I don't have permission to join or speak in that voice channel.",
+		}
+	}
+
+	if err := bot.PlaySoundboardClip(e.GuildID, voiceState.ChannelID, clip.File); err != nil {
+		if errors.Is(err, voice.ErrSoundboardOtherChannel) {
+			return errorEmbed("Join the voice channel I'm in to play clips.")
+		}
+		return errorEmbed(fmt.Sprintf("Could not play **%s**.\n\n**Error:** %v", clip.Name, err))
+	}
+	return nil
+}
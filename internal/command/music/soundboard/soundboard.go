@@ -0,0 +1,342 @@
+package soundboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/media"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/discord/voice"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+type Soundboard struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Soundboard) Name() string             { return "soundboard" }
+func (c *Soundboard) Description() string      { return "Manage and show the server's soundboard clips" }
+func (c *Soundboard) Group() string            { return "music" }
+func (c *Soundboard) Category() string         { return "🎵 Music" }
+func (c *Soundboard) UserPermissions() []int64 { return []int64{} }
+
+// soundboardClipMaxBytes bounds uploaded clip files; clips are short sounds, not tracks.
+const soundboardClipMaxBytes = 2 << 20
+
+// Discord allows 5 rows of 5 buttons per message, and 25 autocomplete choices.
+const (
+	boardButtonsPerRow = 5
+	boardMaxButtons    = 25
+)
+
+func clipOption(autocomplete bool) *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "name",
+		Description:  "Clip name",
+		Required:     true,
+		MaxLength:    storage.SoundboardClipNameMaxRunes,
+		Autocomplete: autocomplete,
+	}
+}
+
+func (c *Soundboard) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "board",
+				Description: "Post the clips as a grid of buttons",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List this server's clips",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "add",
+				Description: "Upload a short audio clip (admins)",
+				Options: []*discordgo.ApplicationCommandOption{
+					clipOption(false),
+					{
+						Type:        discordgo.ApplicationCommandOptionAttachment,
+						Name:        "file",
+						Description: fmt.Sprintf("Audio file, up to %d MB (only the first %d seconds play)", soundboardClipMaxBytes>>20, int(voice.SoundboardClipMaxDuration.Seconds())),
+						Required:    true,
+					},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "remove",
+				Description: "Delete a clip (admins)",
+				Options:     []*discordgo.ApplicationCommandOption{clipOption(true)},
+			},
+		},
+	}
+}
+
+func (c *Soundboard) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	data := e.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("No subcommand provided."))
+		return nil
+	}
+	if slashCtx.Storage == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Soundboard storage is not available."))
+		return nil
+	}
+
+	sub := data.Options[0]
+	if (sub.Name == "add" || sub.Name == "remove") && !canManageClips(e.Member) {
+		discordreply.FollowupEmbedEphemeral(s, e, errorEmbed("Only server admins can add or remove clips."))
+		return nil
+	}
+
+	var embed *discordgo.MessageEmbed
+	switch sub.Name {
+	case "board":
+		// board answers with its own message carrying the buttons.
+		c.runBoard(slashCtx)
+		return nil
+	case "list":
+		embed = c.runList(slashCtx)
+	case "add":
+		embed = c.runAdd(slashCtx, sub)
+	case "remove":
+		embed = c.runRemove(slashCtx, sub)
+	default:
+		embed = errorEmbed(fmt.Sprintf("Unknown subcommand: %s", sub.Name))
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, embed); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "soundboard").Str("sub", sub.Name).Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+// Autocomplete suggests clips for the remove subcommand.
+func (c *Soundboard) Autocomplete(ctx *command.AutocompleteInteractionContext) error {
+	data := ctx.Event.ApplicationCommandData()
+	if len(data.Options) == 0 {
+		return respondClipChoices(ctx, nil)
+	}
+	return respondClipChoices(ctx, data.Options[0].Options)
+}
+
+// respondClipChoices answers an autocomplete request with the clips whose name contains the focused
+// option's text.
+func respondClipChoices(ctx *command.AutocompleteInteractionContext, opts []*discordgo.ApplicationCommandInteractionDataOption) error {
+	s, e := ctx.Session, ctx.Event
+
+	var typed string
+	for _, opt := range opts {
+		if opt.Focused {
+			typed = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if ctx.Storage != nil {
+		clips, err := ctx.Storage.ListSoundboardClips(e.GuildID)
+		if err != nil {
+			return err
+		}
+		for _, clip := range clips {
+			if len(choices) == boardMaxButtons {
+				break
+			}
+			if typed == "" || strings.Contains(strings.ToLower(clip.Name), typed) {
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: clip.Name, Value: clip.Name})
+			}
+		}
+	}
+
+	return s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// canManageClips reports whether member may add or remove clips: administrators and server managers.
+func canManageClips(member *discordgo.Member) bool {
+	return member != nil && member.Permissions&(discordgo.PermissionAdministrator|discordgo.PermissionManageGuild) != 0
+}
+
+func stringOption(sub *discordgo.ApplicationCommandInteractionDataOption, name string) string {
+	for _, opt := range sub.Options {
+		if opt.Name == name {
+			return strings.TrimSpace(opt.StringValue())
+		}
+	}
+	return ""
+}
+
+func errorEmbed(desc string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "🔊 Soundboard Error",
+		Description: desc,
+	}
+}
+
+func successEmbed(desc string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Title:       "🔊 Soundboard",
+		Description: desc,
+		Color:       discordreply.EmbedColor,
+	}
+}
+
+func clipErrorEmbed(err error, name string) *discordgo.MessageEmbed {
+	switch {
+	case errors.Is(err, storage.ErrSoundboardClipNotFound):
+		return errorEmbed(fmt.Sprintf("No clip named **%s**. See `/soundboard list`.", name))
+	case errors.Is(err, storage.ErrSoundboardClipExists),
+		errors.Is(err, storage.ErrSoundboardClipName),
+		errors.Is(err, storage.ErrSoundboardClipLimit):
+		msg := err.Error()
+		return errorEmbed(strings.ToUpper(msg[:1]) + msg[1:] + ".")
+	default:
+		return errorEmbed(fmt.Sprintf("**Error:** %v", err))
+	}
+}
+
+func (c *Soundboard) runList(ctx *command.SlashInteractionContext) *discordgo.MessageEmbed {
+	clips, err := ctx.Storage.ListSoundboardClips(ctx.Event.GuildID)
+	if err != nil {
+		return clipErrorEmbed(err, "")
+	}
+	if len(clips) == 0 {
+		return successEmbed("No clips yet. An admin can upload one with `/soundboard add`.")
+	}
+	names := make([]string, 0, len(clips))
+	for _, clip := range clips {
+		names = append(names, fmt.Sprintf("`%s`", clip.Name))
+	}
+	embed := successEmbed(strings.Join(names, " · "))
+	embed.Title = "🔊 Soundboard clips"
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: "Play one with /sfx <name> or /soundboard board."}
+	return embed
+}
+
+func (c *Soundboard) runBoard(ctx *command.SlashInteractionContext) {
+	s, e := ctx.Session, ctx.Event
+	clips, err := ctx.Storage.ListSoundboardClips(e.GuildID)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, clipErrorEmbed(err, ""))
+		return
+	}
+	if len(clips) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, successEmbed("No clips yet. An admin can upload one with `/soundboard add`."))
+		return
+	}
+
+	embed := successEmbed("Press a button to play the clip in your voice channel.")
+	if len(clips) > boardMaxButtons {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Showing %d of %d clips; play the others with /sfx.", boardMaxButtons, len(clips)),
+		}
+		clips = clips[:boardMaxButtons]
+	}
+
+	var rows []discordgo.MessageComponent
+	for i := 0; i < len(clips); i += boardButtonsPerRow {
+		var buttons []discordgo.MessageComponent
+		for _, clip := range clips[i:min(i+boardButtonsPerRow, len(clips))] {
+			buttons = append(buttons, discordgo.Button{
+				Label:    clip.Name,
+				Style:    discordgo.SecondaryButton,
+				CustomID: SfxComponentPrefix + ":" + clip.Name,
+			})
+		}
+		rows = append(rows, discordgo.ActionsRow{Components: buttons})
+	}
+
+	if _, err := s.FollowupMessageCreate(e.Interaction, true, &discordgo.WebhookParams{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: rows,
+	}); err != nil {
+		ctx.AppLog.Warn().Str("command", "soundboard").Str("sub", "board").Err(err).Msg("followup_embed_failed")
+	}
+}
+
+func (c *Soundboard) runAdd(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	e := ctx.Event
+	name := stringOption(sub, "name")
+
+	dir := c.Bot.SoundboardDir(e.GuildID)
+	if dir == "" {
+		return errorEmbed("The soundboard is disabled on this bot.")
+	}
+
+	var att *discordgo.MessageAttachment
+	for _, opt := range sub.Options {
+		if opt.Name == "file" {
+			if resolved := e.ApplicationCommandData().Resolved; resolved != nil {
+				att = resolved.Attachments[fmt.Sprint(opt.Value)]
+			}
+		}
+	}
+	switch {
+	case att == nil:
+		return errorEmbed("Attach an audio file.")
+	case !local.IsAudioFile(att.Filename):
+		return errorEmbed("That file is not a supported audio file.")
+	case att.Size > soundboardClipMaxBytes:
+		return errorEmbed(fmt.Sprintf("Clips can be at most %d MB.", soundboardClipMaxBytes>>20))
+	}
+	if _, err := ctx.Storage.SoundboardClip(e.GuildID, name); err == nil {
+		return clipErrorEmbed(storage.ErrSoundboardClipExists, name)
+	}
+
+	// Attachment IDs are unique, so clip files never collide whatever their names.
+	file := att.ID + strings.ToLower(filepath.Ext(att.Filename))
+	if err := media.SaveAttachment(att, dir, file); err != nil {
+		return errorEmbed(fmt.Sprintf("Could not save the clip.\n\n**Error:** %v", err))
+	}
+	if err := ctx.Storage.AddSoundboardClip(e.GuildID, name, file, e.Member.User.ID, time.Now()); err != nil {
+		_ = os.Remove(filepath.Join(dir, file))
+		return clipErrorEmbed(err, name)
+	}
+	return successEmbed(fmt.Sprintf("Added clip **%s**. Play it with `/sfx`.", name))
+}
+
+func (c *Soundboard) runRemove(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) *discordgo.MessageEmbed {
+	e := ctx.Event
+	name := stringOption(sub, "name")
+	clip, err := ctx.Storage.RemoveSoundboardClip(e.GuildID, name)
+	if err != nil {
+		return clipErrorEmbed(err, name)
+	}
+	if dir := c.Bot.SoundboardDir(e.GuildID); dir != "" {
+		if err := os.Remove(filepath.Join(dir, clip.File)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			ctx.AppLog.Warn().Str("command", "soundboard").Str("file", clip.File).Err(err).Msg("soundboard_file_remove_failed")
+		}
+	}
+	return successEmbed(fmt.Sprintf("Removed clip **%s**.", clip.Name))
+}
@@ -0,0 +1,266 @@
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+type MusicStats struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *MusicStats) Name() string { return "music-stats" }
+func (c *MusicStats) Description() string {
+	return "Show top tracks, top requesters and listening activity"
+}
+func (c *MusicStats) Group() string            { return "music" }
+func (c *MusicStats) Category() string         { return "🎵 Music" }
+func (c *MusicStats) UserPermissions() []int64 { return []int64{} }
+
+const (
+	statsTopN = 5
+	// statsMaxBuckets bounds the activity lines shown in the embed; the CSV export has all of them.
+	statsMaxBuckets = 14
+)
+
+func (c *MusicStats) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "period",
+				Description: "Time range (default last 30 days)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Last 7 days", Value: "7d"},
+					{Name: "Last 30 days", Value: "30d"},
+					{Name: "This month", Value: "this-month"},
+					{Name: "Last month", Value: "last-month"},
+					{Name: "All time", Value: "all"},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "group",
+				Description: "Activity per day or per week (default depends on the period)",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Per day", Value: string(domain.BucketDay)},
+					{Name: "Per week", Value: string(domain.BucketWeek)},
+				},
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "export",
+				Description: "Attach the full list as CSV",
+				Required:    false,
+				Choices: []*discordgo.ApplicationCommandOptionChoice{
+					{Name: "Top tracks", Value: "tracks"},
+					{Name: "Top requesters", Value: "requesters"},
+					{Name: "Activity", Value: "activity"},
+				},
+			},
+		},
+	}
+}
+
+// statsPeriod resolves a period choice to [from, to) in UTC; zero bounds are open.
+func statsPeriod(choice string, now time.Time) (from, to time.Time, label string) {
+	now = now.UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	switch choice {
+	case "7d":
+		return now.AddDate(0, 0, -7), time.Time{}, "last 7 days"
+	case "this-month":
+		return monthStart, time.Time{}, monthStart.Format("January 2006")
+	case "last-month":
+		prev := monthStart.AddDate(0, -1, 0)
+		return prev, monthStart, prev.Format("January 2006")
+	case "all":
+		return time.Time{}, time.Time{}, "all time"
+	default:
+		return now.AddDate(0, 0, -30), time.Time{}, "last 30 days"
+	}
+}
+
+func (c *MusicStats) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+	store := slashCtx.Storage
+
+	period := "30d"
+	var group, export string
+	for _, opt := range e.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "period":
+			period = strings.TrimSpace(opt.StringValue())
+		case "group":
+			group = strings.TrimSpace(opt.StringValue())
+		case "export":
+			export = strings.TrimSpace(opt.StringValue())
+		}
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to send deferred response: %w", err)
+	}
+
+	if store == nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Error",
+			Description: "Music history storage is not available.",
+		})
+		return nil
+	}
+
+	history, err := store.ListMusicPlaybackTimeline(e.GuildID)
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Music stats",
+			Description: fmt.Sprintf("Could not load history: %v", err),
+		})
+		return nil
+	}
+
+	from, to, label := statsPeriod(period, time.Now())
+	rows := domain.PlaybackBetween(history, from, to)
+	if len(rows) == 0 {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Music stats",
+			Description: fmt.Sprintf("No tracks played in %s.", label),
+			Color:       discordreply.EmbedColor,
+		})
+		return nil
+	}
+
+	size := domain.PlaybackBucketSize(group)
+	if size != domain.BucketDay && size != domain.BucketWeek {
+		size = domain.BucketDay
+		if period == "all" {
+			size = domain.BucketWeek
+		}
+	}
+
+	tracks := domain.AggregatePlaybackCounts(rows)
+	requesters := domain.AggregateRequesters(rows)
+	buckets := domain.BucketPlayback(rows, size)
+
+	embed := &discordgo.MessageEmbed{
+		Title: "🎵 Music stats — " + label,
+		Description: fmt.Sprintf("**%d** plays · **%d** tracks · **%s** listened",
+			len(rows), len(tracks), common.FormatListenHours(domain.TotalListened(rows))),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Top tracks", Value: topTracksValue(tracks)},
+			{Name: "Top requesters", Value: topRequestersValue(requesters)},
+			{Name: activityTitle(size, len(buckets)), Value: activityValue(buckets, size)},
+		},
+		Footer: &discordgo.MessageEmbedFooter{Text: "Times are UTC; replay a track with `/play <id>`."},
+		Color:  discordreply.EmbedColor,
+	}
+
+	if export == "" {
+		if err := discordreply.FollowupEmbed(s, e, embed); err != nil {
+			slashCtx.AppLog.Warn().Str("command", "music-stats").Err(err).Msg("followup_embed_failed")
+		}
+		return nil
+	}
+
+	var data []byte
+	switch export {
+	case "requesters":
+		data, err = common.StatsRequestersCSV(requesters)
+	case "activity":
+		data, err = common.StatsActivityCSV(buckets)
+	default:
+		export = "tracks"
+		data, err = common.StatsTracksCSV(tracks)
+	}
+	if err != nil {
+		discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Title:       "🎵 Music stats",
+			Description: fmt.Sprintf("Could not build the CSV export: %v", err),
+		})
+		return nil
+	}
+
+	if _, err := s.FollowupMessageCreate(e.Interaction, true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Files: []*discordgo.File{{
+			Name:        fmt.Sprintf("music-stats-%s-%s.csv", export, period),
+			ContentType: "text/csv",
+			Reader:      bytes.NewReader(data),
+		}},
+	}); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "music-stats").Err(err).Msg("followup_file_failed")
+	}
+	return nil
+}
+
+func topTracksValue(tracks []domain.PlaybackCountRow) string {
+	var lines []string
+	for i, r := range tracks {
+		if i == statsTopN {
+			break
+		}
+		lines = append(lines, common.FormatCountsLine(r))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func topRequestersValue(requesters []domain.PlaybackRequesterRow) string {
+	if len(requesters) == 0 {
+		return "No requesters recorded in this period."
+	}
+	var lines []string
+	for i, r := range requesters {
+		if i == statsTopN {
+			break
+		}
+		lines = append(lines, common.FormatRequesterLine(i+1, r))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func activityTitle(size domain.PlaybackBucketSize, n int) string {
+	title := "Plays per day"
+	if size == domain.BucketWeek {
+		title = "Plays per week"
+	}
+	if n > statsMaxBuckets {
+		title += fmt.Sprintf(" (last %d)", statsMaxBuckets)
+	}
+	return title
+}
+
+func activityValue(buckets []domain.PlaybackBucket, size domain.PlaybackBucketSize) string {
+	if len(buckets) > statsMaxBuckets {
+		buckets = buckets[len(buckets)-statsMaxBuckets:]
+	}
+	maxPlays := 0
+	for _, b := range buckets {
+		maxPlays = max(maxPlays, b.Plays)
+	}
+	lines := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		lines = append(lines, common.FormatBucketLine(b, size, maxPlays))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/command/music/common"
 	"github.com/keshon/server-domme/internal/discord"
 	"github.com/keshon/server-domme/internal/discord/discordreply"
 )
@@ -42,6 +43,20 @@ func (c *Stop) Run(ctx interface{}) error {
 	}
 
 	player := c.Bot.GetOrCreatePlayer(e.GuildID)
+	// Without the DJ role, /stop only votes to skip the current track.
+	if player.CurrentTrack() != nil && !common.IsDJ(slashCtx.Storage, e.GuildID, e.Member, player) {
+		msg, counted, passed := common.CastSkipVote(c.Bot, e.GuildID, e.Member, player)
+		if !passed {
+			common.ReplySkipVote(s, e, c.Bot, slashCtx.AppLog, msg, counted)
+			return nil
+		}
+		if err := common.SkipOrStop(player); err != nil {
+			slashCtx.AppLog.Warn().Err(err).Msg("player_skip_failed")
+		}
+		common.AnnouncePlayback(s, e, c.Bot, slashCtx.AppLog, msg)
+		return nil
+	}
+
 	if err := player.Stop(true); err != nil {
 		slashCtx.AppLog.Warn().Err(err).Msg("player_stop_failed")
 	}
@@ -0,0 +1,123 @@
+package volume
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+type Volume struct {
+	Bot discord.VoiceAPI
+}
+
+func (c *Volume) Name() string             { return "volume" }
+func (c *Volume) Description() string      { return "Set playback volume and loudness normalisation" }
+func (c *Volume) Group() string            { return "music" }
+func (c *Volume) Category() string         { return "🎵 Music" }
+func (c *Volume) UserPermissions() []int64 { return []int64{} }
+
+// discordgo requires a pointer for MinValue on slash options.
+var volumeMinValue = 0.0
+
+func (c *Volume) SlashDefinition() *discordgo.ApplicationCommand {
+	return &discordgo.ApplicationCommand{
+		Name:        c.Name(),
+		Description: c.Description(),
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionInteger,
+				Name:        "level",
+				Description: fmt.Sprintf("Volume in percent (0-%d, default %d)", domain.MaxMusicVolume, domain.DefaultMusicVolume),
+				Required:    false,
+				MinValue:    &volumeMinValue,
+				MaxValue:    domain.MaxMusicVolume,
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionBoolean,
+				Name:        "normalize",
+				Description: "Even out loudness between tracks",
+				Required:    false,
+			},
+		},
+	}
+}
+
+func (c *Volume) Run(ctx interface{}) error {
+	slashCtx, ok := ctx.(*command.SlashInteractionContext)
+	if !ok {
+		return nil
+	}
+
+	s := slashCtx.Session
+	e := slashCtx.Event
+	guildID := e.GuildID
+
+	var level *int64
+	var normalize *bool
+	for _, opt := range e.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "level":
+			v := opt.IntValue()
+			level = &v
+		case "normalize":
+			v := opt.BoolValue()
+			normalize = &v
+		}
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		return fmt.Errorf("failed to defer response: %w", err)
+	}
+
+	var changes []string
+	if level != nil {
+		applied, err := c.Bot.SetVolume(guildID, int(*level))
+		if err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Failed to set volume.\n\n**Error:** %v", err),
+			})
+			return nil
+		}
+		changes = append(changes, fmt.Sprintf("Volume set to **%d%%**.", applied))
+	}
+	if normalize != nil {
+		if err := c.Bot.SetNormalize(guildID, *normalize); err != nil {
+			discordreply.FollowupEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+				Title:       "🎵 Error",
+				Description: fmt.Sprintf("Failed to set normalisation.\n\n**Error:** %v", err),
+			})
+			return nil
+		}
+		changes = append(changes, fmt.Sprintf("Loudness normalisation **%s**.", onOff(*normalize)))
+	}
+
+	desc := strings.Join(changes, "\n")
+	if desc == "" {
+		desc = fmt.Sprintf("Volume: **%d%%**\nLoudness normalisation: **%s**",
+			c.Bot.Volume(guildID), onOff(c.Bot.Normalize(guildID)))
+	}
+
+	if err := discordreply.FollowupEmbed(s, e, &discordgo.MessageEmbed{
+		Title:       "🔊 Volume",
+		Description: desc,
+		Color:       discordreply.EmbedColor,
+	}); err != nil {
+		slashCtx.AppLog.Warn().Str("command", "volume").Err(err).Msg("followup_embed_failed")
+	}
+	return nil
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
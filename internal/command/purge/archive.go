@@ -0,0 +1,241 @@
+package purge
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	st "github.com/keshon/server-domme/internal/domain"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+// archiveUploadMaxBytes is the largest transcript posted as an attachment; bigger ones are only named.
+const archiveUploadMaxBytes = 10 << 20
+
+// Archive says where a purge saves what it deletes; the zero value saves nothing.
+type Archive struct {
+	Format    string // "html" or "jsonl"; "" disables archiving
+	Dir       string // transcripts go to <Dir>/<guildID>/<channelID>/
+	ChannelID string // where the transcript is posted when the run finishes; "" posts nothing
+	Rule      string // the rule's name, part of the file name so rules on one channel never share a file
+}
+
+// NewArchive returns the archive settings of a run of job, posting to the guild's purge log channel if
+// one is set.
+func NewArchive(store *storage.Storage, dir string, job st.PurgeJob) Archive {
+	if job.Archive == "" {
+		return Archive{}
+	}
+	logChannel, err := store.GetPurgeLogChannel(job.GuildID)
+	if err != nil {
+		log.Printf("[WARN] Failed to read purge log channel of guild %s: %v", job.GuildID, err)
+	}
+	return Archive{Format: job.Archive, Dir: dir, ChannelID: logChannel, Rule: job.Name}
+}
+
+// archivedMessage is one line of a JSONL transcript.
+type archivedMessage struct {
+	ID          string                    `json:"id"`
+	AuthorID    string                    `json:"author_id,omitempty"`
+	Author      string                    `json:"author,omitempty"`
+	Timestamp   time.Time                 `json:"timestamp"`
+	Content     string                    `json:"content,omitempty"`
+	Attachments []string                  `json:"attachments,omitempty"`
+	Embeds      []*discordgo.MessageEmbed `json:"embeds,omitempty"`
+}
+
+func archived(msg *discordgo.Message) archivedMessage {
+	a := archivedMessage{ID: msg.ID, Timestamp: msg.Timestamp, Content: msg.Content, Embeds: msg.Embeds}
+	if msg.Author != nil {
+		a.AuthorID = msg.Author.ID
+		a.Author = msg.Author.Username
+	}
+	for _, att := range msg.Attachments {
+		a.Attachments = append(a.Attachments, att.URL)
+	}
+	return a
+}
+
+// transcript writes the messages of one purge run to a file, created on the first write so runs that
+// delete nothing leave nothing behind.
+type transcript struct {
+	format string
+	path   string
+	f      *os.File
+	w      *bufio.Writer
+	count  int
+}
+
+// newTranscript names the file <Dir>/<guildID>/<channelID>/<started>-<rule>.<format>.
+func newTranscript(a Archive, guildID, channelID string, started time.Time) *transcript {
+	name := started.Format("20060102-150405")
+	if a.Rule != "" {
+		name += "-" + fileSafe(a.Rule)
+	}
+	return &transcript{format: a.Format, path: filepath.Join(a.Dir, guildID, channelID, name+"."+a.Format)}
+}
+
+// fileSafe replaces everything but letters, digits, '-' and '_' in a rule name.
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// create opens a new file at t.path, never an existing one: runs starting in the same second get
+// "-2", "-3"... before the extension instead of truncating each other's transcript.
+func (t *transcript) create() (*os.File, error) {
+	ext := filepath.Ext(t.path)
+	base := strings.TrimSuffix(t.path, ext)
+	for n := 1; ; n++ {
+		path := base + ext
+		if n > 1 {
+			path = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err == nil {
+			t.path = path
+		}
+		return f, err
+	}
+}
+
+// write appends msgs; the purge deletes them only once this succeeded.
+func (t *transcript) write(msgs []*discordgo.Message) error {
+	if t.f == nil {
+		if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+			return err
+		}
+		f, err := t.create()
+		if err != nil {
+			return err
+		}
+		t.f, t.w = f, bufio.NewWriter(f)
+		if t.format == "html" {
+			fmt.Fprintf(t.w, htmlHeader, html.EscapeString(filepath.Base(t.path)))
+		}
+	}
+	for _, msg := range msgs {
+		if t.format == "html" {
+			writeHTMLMessage(t.w, msg)
+		} else {
+			b, err := json.Marshal(archived(msg))
+			if err != nil {
+				return err
+			}
+			t.w.Write(b)
+			t.w.WriteByte('\n')
+		}
+	}
+	t.count += len(msgs)
+	return t.w.Flush()
+}
+
+// close finishes the file; it reports whether anything was written.
+func (t *transcript) close() (bool, error) {
+	if t.f == nil {
+		return false, nil
+	}
+	if t.format == "html" {
+		t.w.WriteString(htmlFooter)
+	}
+	err := t.w.Flush()
+	if cerr := t.f.Close(); err == nil {
+		err = cerr
+	}
+	return true, err
+}
+
+const htmlHeader = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>%s</title>
+<style>
+body{font-family:sans-serif;background:#313338;color:#dbdee1;margin:2em}
+.msg{margin:0 0 1em}.meta{color:#949ba4;font-size:.85em}.author{color:#f2f3f5;font-weight:bold}
+.content{white-space:pre-wrap}.embed{border-left:4px solid #5865f2;background:#2b2d31;padding:.5em;margin:.3em 0;max-width:40em}
+a{color:#00a8fc}
+</style></head><body>
+`
+
+const htmlFooter = "</body></html>\n"
+
+func writeHTMLMessage(w *bufio.Writer, msg *discordgo.Message) {
+	a := archived(msg)
+	fmt.Fprintf(w, "<div class=\"msg\" id=\"m%s\"><span class=\"author\">%s</span> <span class=\"meta\">%s · %s</span>\n",
+		html.EscapeString(a.ID), html.EscapeString(a.Author), html.EscapeString(a.AuthorID), a.Timestamp.UTC().Format(time.RFC3339))
+	if a.Content != "" {
+		fmt.Fprintf(w, "<div class=\"content\">%s</div>\n", html.EscapeString(a.Content))
+	}
+	for _, url := range a.Attachments {
+		fmt.Fprintf(w, "<div><a href=\"%[1]s\">%[1]s</a></div>\n", html.EscapeString(url))
+	}
+	for _, e := range a.Embeds {
+		w.WriteString("<div class=\"embed\">")
+		if e.Title != "" {
+			fmt.Fprintf(w, "<b>%s</b><br>", html.EscapeString(e.Title))
+		}
+		if e.URL != "" {
+			fmt.Fprintf(w, "<a href=\"%[1]s\">%[1]s</a><br>", html.EscapeString(e.URL))
+		}
+		if e.Description != "" {
+			fmt.Fprintf(w, "<div class=\"content\">%s</div>", html.EscapeString(e.Description))
+		}
+		for _, f := range e.Fields {
+			fmt.Fprintf(w, "<div><b>%s</b>: %s</div>", html.EscapeString(f.Name), html.EscapeString(f.Value))
+		}
+		w.WriteString("</div>\n")
+	}
+	w.WriteString("</div>\n")
+}
+
+// postTranscript sends a finished transcript to the archive's log channel, attached when small enough.
+func postTranscript(s *discordgo.Session, a Archive, channelID string, t *transcript) {
+	if a.ChannelID == "" {
+		return
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "🧹 Purge Archive",
+		Description: fmt.Sprintf("Archived **%d** message(s) purged from <#%s>.", t.count, channelID),
+	}
+	msg := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}
+
+	var f *os.File
+	if info, err := os.Stat(t.path); err == nil && info.Size() <= archiveUploadMaxBytes {
+		f, err = os.Open(t.path)
+		if err == nil {
+			defer f.Close()
+			msg.Files = []*discordgo.File{{Name: filepath.Base(t.path), Reader: f}}
+		}
+	}
+	if f == nil {
+		embed.Description += "\nThe transcript is too large to upload; it is saved on the server at `" + strings.ReplaceAll(t.path, "`", "'") + "`."
+	}
+	if _, err := s.ChannelMessageSendComplex(a.ChannelID, msg); err != nil {
+		log.Printf("[WARN] Failed to post purge transcript %s: %v", t.path, err)
+	}
+}
+
+// archiveOption is the transcript option of /purge now and /purge auto.
+func archiveOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "archive",
+		Description: "Save purged messages to a transcript before deleting them",
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{Name: "HTML transcript", Value: "html"},
+			{Name: "JSONL transcript", Value: "jsonl"},
+		},
+	}
+}
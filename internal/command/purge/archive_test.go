@@ -0,0 +1,112 @@
+package purge
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestTranscriptArchivesBeforeDeleting(t *testing.T) {
+	now := time.Now()
+	f := &fakeChannel{msgs: history(now, 3)}
+	f.msgs[0].Author = &discordgo.User{ID: "u1", Username: "ann"}
+	f.msgs[0].Content = "<b>hi</b>"
+	f.msgs[0].Attachments = []*discordgo.MessageAttachment{{URL: "https://cdn.example/a.png"}}
+	f.msgs[0].Embeds = []*discordgo.MessageEmbed{{Title: "card"}}
+
+	dir := t.TempDir()
+	tr := newTranscript(Archive{Format: "jsonl", Dir: dir}, "g", "c", now)
+	if got := deleteMessages(f, "c", now, all, tr.write, nil); got != 3 {
+		t.Fatalf("deleted = %d", got)
+	}
+	if written, err := tr.close(); !written || err != nil {
+		t.Fatalf("close = %v, %v", written, err)
+	}
+	if filepath.Dir(tr.path) != filepath.Join(dir, "g", "c") {
+		t.Fatalf("path = %s", tr.path)
+	}
+
+	file, err := os.Open(tr.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var lines []archivedMessage
+	sc := bufio.NewScanner(file)
+	for sc.Scan() {
+		var a archivedMessage
+		if err := json.Unmarshal(sc.Bytes(), &a); err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, a)
+	}
+	if len(lines) != 3 || lines[0].Author != "ann" || lines[0].Content != "<b>hi</b>" ||
+		lines[0].Attachments[0] != "https://cdn.example/a.png" || lines[0].Embeds[0].Title != "card" {
+		t.Fatalf("transcript = %+v", lines)
+	}
+}
+
+func TestHTMLTranscriptEscapes(t *testing.T) {
+	tr := newTranscript(Archive{Format: "html", Dir: t.TempDir()}, "g", "c", time.Now())
+	msg := &discordgo.Message{ID: "1", Author: &discordgo.User{Username: "x"}, Content: "<script>alert(1)</script>"}
+	if err := tr.write([]*discordgo.Message{msg}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tr.close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(tr.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	if strings.Contains(page, "<script>") || !strings.Contains(page, "&lt;script&gt;") || !strings.HasSuffix(page, "</html>\n") {
+		t.Fatalf("page = %s", page)
+	}
+}
+
+func TestNothingDeletedWhenArchiveFails(t *testing.T) {
+	now := time.Now()
+	f := &fakeChannel{msgs: history(now, 3)}
+	fail := func([]*discordgo.Message) error { return errors.New("disk full") }
+	if got := deleteMessages(f, "c", now, all, fail, nil); got != 0 || len(f.bulk)+len(f.single) != 0 {
+		t.Fatalf("deleted %d messages without archiving them", got)
+	}
+
+	// A run that deletes nothing leaves no file.
+	tr := newTranscript(Archive{Format: "jsonl", Dir: t.TempDir()}, "g", "c", now)
+	if written, _ := tr.close(); written {
+		t.Fatal("empty run wrote a transcript")
+	}
+}
+
+func TestTranscriptsOfOneChannelDoNotCollide(t *testing.T) {
+	dir, now := t.TempDir(), time.Now()
+	msg := []*discordgo.Message{{ID: "1"}}
+	daily := newTranscript(Archive{Format: "jsonl", Dir: dir, Rule: "daily"}, "g", "c", now)
+	first := newTranscript(Archive{Format: "jsonl", Dir: dir, Rule: "a/b"}, "g", "c", now)
+	second := newTranscript(Archive{Format: "jsonl", Dir: dir, Rule: "a/b"}, "g", "c", now)
+	for _, tr := range []*transcript{daily, first, second} {
+		if err := tr.write(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !strings.HasSuffix(daily.path, "-daily.jsonl") || !strings.HasSuffix(first.path, "-a_b.jsonl") || !strings.HasSuffix(second.path, "-a_b-2.jsonl") {
+		t.Fatalf("paths = %s, %s, %s", daily.path, first.path, second.path)
+	}
+	for _, tr := range []*transcript{daily, first, second} {
+		if _, err := tr.close(); err != nil {
+			t.Fatal(err)
+		}
+		if b, err := os.ReadFile(tr.path); err != nil || strings.Count(string(b), "\n") != 1 {
+			t.Fatalf("%s = %q, %v", tr.path, b, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package purge
+
+import (
+	"log"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord only bulk-deletes messages younger than 14 days; the margin covers clock skew and the time a
+// batch waits for its turn.
+const bulkDeleteMaxAge = 14*24*time.Hour - 10*time.Minute
+
+// bulkDeleteMax is the most messages one bulk-delete request takes.
+const bulkDeleteMax = 100
+
+// messageLister is the part of *discordgo.Session a preview uses.
+type messageLister interface {
+	ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error)
+}
+
+// messageDeleter is the part of *discordgo.Session a purge uses.
+type messageDeleter interface {
+	messageLister
+	ChannelMessagesBulkDelete(channelID string, messages []string, options ...discordgo.RequestOption) error
+	ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error
+}
+
+// deleteMessages walks the channel from the newest message back and deletes those selected, batching
+// the ones younger than bulkDeleteMaxAge (relative to now). When save is set, messages are handed to it
+// before they are deleted, and the run stops if it fails. It returns how many messages it deleted.
+func deleteMessages(api messageDeleter, channelID string, now time.Time, selected func(*discordgo.Message) bool, save func([]*discordgo.Message) error, stopChan <-chan struct{}) int {
+	deleted := 0
+	failed := false
+	var batch []*discordgo.Message
+	remove := func(msgs []*discordgo.Message) {
+		if save != nil {
+			if err := save(msgs); err != nil {
+				log.Printf("[ERR] Stopping purge of channel %s: failed to archive messages: %v", channelID, err)
+				failed = true
+				return
+			}
+		}
+		if len(msgs) == 1 {
+			if api.ChannelMessageDelete(channelID, msgs[0].ID) == nil {
+				deleted++
+			}
+			return
+		}
+		ids := make([]string, len(msgs))
+		for i, msg := range msgs {
+			ids[i] = msg.ID
+		}
+		// A failed batch (a message vanished or aged out meanwhile) falls back to single deletes.
+		if err := api.ChannelMessagesBulkDelete(channelID, ids); err != nil {
+			log.Printf("[WARN] Bulk delete of %d messages in channel %s failed, deleting one by one: %v", len(ids), channelID, err)
+			for _, id := range ids {
+				if api.ChannelMessageDelete(channelID, id) == nil {
+					deleted++
+				}
+			}
+			return
+		}
+		deleted += len(ids)
+	}
+	flush := func() {
+		if len(batch) > 0 {
+			remove(batch)
+			batch = batch[:0]
+		}
+	}
+	eachMessage(api, channelID, stopChan, func(msg *discordgo.Message) bool {
+		if !selected(msg) {
+			return true
+		}
+		// Pages run newest first, so once a message is too old for bulk delete the rest are too.
+		if now.Sub(msg.Timestamp) < bulkDeleteMaxAge {
+			batch = append(batch, msg)
+			if len(batch) == bulkDeleteMax {
+				flush()
+			}
+			return !failed
+		}
+		flush()
+		if !failed {
+			remove([]*discordgo.Message{msg})
+		}
+		return !failed
+	})
+	if !isClosed(stopChan) && !failed {
+		flush()
+	}
+	return deleted
+}
+
+// eachMessage calls fn with the channel's messages from the newest back until fn returns false, the
+// history ends, a page fails to load or stopChan closes.
+func eachMessage(api messageLister, channelID string, stopChan <-chan struct{}, fn func(*discordgo.Message) bool) {
+	var lastID string
+	for !isClosed(stopChan) {
+		msgs, err := api.ChannelMessages(channelID, 100, lastID, "", "")
+		if err != nil || len(msgs) == 0 {
+			return
+		}
+		for _, msg := range msgs {
+			if isClosed(stopChan) || !fn(msg) {
+				return
+			}
+		}
+		lastID = msgs[len(msgs)-1].ID
+		if len(msgs) < 100 {
+			return
+		}
+	}
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
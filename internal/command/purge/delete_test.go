@@ -0,0 +1,96 @@
+package purge
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// fakeChannel serves a channel history newest first and records deletions.
+type fakeChannel struct {
+	msgs       []*discordgo.Message
+	bulk       [][]string
+	single     []string
+	failBulkAt int // 1-based bulk request to fail; 0 never
+}
+
+func (f *fakeChannel) ChannelMessages(channelID string, limit int, beforeID, afterID, aroundID string, options ...discordgo.RequestOption) ([]*discordgo.Message, error) {
+	start := 0
+	if beforeID != "" {
+		for i, m := range f.msgs {
+			if m.ID == beforeID {
+				start = i + 1
+			}
+		}
+	}
+	end := min(start+limit, len(f.msgs))
+	return f.msgs[start:end], nil
+}
+
+func (f *fakeChannel) ChannelMessagesBulkDelete(channelID string, messages []string, options ...discordgo.RequestOption) error {
+	f.bulk = append(f.bulk, append([]string(nil), messages...))
+	if len(f.bulk) == f.failBulkAt {
+		return errors.New("unknown message")
+	}
+	return nil
+}
+
+func (f *fakeChannel) ChannelMessageDelete(channelID, messageID string, options ...discordgo.RequestOption) error {
+	f.single = append(f.single, messageID)
+	return nil
+}
+
+// history returns n messages one hour apart, the newest an hour before now.
+func history(now time.Time, n int) []*discordgo.Message {
+	msgs := make([]*discordgo.Message, n)
+	for i := range msgs {
+		msgs[i] = &discordgo.Message{ID: strconv.Itoa(i), Timestamp: now.Add(-time.Duration(i+1) * time.Hour)}
+	}
+	return msgs
+}
+
+func all(*discordgo.Message) bool { return true }
+
+func TestDeleteMessagesBatchesRecentMessages(t *testing.T) {
+	now := time.Now()
+	// 14 days of hourly messages: 335 bulk-deletable, then the rest one by one.
+	f := &fakeChannel{msgs: history(now, 400)}
+	if got := deleteMessages(f, "c", now, all, nil, nil); got != 400 {
+		t.Fatalf("deleted = %d, want 400", got)
+	}
+	var sizes []int
+	for _, b := range f.bulk {
+		sizes = append(sizes, len(b))
+	}
+	if fmt.Sprint(sizes) != "[100 100 100 35]" {
+		t.Fatalf("bulk batches = %v", sizes)
+	}
+	if len(f.single) != 65 || f.single[0] != "335" {
+		t.Fatalf("single deletes = %d starting at %v", len(f.single), f.single[:1])
+	}
+}
+
+func TestDeleteMessagesFallsBackWhenBulkFails(t *testing.T) {
+	now := time.Now()
+	f := &fakeChannel{msgs: history(now, 5), failBulkAt: 1}
+	odd := func(m *discordgo.Message) bool { n, _ := strconv.Atoi(m.ID); return n%2 == 1 }
+	if got := deleteMessages(f, "c", now, odd, nil, nil); got != 2 {
+		t.Fatalf("deleted = %d, want 2", got)
+	}
+	if fmt.Sprint(f.bulk) != "[[1 3]]" || fmt.Sprint(f.single) != "[1 3]" {
+		t.Fatalf("bulk = %v, single = %v", f.bulk, f.single)
+	}
+}
+
+func TestDeleteMessagesStops(t *testing.T) {
+	stop := make(chan struct{})
+	close(stop)
+	f := &fakeChannel{msgs: history(time.Now(), 5)}
+	if got := deleteMessages(f, "c", time.Now(), all, nil, stop); got != 0 || len(f.bulk)+len(f.single) != 0 {
+		t.Fatalf("deleted %d after stop", got)
+	}
+}
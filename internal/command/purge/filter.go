@@ -0,0 +1,198 @@
+package purge
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	st "github.com/keshon/server-domme/internal/domain"
+)
+
+// maxPatternLen caps the content regexp; RE2 cannot backtrack, but a huge pattern is still a mistake.
+const maxPatternLen = 200
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://\S+`)
+
+// matcher decides which messages a purge filter selects.
+type matcher struct {
+	filter  st.PurgeFilter
+	content *regexp.Regexp
+	// memberRoles returns the role IDs of a guild member; only used with a role filter.
+	memberRoles func(userID string) ([]string, error)
+	roles       map[string][]string
+}
+
+// newMatcher compiles filter. memberRoles may be nil when the filter has no role.
+func newMatcher(filter st.PurgeFilter, memberRoles func(userID string) ([]string, error)) (*matcher, error) {
+	m := &matcher{filter: filter, memberRoles: memberRoles, roles: map[string][]string{}}
+	if filter.Pattern != "" {
+		if len(filter.Pattern) > maxPatternLen {
+			return nil, fmt.Errorf("content pattern is longer than %d characters", maxPatternLen)
+		}
+		re, err := regexp.Compile(filter.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content pattern: %w", err)
+		}
+		m.content = re
+	}
+	return m, nil
+}
+
+// sessionMemberRoles looks members up in the state cache first, then over the API.
+func sessionMemberRoles(s *discordgo.Session, guildID string) func(userID string) ([]string, error) {
+	return func(userID string) ([]string, error) {
+		if s.State != nil {
+			if m, err := s.State.Member(guildID, userID); err == nil {
+				return m.Roles, nil
+			}
+		}
+		m, err := s.GuildMember(guildID, userID)
+		if err != nil {
+			return nil, err
+		}
+		return m.Roles, nil
+	}
+}
+
+// match reports whether msg is selected. Authors whose roles cannot be read (usually members who left)
+// do not match a role filter.
+func (m *matcher) match(msg *discordgo.Message) bool {
+	f := m.filter
+	if f.SkipPinned && msg.Pinned {
+		return false
+	}
+	if f.UserID != "" && (msg.Author == nil || msg.Author.ID != f.UserID) {
+		return false
+	}
+	if f.BotsOnly && msg.WebhookID == "" && (msg.Author == nil || !msg.Author.Bot) {
+		return false
+	}
+	if f.Attachments || f.Links {
+		hasAttachments := len(msg.Attachments) > 0
+		hasLinks := linkPattern.MatchString(msg.Content)
+		if !(f.Attachments && hasAttachments) && !(f.Links && hasLinks) {
+			return false
+		}
+	}
+	if m.content != nil && !m.content.MatchString(msg.Content) {
+		return false
+	}
+	if f.RoleID != "" {
+		if msg.Author == nil || !slices.Contains(m.authorRoles(msg), f.RoleID) {
+			return false
+		}
+	}
+	return true
+}
+
+// authorRoles returns the roles of msg's author, asking memberRoles once per author.
+func (m *matcher) authorRoles(msg *discordgo.Message) []string {
+	if msg.Member != nil && msg.Member.Roles != nil {
+		return msg.Member.Roles
+	}
+	id := msg.Author.ID
+	if roles, ok := m.roles[id]; ok {
+		return roles
+	}
+	var roles []string
+	if m.memberRoles != nil {
+		roles, _ = m.memberRoles(id)
+	}
+	m.roles[id] = roles
+	return roles
+}
+
+// describeFilter renders filter for job lists and notices; "" for the zero filter.
+func describeFilter(f st.PurgeFilter) string {
+	var parts []string
+	if f.UserID != "" {
+		parts = append(parts, "from <@"+f.UserID+">")
+	}
+	if f.RoleID != "" {
+		parts = append(parts, "from <@&"+f.RoleID+">")
+	}
+	if f.BotsOnly {
+		parts = append(parts, "from bots")
+	}
+	switch {
+	case f.Attachments && f.Links:
+		parts = append(parts, "with attachments or links")
+	case f.Attachments:
+		parts = append(parts, "with attachments")
+	case f.Links:
+		parts = append(parts, "with links")
+	}
+	if f.Pattern != "" {
+		parts = append(parts, "matching `"+strings.ReplaceAll(f.Pattern, "`", "'")+"`")
+	}
+	if f.SkipPinned {
+		parts = append(parts, "except pinned")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// filterOptions are the filter options shared by /purge now and /purge auto.
+func filterOptions() []*discordgo.ApplicationCommandOption {
+	return []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Name:        "from_user",
+			Description: "Only messages by this user",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionRole,
+			Name:        "from_role",
+			Description: "Only messages by members with this role",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "bots_only",
+			Description: "Only messages by bots and webhooks",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "with_attachments",
+			Description: "Only messages with attachments (with links too: either)",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "with_links",
+			Description: "Only messages with links (with attachments too: either)",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "content_regex",
+			Description: "Only messages whose text matches this regular expression",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Name:        "skip_pinned",
+			Description: "Leave pinned messages alone",
+		},
+	}
+}
+
+// parseFilterOption sets the filter field of opt, reporting whether opt was a filter option.
+func parseFilterOption(f *st.PurgeFilter, opt *discordgo.ApplicationCommandInteractionDataOption) bool {
+	switch opt.Name {
+	case "from_user":
+		f.UserID, _ = opt.Value.(string)
+	case "from_role":
+		f.RoleID, _ = opt.Value.(string)
+	case "bots_only":
+		f.BotsOnly = opt.BoolValue()
+	case "with_attachments":
+		f.Attachments = opt.BoolValue()
+	case "with_links":
+		f.Links = opt.BoolValue()
+	case "content_regex":
+		f.Pattern = opt.StringValue()
+	case "skip_pinned":
+		f.SkipPinned = opt.BoolValue()
+	default:
+		return false
+	}
+	return true
+}
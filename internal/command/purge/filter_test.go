@@ -0,0 +1,75 @@
+package purge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	st "github.com/keshon/server-domme/internal/domain"
+)
+
+func TestMatcher(t *testing.T) {
+	human := &discordgo.User{ID: "u1"}
+	bot := &discordgo.User{ID: "b1", Bot: true}
+	msgs := map[string]*discordgo.Message{
+		"plain":    {Author: human, Content: "hello"},
+		"link":     {Author: human, Content: "see https://example.com/x"},
+		"file":     {Author: human, Attachments: []*discordgo.MessageAttachment{{ID: "a"}}},
+		"pinned":   {Author: human, Content: "rules", Pinned: true},
+		"bot":      {Author: bot, Content: "beep"},
+		"webhook":  {Author: &discordgo.User{ID: "w1"}, WebhookID: "w1"},
+		"stranger": {Author: &discordgo.User{ID: "gone"}, Content: "hello"},
+	}
+	roles := func(userID string) ([]string, error) {
+		switch userID {
+		case "u1":
+			return []string{"r1"}, nil
+		case "b1":
+			return nil, nil
+		}
+		return nil, errors.New("unknown member")
+	}
+
+	cases := []struct {
+		name   string
+		filter st.PurgeFilter
+		want   []string
+	}{
+		{"zero", st.PurgeFilter{}, []string{"plain", "link", "file", "pinned", "bot", "webhook", "stranger"}},
+		{"user", st.PurgeFilter{UserID: "u1"}, []string{"plain", "link", "file", "pinned"}},
+		{"role", st.PurgeFilter{RoleID: "r1", SkipPinned: true}, []string{"plain", "link", "file"}},
+		{"bots", st.PurgeFilter{BotsOnly: true}, []string{"bot", "webhook"}},
+		{"attachments or links", st.PurgeFilter{Attachments: true, Links: true}, []string{"link", "file"}},
+		{"links", st.PurgeFilter{Links: true}, []string{"link"}},
+		{"pattern", st.PurgeFilter{Pattern: `(?i)^HEL`}, []string{"plain", "stranger"}},
+	}
+	for _, tc := range cases {
+		m, err := newMatcher(tc.filter, roles)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		want := map[string]bool{}
+		for _, name := range tc.want {
+			want[name] = true
+		}
+		for name, msg := range msgs {
+			if got := m.match(msg); got != want[name] {
+				t.Errorf("%s: match(%s) = %v", tc.name, name, got)
+			}
+		}
+	}
+}
+
+func TestMatcherRejectsBadPattern(t *testing.T) {
+	if _, err := newMatcher(st.PurgeFilter{Pattern: "("}, nil); err == nil {
+		t.Fatal("invalid regexp accepted")
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	got, err := ParseDuration("1w2d3h")
+	if err != nil || got != 9*24*time.Hour+3*time.Hour {
+		t.Fatalf("ParseDuration = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,282 @@
+package purge
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	st "github.com/keshon/server-domme/internal/domain"
+)
+
+const (
+	// previewTTL is how long the Confirm and Cancel buttons of a preview work.
+	previewTTL = 5 * time.Minute
+	// previewScanMax caps how many messages a preview reads, so a huge channel still answers quickly.
+	previewScanMax = 3000
+	// previewSamples is how many matching messages a preview quotes.
+	previewSamples = 3
+)
+
+// purgeRequest is a /purge now or /purge auto waiting for its preview to be confirmed.
+type purgeRequest struct {
+	id                 string
+	userID             string
+	guildID, channelID string
+	categoryID         string // set when the rule covers a category instead of channelID
+	name               string
+	mode               string        // "delayed" or "recurring", as in domain.PurgeJob
+	dur                time.Duration // the delay for "delayed", the age for "recurring"
+	olderThan          string        // the age as typed, stored with recurring jobs
+	notifyAll          bool
+	filter             st.PurgeFilter
+	archive            string // transcript format, as in domain.PurgeJob
+}
+
+var (
+	pendingPurges   = make(map[string]*purgeRequest)
+	pendingPurgesMu sync.Mutex
+)
+
+// job is the rule req creates once confirmed.
+func (req *purgeRequest) job() st.PurgeJob {
+	job := st.PurgeJob{
+		GuildID:   req.guildID,
+		Name:      req.name,
+		Mode:      req.mode,
+		OlderThan: req.olderThan,
+		Silent:    req.notifyAll,
+		Filter:    req.filter,
+		Archive:   req.archive,
+	}
+	if req.categoryID != "" {
+		job.CategoryID = req.categoryID
+	} else {
+		job.ChannelID = req.channelID
+	}
+	return job
+}
+
+// takePending removes and returns the pending request id, or nil when it expired or was answered.
+func takePending(id string) *purgeRequest {
+	pendingPurgesMu.Lock()
+	defer pendingPurgesMu.Unlock()
+	req := pendingPurges[id]
+	delete(pendingPurges, id)
+	return req
+}
+
+// purgePreview is what a dry run found.
+type purgePreview struct {
+	matched        int
+	scanned        int
+	complete       bool // the whole history was read
+	newest, oldest *discordgo.Message
+	samples        []*discordgo.Message
+}
+
+// scanPreview counts the messages a purge of channelIDs would delete now, reading at most
+// previewScanMax messages across them.
+func scanPreview(api messageLister, channelIDs []string, selected func(*discordgo.Message) bool) purgePreview {
+	var p purgePreview
+	p.complete = true
+	for _, channelID := range channelIDs {
+		if !p.complete {
+			break
+		}
+		scanChannel(api, channelID, selected, &p)
+	}
+	return p
+}
+
+func scanChannel(api messageLister, channelID string, selected func(*discordgo.Message) bool, p *purgePreview) {
+	eachMessage(api, channelID, nil, func(msg *discordgo.Message) bool {
+		if p.scanned == previewScanMax {
+			p.complete = false
+			return false
+		}
+		p.scanned++
+		if !selected(msg) {
+			return true
+		}
+		p.matched++
+		if p.newest == nil {
+			p.newest = msg
+		}
+		p.oldest = msg
+		if len(p.samples) < previewSamples {
+			p.samples = append(p.samples, msg)
+		}
+		return true
+	})
+}
+
+// selector returns what req would delete if it ran now.
+func (req *purgeRequest) selector(s *discordgo.Session) func(*discordgo.Message) bool {
+	m, _ := newMatcher(req.filter, sessionMemberRoles(s, req.guildID))
+	cutoff := time.Now().Add(-req.dur)
+	return func(msg *discordgo.Message) bool {
+		if req.mode == "recurring" && msg.Timestamp.After(cutoff) {
+			return false
+		}
+		return m.match(msg)
+	}
+}
+
+// showPreview answers a validated request with a dry run and Confirm/Cancel buttons. Nothing is
+// deleted or scheduled until Confirm is pressed.
+func showPreview(ctx *command.SlashInteractionContext, req *purgeRequest) error {
+	s, e := ctx.Session, ctx.Event
+	if err := discordreply.RespondDeferredEphemeral(s, e); err != nil {
+		return fmt.Errorf("purge: failed to defer preview: %w", err)
+	}
+
+	preview := scanPreview(s, ruleChannels(s, req.job()), req.selector(s))
+	embed := previewEmbed(req, preview, ctx.Responder.EmbedColor())
+
+	pendingPurgesMu.Lock()
+	pendingPurges[req.id] = req
+	pendingPurgesMu.Unlock()
+
+	components := previewButtons(req.id)
+	if _, err := s.InteractionResponseEdit(e.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	}); err != nil {
+		takePending(req.id)
+		return fmt.Errorf("purge: failed to show preview: %w", err)
+	}
+
+	time.AfterFunc(previewTTL, func() {
+		if takePending(req.id) == nil {
+			return
+		}
+		_, _ = s.InteractionResponseEdit(e.Interaction, &discordgo.WebhookEdit{
+			Embeds:     &[]*discordgo.MessageEmbed{{Description: "This preview expired. Run the command again to purge."}},
+			Components: &[]discordgo.MessageComponent{},
+		})
+	})
+	return nil
+}
+
+func previewEmbed(req *purgeRequest, p purgePreview, color int) *discordgo.MessageEmbed {
+	var what string
+	switch req.mode {
+	case "recurring":
+		what = fmt.Sprintf("Rule **%s**, a recurring purge of messages older than **%s**%s", req.name, req.dur, filterSuffix(req.filter))
+		if req.categoryID != "" {
+			what += " in every channel of <#" + req.categoryID + ">"
+		}
+	default:
+		what = fmt.Sprintf("A purge of all messages%s in **%s**", filterSuffix(req.filter), req.dur)
+	}
+
+	count := fmt.Sprintf("%d", p.matched)
+	if !p.complete {
+		count = fmt.Sprintf("at least %d (of the latest %d read)", p.matched, p.scanned)
+	}
+	archived := ""
+	if req.archive != "" {
+		archived = fmt.Sprintf("\nEach run first saves what it deletes to a transcript (%s).", strings.ToUpper(req.archive))
+	}
+	embed := &discordgo.MessageEmbed{
+		Title:       "🧹 Purge Preview",
+		Description: fmt.Sprintf("%s would delete **%s** message(s) right now.%s\n\nConfirm within %s.", what, count, archived, previewTTL),
+		Color:       color,
+	}
+	if p.matched == 0 {
+		return embed
+	}
+	embed.Fields = []*discordgo.MessageEmbedField{
+		{Name: "Newest", Value: messageLine(req.guildID, p.newest), Inline: true},
+		{Name: "Oldest", Value: messageLine(req.guildID, p.oldest), Inline: true},
+	}
+	var samples []string
+	for _, msg := range p.samples {
+		samples = append(samples, sampleLine(req.guildID, msg))
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{Name: "Samples", Value: strings.Join(samples, "\n")})
+	return embed
+}
+
+// messageLine is a message's jump link and relative time.
+func messageLine(guildID string, msg *discordgo.Message) string {
+	return fmt.Sprintf("[message](https://discord.com/channels/%s/%s/%s) <t:%d:R>", guildID, msg.ChannelID, msg.ID, msg.Timestamp.Unix())
+}
+
+// sampleLine quotes a message briefly: author and the start of its text.
+func sampleLine(guildID string, msg *discordgo.Message) string {
+	author := "unknown"
+	if msg.Author != nil {
+		author = "<@" + msg.Author.ID + ">"
+	}
+	text := strings.Join(strings.Fields(msg.Content), " ")
+	if text == "" && len(msg.Attachments) > 0 {
+		text = fmt.Sprintf("(%d attachment(s))", len(msg.Attachments))
+	}
+	if r := []rune(text); len(r) > 80 {
+		text = string(r[:79]) + "…"
+	}
+	text = strings.ReplaceAll(text, "`", "'")
+	return fmt.Sprintf("%s: `%s` · %s", author, text, messageLine(guildID, msg))
+}
+
+func previewButtons(id string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Confirm", Style: discordgo.DangerButton, CustomID: "purge:confirm:" + id},
+			discordgo.Button{Label: "Cancel", Style: discordgo.SecondaryButton, CustomID: "purge:cancel:" + id},
+		}},
+	}
+}
+
+// Component handles the Confirm and Cancel buttons of a purge preview.
+func (c *PurgeCommand) Component(ctx *command.ComponentInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+	parts := strings.Split(e.MessageComponentData().CustomID, ":")
+	if len(parts) != 3 || (parts[1] != "confirm" && parts[1] != "cancel") {
+		return ctx.Responder.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "Something smells off about this button.",
+		})
+	}
+	if e.Member == nil || e.Member.User == nil {
+		return nil
+	}
+
+	pendingPurgesMu.Lock()
+	req := pendingPurges[parts[2]]
+	if req != nil && req.userID == e.Member.User.ID {
+		delete(pendingPurges, parts[2])
+	}
+	pendingPurgesMu.Unlock()
+
+	var reply string
+	switch {
+	case req == nil:
+		reply = "This preview expired. Run the command again to purge."
+	case req.userID != e.Member.User.ID:
+		return ctx.Responder.RespondEmbedEphemeral(s, e, &discordgo.MessageEmbed{
+			Description: "Only whoever asked for this purge can confirm it.",
+		})
+	case parts[1] == "cancel":
+		reply = "Purge cancelled. Nothing was deleted."
+	case req.mode == "recurring":
+		reply = startRecurring(c.rulesContext(), s, ctx.Storage, ctx.Config.PurgeArchivesDir, ctx.Responder.EmbedColor(), req)
+	default:
+		reply = startDelayed(c.rulesContext(), s, ctx.Storage, ctx.Config.PurgeArchivesDir, ctx.Responder.EmbedColor(), req)
+	}
+
+	if err := s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{{Description: reply}},
+			Components: []discordgo.MessageComponent{},
+		},
+	}); err != nil {
+		return fmt.Errorf("purge: failed to update preview: %w", err)
+	}
+	return nil
+}
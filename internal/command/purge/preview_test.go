@@ -0,0 +1,41 @@
+package purge
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestScanPreview(t *testing.T) {
+	now := time.Now()
+	f := &fakeChannel{msgs: history(now, 10)}
+	even := func(m *discordgo.Message) bool { n, _ := strconv.Atoi(m.ID); return n%2 == 0 }
+
+	p := scanPreview(f, []string{"c"}, even)
+	if p.matched != 5 || !p.complete || p.newest.ID != "0" || p.oldest.ID != "8" || len(p.samples) != previewSamples {
+		t.Fatalf("preview = %+v", p)
+	}
+	if len(f.bulk)+len(f.single) != 0 {
+		t.Fatal("a preview deleted messages")
+	}
+
+	embed := previewEmbed(&purgeRequest{guildID: "g", mode: "delayed", dur: time.Minute}, p, 0)
+	if !strings.Contains(embed.Description, "**5** message(s)") || len(embed.Fields) != 3 {
+		t.Fatalf("embed = %+v", embed)
+	}
+}
+
+func TestScanPreviewStopsAtCap(t *testing.T) {
+	f := &fakeChannel{msgs: history(time.Now(), previewScanMax+50)}
+	p := scanPreview(f, []string{"c"}, all)
+	if p.complete || p.matched != previewScanMax {
+		t.Fatalf("matched = %d, complete = %v", p.matched, p.complete)
+	}
+	embed := previewEmbed(&purgeRequest{mode: "recurring", dur: time.Hour}, p, 0)
+	if !strings.Contains(embed.Description, "at least 3000") {
+		t.Fatalf("description = %q", embed.Description)
+	}
+}
@@ -0,0 +1,450 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/server-domme/internal/command"
+	st "github.com/keshon/server-domme/internal/domain"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+// recurringInterval is how often a recurring rule runs.
+const recurringInterval = 30 * time.Second
+
+// maxRuleName caps rule names; they are shown in lists and autocomplete choices.
+const maxRuleName = 32
+
+// ErrRuleRunning is returned when a rule with the same key is already running.
+var ErrRuleRunning = errors.New("purge rule is already running")
+
+// StartRecurring runs a recurring rule every recurringInterval until it is stopped or ctx ends. A
+// category rule covers the category's text channels as they are at each run.
+func StartRecurring(ctx context.Context, s *discordgo.Session, store *storage.Storage, archivesDir string, job st.PurgeJob) error {
+	dur, err := ParseDuration(job.OlderThan)
+	if err != nil {
+		return fmt.Errorf("invalid age %q: %w", job.OlderThan, err)
+	}
+	stopChan, ok := claimDeletion(job.Key())
+	if !ok {
+		return ErrRuleRunning
+	}
+
+	go func() {
+		defer releaseDeletion(job.Key(), stopChan)
+		ticker := time.NewTicker(recurringInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopChan:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				cutoff := time.Now().Add(-dur)
+				archive := NewArchive(store, archivesDir, job)
+				for _, channelID := range ruleChannels(s, job) {
+					DeleteMessages(s, job.GuildID, channelID, nil, &cutoff, job.Filter, archive, stopChan)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// StartDelayed runs a one-off rule at job.DelayUntil (at once if that has passed) and then removes it.
+func StartDelayed(ctx context.Context, s *discordgo.Session, store *storage.Storage, archivesDir string, job st.PurgeJob) {
+	go func() {
+		timer := time.NewTimer(time.Until(job.DelayUntil))
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		// The rule may have been stopped, or replaced by a newer /purge now, while it waited.
+		if !isCurrent(store, job) {
+			return
+		}
+		stopChan, ok := claimDeletion(job.Key())
+		if !ok {
+			return
+		}
+		archive := NewArchive(store, archivesDir, job)
+		for _, channelID := range ruleChannels(s, job) {
+			DeleteMessages(s, job.GuildID, channelID, nil, nil, job.Filter, archive, stopChan)
+		}
+		releaseDeletion(job.Key(), stopChan)
+
+		if !isCurrent(store, job) {
+			return
+		}
+		if err := store.ClearPurgeJob(job.GuildID, job.Key()); err != nil {
+			log.Printf("[ERR] Failed to delete purge job %s: %v", job.Key(), err)
+		}
+	}()
+}
+
+// isCurrent reports whether job is still the stored rule under its key.
+func isCurrent(store *storage.Storage, job st.PurgeJob) bool {
+	cur, ok, err := store.GetPurgeJob(job.GuildID, job.Key())
+	return err == nil && ok && cur.DelayUntil.Equal(job.DelayUntil) && cur.StartedAt.Equal(job.StartedAt)
+}
+
+// claimDeletion registers a running rule, failing if one with key already runs.
+func claimDeletion(key string) (chan struct{}, bool) {
+	ActiveDeletionsMu.Lock()
+	defer ActiveDeletionsMu.Unlock()
+	if _, exists := ActiveDeletions[key]; exists {
+		return nil, false
+	}
+	stopChan := make(chan struct{})
+	ActiveDeletions[key] = stopChan
+	return stopChan, true
+}
+
+// releaseDeletion unregisters a rule that ended by itself, unless it was stopped and replaced meanwhile.
+func releaseDeletion(key string, stopChan chan struct{}) {
+	ActiveDeletionsMu.Lock()
+	defer ActiveDeletionsMu.Unlock()
+	if ActiveDeletions[key] == stopChan {
+		delete(ActiveDeletions, key)
+	}
+}
+
+func isRunning(key string) bool {
+	ActiveDeletionsMu.Lock()
+	defer ActiveDeletionsMu.Unlock()
+	_, ok := ActiveDeletions[key]
+	return ok
+}
+
+// ruleChannels returns the channels a rule covers right now.
+func ruleChannels(s *discordgo.Session, job st.PurgeJob) []string {
+	if job.CategoryID == "" {
+		return []string{job.ChannelID}
+	}
+	var channels []*discordgo.Channel
+	if s.State != nil {
+		if g, err := s.State.Guild(job.GuildID); err == nil {
+			channels = g.Channels
+		}
+	}
+	if channels == nil {
+		var err error
+		if channels, err = s.GuildChannels(job.GuildID); err != nil {
+			log.Printf("[ERR] Failed to list channels of category %s: %v", job.CategoryID, err)
+			return nil
+		}
+	}
+	return channelsInCategory(channels, job.CategoryID)
+}
+
+// channelsInCategory returns the text and announcement channels under categoryID.
+func channelsInCategory(channels []*discordgo.Channel, categoryID string) []string {
+	var ids []string
+	for _, ch := range channels {
+		if ch.ParentID != categoryID {
+			continue
+		}
+		if ch.Type == discordgo.ChannelTypeGuildText || ch.Type == discordgo.ChannelTypeGuildNews {
+			ids = append(ids, ch.ID)
+		}
+	}
+	return ids
+}
+
+// ruleTarget renders where a rule applies.
+func ruleTarget(job st.PurgeJob) string {
+	if job.CategoryID != "" {
+		return "every channel in <#" + job.CategoryID + ">"
+	}
+	return "<#" + job.ChannelID + ">"
+}
+
+// validRuleName reports whether name can name a rule: short, and without the key separator.
+func validRuleName(name string) bool {
+	return name != "" && len(name) <= maxRuleName && !strings.Contains(name, "/")
+}
+
+// sortedRules returns the rules ordered by target, then name.
+func sortedRules(jobs map[string]st.PurgeJob) []st.PurgeJob {
+	list := make([]st.PurgeJob, 0, len(jobs))
+	for _, job := range jobs {
+		list = append(list, job)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Target() != list[j].Target() {
+			return list[i].Target() < list[j].Target()
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// ruleOption is the rule picker of /purge rules edit and delete.
+func ruleOption() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "rule",
+		Description:  "The purge rule",
+		Required:     true,
+		Autocomplete: true,
+	}
+}
+
+func rulesGroup() *discordgo.ApplicationCommandOption {
+	return &discordgo.ApplicationCommandOption{
+		Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+		Name:        "rules",
+		Description: "List, edit or delete purge rules",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "list",
+				Description: "List the purge rules of this server",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "edit",
+				Description: "Change the age, filters or archive of a recurring rule",
+				Options: append([]*discordgo.ApplicationCommandOption{
+					ruleOption(),
+					{
+						Type:        discordgo.ApplicationCommandOptionString,
+						Name:        "older_than",
+						Description: "Purge messages older than this (e.g. 10m, 1h, 1d, 1w)",
+					},
+					{
+						Type:        discordgo.ApplicationCommandOptionBoolean,
+						Name:        "clear_filters",
+						Description: "Drop the rule's filters before applying the ones given here",
+					},
+				}, append(filterOptions(), editArchiveOption())...),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "delete",
+				Description: "Stop and delete a purge rule",
+				Options:     []*discordgo.ApplicationCommandOption{ruleOption()},
+			},
+		},
+	}
+}
+
+// editArchiveOption is archiveOption with a choice to stop archiving.
+func editArchiveOption() *discordgo.ApplicationCommandOption {
+	opt := archiveOption()
+	opt.Choices = append(opt.Choices, &discordgo.ApplicationCommandOptionChoice{Name: "No transcript", Value: "off"})
+	return opt
+}
+
+func runPurgeRules(rulesCtx context.Context, ctx *command.SlashInteractionContext, group *discordgo.ApplicationCommandInteractionDataOption) error {
+	if len(group.Options) == 0 {
+		return ctx.Responder.RespondEmbedEphemeral(ctx.Session, ctx.Event, &discordgo.MessageEmbed{
+			Description: "Please select a subcommand: `list`, `edit`, or `delete`.",
+		})
+	}
+	sub := group.Options[0]
+	switch sub.Name {
+	case "list":
+		return runPurgeJobs(ctx)
+	case "edit":
+		return runRulesEdit(rulesCtx, ctx, sub)
+	case "delete":
+		return runRulesDelete(ctx, sub)
+	default:
+		return ctx.Responder.RespondEmbedEphemeral(ctx.Session, ctx.Event, &discordgo.MessageEmbed{
+			Description: fmt.Sprintf("Unknown subcommand: %s", sub.Name),
+		})
+	}
+}
+
+func runRulesEdit(rulesCtx context.Context, ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) error {
+	session, event := ctx.Session, ctx.Event
+	reply := func(desc string) error {
+		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{Description: desc})
+	}
+
+	var key string
+	var clearFilters bool
+	var filter st.PurgeFilter
+	var filterSet []*discordgo.ApplicationCommandInteractionDataOption
+	var olderThan, archive string
+	for _, opt := range sub.Options {
+		if parseFilterOption(&filter, opt) {
+			filterSet = append(filterSet, opt)
+			continue
+		}
+		switch opt.Name {
+		case "rule":
+			key = opt.StringValue()
+		case "clear_filters":
+			clearFilters = opt.BoolValue()
+		case "older_than":
+			olderThan = opt.StringValue()
+		case "archive":
+			archive = opt.StringValue()
+		}
+	}
+
+	job, ok, err := ctx.Storage.GetPurgeJob(event.GuildID, key)
+	if err != nil {
+		return reply("Failed to load the rule: " + err.Error())
+	}
+	if !ok {
+		return reply("No purge rule matches that. See `/purge rules list`.")
+	}
+	if job.Mode != "recurring" {
+		return reply("One-off purges cannot be edited. Delete it and run `/purge now` again.")
+	}
+
+	if clearFilters {
+		job.Filter = st.PurgeFilter{}
+	}
+	for _, opt := range filterSet {
+		parseFilterOption(&job.Filter, opt)
+	}
+	if olderThan != "" {
+		if _, err := ParseDuration(olderThan); err != nil {
+			return reply("Invalid duration format. Use `10m`, `2h`, `1d`, etc.")
+		}
+		job.OlderThan = olderThan
+	}
+	switch archive {
+	case "":
+	case "off":
+		job.Archive = ""
+	default:
+		job.Archive = archive
+	}
+	if _, err := newMatcher(job.Filter, nil); err != nil {
+		return reply("Invalid filter: " + err.Error())
+	}
+
+	if err := ctx.Storage.SetPurgeJob(event.GuildID, job); err != nil {
+		return reply("Failed to save the rule: " + err.Error())
+	}
+	stopDeletion(job.Key())
+	if err := StartRecurring(rulesCtx, session, ctx.Storage, ctx.Config.PurgeArchivesDir, job); err != nil {
+		return reply("The rule was saved but could not restart: " + err.Error())
+	}
+	return reply(fmt.Sprintf("Rule **%s** on %s updated.\n\n%s", job.Name, ruleTarget(job), describeRule(job)))
+}
+
+func runRulesDelete(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) error {
+	session, event := ctx.Session, ctx.Event
+	var key string
+	for _, opt := range sub.Options {
+		if opt.Name == "rule" {
+			key = opt.StringValue()
+		}
+	}
+
+	job, ok, err := ctx.Storage.GetPurgeJob(event.GuildID, key)
+	if err == nil && !ok {
+		err = errors.New("no purge rule matches that; see `/purge rules list`")
+	}
+	if err == nil {
+		err = ctx.Storage.ClearPurgeJob(event.GuildID, key)
+	}
+	if err != nil {
+		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
+			Description: "Failed to delete the rule: " + err.Error(),
+		})
+	}
+	stopDeletion(key)
+	return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
+		Description: fmt.Sprintf("Rule **%s** on %s deleted.", job.Name, ruleTarget(job)),
+	})
+}
+
+// describeRule is the body of a rule in lists: what it deletes, its filters and archive.
+func describeRule(job st.PurgeJob) string {
+	var sb strings.Builder
+	switch job.Mode {
+	case "delayed":
+		eta := time.Until(job.DelayUntil).Truncate(time.Second)
+		if eta > 0 {
+			sb.WriteString("Runs in: `" + eta.String() + "`\n")
+		} else {
+			sb.WriteString("Overdue by: `" + (-eta).String() + "`\n")
+		}
+	case "recurring":
+		sb.WriteString("Recurring purge of messages older than `" + job.OlderThan + "`")
+		if !isRunning(job.Key()) {
+			sb.WriteString(" (not running)")
+		}
+		sb.WriteString("\n")
+	default:
+		sb.WriteString("Unknown mode: " + job.Mode + "\n")
+	}
+	if desc := describeFilter(job.Filter); desc != "" {
+		sb.WriteString("Only messages " + desc + "\n")
+	}
+	if job.Archive != "" {
+		sb.WriteString("Archived to " + strings.ToUpper(job.Archive) + " transcripts\n")
+	}
+	return sb.String()
+}
+
+// Autocomplete suggests rules for /purge rules edit and delete.
+func (c *PurgeCommand) Autocomplete(ctx *command.AutocompleteInteractionContext) error {
+	s, e := ctx.Session, ctx.Event
+
+	var typed string
+	opts := e.ApplicationCommandData().Options
+	for len(opts) == 1 && len(opts[0].Options) > 0 && !opts[0].Focused {
+		opts = opts[0].Options
+	}
+	for _, opt := range opts {
+		if opt.Focused {
+			typed = strings.ToLower(strings.TrimSpace(opt.StringValue()))
+		}
+	}
+
+	choices := []*discordgo.ApplicationCommandOptionChoice{}
+	if ctx.Storage != nil {
+		jobs, err := ctx.Storage.GetPurgeJobsList(e.GuildID)
+		if err != nil {
+			return err
+		}
+		for _, job := range sortedRules(jobs) {
+			if len(choices) == 25 {
+				break
+			}
+			label := job.Name + " · " + targetName(s, job)
+			if typed == "" || strings.Contains(strings.ToLower(label), typed) {
+				choices = append(choices, &discordgo.ApplicationCommandOptionChoice{Name: label, Value: job.Key()})
+			}
+		}
+	}
+
+	return s.InteractionRespond(e.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+		Data: &discordgo.InteractionResponseData{Choices: choices},
+	})
+}
+
+// targetName is the plain-text name of a rule's channel or category, for autocomplete choices where
+// mentions do not render.
+func targetName(s *discordgo.Session, job st.PurgeJob) string {
+	name := job.Target()
+	if s.State != nil {
+		if ch, err := s.State.Channel(job.Target()); err == nil {
+			name = ch.Name
+		}
+	}
+	if job.CategoryID != "" {
+		return "category " + name
+	}
+	return "#" + name
+}
@@ -0,0 +1,42 @@
+package purge
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	st "github.com/keshon/server-domme/internal/domain"
+)
+
+func TestChannelsInCategory(t *testing.T) {
+	channels := []*discordgo.Channel{
+		{ID: "cat", Type: discordgo.ChannelTypeGuildCategory},
+		{ID: "a", ParentID: "cat", Type: discordgo.ChannelTypeGuildText},
+		{ID: "v", ParentID: "cat", Type: discordgo.ChannelTypeGuildVoice},
+		{ID: "n", ParentID: "cat", Type: discordgo.ChannelTypeGuildNews},
+		{ID: "o", ParentID: "other", Type: discordgo.ChannelTypeGuildText},
+	}
+	if got := channelsInCategory(channels, "cat"); !reflect.DeepEqual(got, []string{"a", "n"}) {
+		t.Fatalf("channels = %v", got)
+	}
+}
+
+func TestRuleNamesAndOrder(t *testing.T) {
+	for name, want := range map[string]bool{"default": true, "": false, "a/b": false, "abcdefghijklmnopqrstuvwxyz0123456": false} {
+		if validRuleName(name) != want {
+			t.Errorf("validRuleName(%q) = %v", name, !want)
+		}
+	}
+
+	jobs := map[string]st.PurgeJob{}
+	for _, j := range []st.PurgeJob{{ChannelID: "c2", Name: "a"}, {ChannelID: "c1", Name: "weekly"}, {ChannelID: "c1", Name: "daily"}} {
+		jobs[j.Key()] = j
+	}
+	var keys []string
+	for _, j := range sortedRules(jobs) {
+		keys = append(keys, j.Key())
+	}
+	if !reflect.DeepEqual(keys, []string{"c1/daily", "c1/weekly", "c2/a"}) {
+		t.Fatalf("order = %v", keys)
+	}
+}
@@ -1,11 +1,15 @@
 package purge
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"regexp"
 
 	"github.com/keshon/server-domme/internal/command"
+	st "github.com/keshon/server-domme/internal/domain"
+	"github.com/keshon/server-domme/internal/storage"
 
 	"strconv"
 	"strings"
@@ -15,7 +19,19 @@ import (
 	"github.com/bwmarrin/discordgo"
 )
 
-type PurgeCommand struct{}
+// PurgeCommand is /purge. Bot supplies the context rules run in, the one RunScheduler gets too, so
+// rules started from a command also stop on shutdown.
+type PurgeCommand struct {
+	Bot interface{ BackgroundContext() context.Context }
+}
+
+// rulesContext is the context rules started by the command run in.
+func (c *PurgeCommand) rulesContext() context.Context {
+	if c.Bot == nil {
+		return context.Background()
+	}
+	return c.Bot.BackgroundContext()
+}
 
 func (c *PurgeCommand) Name() string        { return "purge" }
 func (c *PurgeCommand) Description() string { return "Manage message purges" }
@@ -33,8 +49,8 @@ func (c *PurgeCommand) SlashDefinition() *discordgo.ApplicationCommand {
 			{
 				Type:        discordgo.ApplicationCommandOptionSubCommand,
 				Name:        "auto",
-				Description: "Regularly purge old messages in this channel",
-				Options: []*discordgo.ApplicationCommandOption{
+				Description: "Add a rule that regularly purges old messages in this channel or a category",
+				Options: append([]*discordgo.ApplicationCommandOption{
 					{
 						Type:        discordgo.ApplicationCommandOptionString,
 						Name:        "older_than",
@@ -53,17 +69,23 @@ func (c *PurgeCommand) SlashDefinition() *discordgo.ApplicationCommand {
 					},
 					{
 						Type:        discordgo.ApplicationCommandOptionString,
-						Name:        "confirm",
-						Description: "Type 'yes' to confirm the action",
-						Required:    true,
+						Name:        "name",
+						Description: fmt.Sprintf("Rule name, to keep several rules on one channel (default %q)", st.PurgeRuleDefault),
+						MaxLength:   maxRuleName,
 					},
-				},
+					{
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "category",
+						Description:  "Cover every text channel in this category, including ones created later",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildCategory},
+					},
+				}, append(filterOptions(), archiveOption())...),
 			},
 			{
 				Type:        discordgo.ApplicationCommandOptionSubCommand,
 				Name:        "now",
 				Description: "Schedule or perform an immediate purge",
-				Options: []*discordgo.ApplicationCommandOption{
+				Options: append([]*discordgo.ApplicationCommandOption{
 					{
 						Type:        discordgo.ApplicationCommandOptionString,
 						Name:        "delay",
@@ -88,11 +110,18 @@ func (c *PurgeCommand) SlashDefinition() *discordgo.ApplicationCommand {
 							{Name: "No", Value: "false"},
 						},
 					},
+				}, append(filterOptions(), archiveOption())...),
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionSubCommand,
+				Name:        "log",
+				Description: "Post purge transcripts to a channel",
+				Options: []*discordgo.ApplicationCommandOption{
 					{
-						Type:        discordgo.ApplicationCommandOptionString,
-						Name:        "confirm",
-						Description: "Type 'yes' to confirm the action",
-						Required:    true,
+						Type:         discordgo.ApplicationCommandOptionChannel,
+						Name:         "channel",
+						Description:  "Where to post transcripts (leave empty to stop posting them)",
+						ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
 					},
 				},
 			},
@@ -106,6 +135,7 @@ func (c *PurgeCommand) SlashDefinition() *discordgo.ApplicationCommand {
 				Name:        "stop",
 				Description: "Stop ongoing purge in this channel",
 			},
+			rulesGroup(),
 		},
 	}
 }
@@ -122,7 +152,7 @@ func (c *PurgeCommand) Run(ctx interface{}) error {
 	data := event.ApplicationCommandData()
 	if len(data.Options) == 0 {
 		return context.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "Please select a subcommand: `auto`, `now`, `jobs`, or `stop`.",
+			Description: "Please select a subcommand: `auto`, `now`, `log`, `jobs`, `stop`, or `rules`.",
 		})
 	}
 
@@ -132,10 +162,14 @@ func (c *PurgeCommand) Run(ctx interface{}) error {
 		return runPurgeAuto(context, sub)
 	case "now":
 		return runPurgeNow(context, sub)
+	case "log":
+		return runPurgeLog(context, sub)
 	case "jobs":
 		return runPurgeJobs(context)
 	case "stop":
 		return runPurgeStop(context)
+	case "rules":
+		return runPurgeRules(c.rulesContext(), context, sub)
 	default:
 		return context.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
 			Description: fmt.Sprintf("Unknown subcommand: %s", sub.Name),
@@ -146,166 +180,193 @@ func (c *PurgeCommand) Run(ctx interface{}) error {
 func runPurgeAuto(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) error {
 	session := ctx.Session
 	event := ctx.Event
-	storage := ctx.Storage
-
-	var olderThan, confirm string
-	var notifyAll bool
 
+	req := &purgeRequest{
+		id:        event.ID,
+		userID:    event.Member.User.ID,
+		guildID:   event.GuildID,
+		channelID: event.ChannelID,
+		mode:      "recurring",
+		name:      st.PurgeRuleDefault,
+	}
 	for _, opt := range sub.Options {
+		if parseFilterOption(&req.filter, opt) {
+			continue
+		}
 		switch opt.Name {
+		case "archive":
+			req.archive = opt.StringValue()
 		case "older_than":
-			olderThan = opt.StringValue()
-		case "confirm":
-			confirm = opt.StringValue()
+			req.olderThan = opt.StringValue()
+		case "name":
+			req.name = strings.TrimSpace(opt.StringValue())
+		case "category":
+			req.categoryID, _ = opt.Value.(string)
 		case "notify_all":
-			notifyAll = strings.ToLower(opt.StringValue()) == "true"
+			req.notifyAll = strings.ToLower(opt.StringValue()) == "true"
 		}
 	}
 
-	if strings.ToLower(confirm) != "yes" {
+	if !validRuleName(req.name) {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "Action not confirmed. Please type 'yes' to proceed.",
+			Description: fmt.Sprintf("Rule names are 1 to %d characters, without `/`.", maxRuleName),
 		})
 	}
 
-	dur, err := parseDuration(olderThan)
+	dur, err := ParseDuration(req.olderThan)
 	if err != nil {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
 			Description: "Invalid duration format. Use `10m`, `2h`, `1d`, etc.",
 		})
 	}
+	req.dur = dur
 
-	if !ctx.Responder.CheckBotPermissions(session, event.ChannelID) {
+	if _, err := newMatcher(req.filter, nil); err != nil {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "Missing permissions to purge messages.",
+			Description: "Invalid filter: " + err.Error(),
 		})
 	}
 
-	ActiveDeletionsMu.Lock()
-	if _, exists := ActiveDeletions[event.ChannelID]; exists {
-		ActiveDeletionsMu.Unlock()
+	if !ctx.Responder.CheckBotPermissions(session, event.ChannelID) {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "A purge job is already running in this channel.",
+			Description: "Missing permissions to purge messages.",
 		})
 	}
-	stopChan := make(chan struct{})
-	ActiveDeletions[event.ChannelID] = stopChan
-	ActiveDeletionsMu.Unlock()
 
-	err = storage.SetDeletionJob(event.GuildID, event.ChannelID, "recurring", time.Now(), notifyAll, olderThan)
-	if err != nil {
-		stopDeletion(event.ChannelID)
+	job := req.job()
+	if _, exists, _ := ctx.Storage.GetPurgeJob(event.GuildID, job.Key()); exists || isRunning(job.Key()) {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "Failed to set deletion job: " + err.Error(),
+			Description: fmt.Sprintf("%s already has a rule named **%s**. Change it with `/purge rules edit` or pick another name.", ruleTarget(job), req.name),
 		})
 	}
 
-	embedColor := ctx.Responder.EmbedColor()
-	ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-		Description: "Recurring purge started. Messages older than **" + dur.String() + "** will be erased.",
-	})
+	return showPreview(ctx, req)
+}
+
+// startRecurring starts a confirmed recurring purge and returns the reply for whoever confirmed it.
+func startRecurring(rulesCtx context.Context, session *discordgo.Session, store *storage.Storage, archivesDir string, embedColor int, req *purgeRequest) string {
+	job := req.job()
+	job.StartedAt = time.Now()
+	if _, exists, _ := store.GetPurgeJob(job.GuildID, job.Key()); exists || isRunning(job.Key()) {
+		return fmt.Sprintf("%s already has a rule named **%s**.", ruleTarget(job), job.Name)
+	}
+	if err := store.SetPurgeJob(job.GuildID, job); err != nil {
+		return "Failed to set deletion job: " + err.Error()
+	}
+	if err := StartRecurring(rulesCtx, session, store, archivesDir, job); err != nil {
+		_ = store.ClearPurgeJob(job.GuildID, job.Key())
+		return "Failed to start the purge: " + err.Error()
+	}
 
-	if notifyAll {
-		session.ChannelMessageSendEmbed(event.ChannelID, &discordgo.MessageEmbed{
+	where := ""
+	if job.CategoryID != "" {
+		where = " in " + ruleTarget(job)
+	}
+	if req.notifyAll {
+		session.ChannelMessageSendEmbed(req.channelID, &discordgo.MessageEmbed{
 			Title:       "☢️ Recurring Nuke Detonation",
-			Description: fmt.Sprintf("All messages older than `%s` will be **systematically erased**.", dur.String()),
+			Description: fmt.Sprintf("All messages%s older than `%s`%s will be **systematically erased**.", where, req.dur.String(), filterSuffix(req.filter)),
 			Color:       embedColor,
 			Image:       &discordgo.MessageEmbedImage{URL: "https://ichef.bbci.co.uk/images/ic/1376xn/p05cj1tt.jpg.webp"},
 			Footer:      &discordgo.MessageEmbedFooter{Text: "History has a half-life."},
 		})
 	}
-
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-		defer stopDeletion(event.ChannelID)
-
-		for {
-			select {
-			case <-stopChan:
-				return
-			case <-ticker.C:
-				start := time.Now().Add(-dur)
-				now := time.Now()
-				DeleteMessages(session, event.ChannelID, &now, &start, stopChan)
-			}
-		}
-	}()
-	return nil
+	return "Recurring purge **" + job.Name + "** started. Messages" + where + " older than **" + req.dur.String() + "**" + filterSuffix(req.filter) + " will be erased."
 }
 
 func runPurgeNow(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) error {
 	session := ctx.Session
 	event := ctx.Event
-	storage := ctx.Storage
 
-	var delayStr, confirm string
-	var notifyAll bool
+	req := &purgeRequest{
+		id:        event.ID,
+		userID:    event.Member.User.ID,
+		guildID:   event.GuildID,
+		channelID: event.ChannelID,
+		mode:      "delayed",
+		name:      st.PurgeRuleDelayed,
+	}
+	var delayStr string
 	for _, opt := range sub.Options {
+		if parseFilterOption(&req.filter, opt) {
+			continue
+		}
 		switch opt.Name {
+		case "archive":
+			req.archive = opt.StringValue()
 		case "delay":
 			delayStr = opt.StringValue()
-		case "confirm":
-			confirm = opt.StringValue()
 		case "notify_all":
-			notifyAll = strings.ToLower(opt.StringValue()) == "true"
+			req.notifyAll = strings.ToLower(opt.StringValue()) == "true"
 		}
 	}
 
-	if strings.ToLower(confirm) != "yes" {
-		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "Action not confirmed. Please type 'yes' to proceed.",
-		})
-	}
-
 	if delayStr == "0s" {
 		delayStr = "10s"
 	}
 
-	dur, err := parseDuration(delayStr)
+	dur, err := ParseDuration(delayStr)
 	if err != nil {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
 			Description: "Invalid delay format. Use formats like `10m`, `1h`, `1d`.",
 		})
 	}
+	req.dur = dur
 
-	delayUntil := time.Now().Add(dur)
-	if err := storage.SetDeletionJob(event.GuildID, event.ChannelID, "delayed", delayUntil, notifyAll); err != nil {
+	if _, err := newMatcher(req.filter, nil); err != nil {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "Failed to schedule purge: " + err.Error(),
+			Description: "Invalid filter: " + err.Error(),
 		})
 	}
 
-	embedColor := ctx.Responder.EmbedColor()
-	ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-		Description: "Purge scheduled — will start in **" + dur.String() + "**.",
-	})
+	return showPreview(ctx, req)
+}
+
+// startDelayed schedules a confirmed purge and returns the reply for whoever confirmed it. A newer
+// /purge now in the same channel replaces one still waiting.
+func startDelayed(rulesCtx context.Context, session *discordgo.Session, store *storage.Storage, archivesDir string, embedColor int, req *purgeRequest) string {
+	job := req.job()
+	job.StartedAt = time.Now()
+	job.DelayUntil = job.StartedAt.Add(req.dur)
+	if err := store.SetPurgeJob(job.GuildID, job); err != nil {
+		return "Failed to schedule purge: " + err.Error()
+	}
 
-	if notifyAll {
-		session.ChannelMessageSendEmbed(event.ChannelID, &discordgo.MessageEmbed{
+	if req.notifyAll {
+		session.ChannelMessageSendEmbed(req.channelID, &discordgo.MessageEmbed{
 			Title:       "☢️ Upcoming Nuke Detonation",
-			Description: "Countdown initiated — all messages will be purged in `" + dur.String() + "`.",
+			Description: "Countdown initiated — all messages" + filterSuffix(req.filter) + " will be purged in `" + req.dur.String() + "`.",
 			Color:       embedColor,
 			Image:       &discordgo.MessageEmbedImage{URL: "https://c.tenor.com/qDvLEFO5bAkAAAAd/tenor.gif"},
 			Footer:      &discordgo.MessageEmbedFooter{Text: "May your sins be incinerated."},
 		})
 	}
 
-	go func() {
-		time.Sleep(dur)
-		stopChan := make(chan struct{})
-		ActiveDeletionsMu.Lock()
-		ActiveDeletions[event.ChannelID] = stopChan
-		ActiveDeletionsMu.Unlock()
+	StartDelayed(rulesCtx, session, store, archivesDir, job)
+	return "Purge" + filterSuffix(req.filter) + " scheduled — will start in **" + req.dur.String() + "**."
+}
 
-		DeleteMessages(session, event.ChannelID, nil, nil, stopChan)
+func runPurgeLog(ctx *command.SlashInteractionContext, sub *discordgo.ApplicationCommandInteractionDataOption) error {
+	session := ctx.Session
+	event := ctx.Event
 
-		ActiveDeletionsMu.Lock()
-		delete(ActiveDeletions, event.ChannelID)
-		ActiveDeletionsMu.Unlock()
-		storage.ClearDeletionJob(event.GuildID, event.ChannelID)
-	}()
-	return nil
+	var channelID string
+	for _, opt := range sub.Options {
+		if opt.Name == "channel" {
+			channelID, _ = opt.Value.(string)
+		}
+	}
+
+	if err := ctx.Storage.SetPurgeLogChannel(event.GuildID, channelID); err != nil {
+		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
+			Description: "Failed to set the purge log channel: " + err.Error(),
+		})
+	}
+	desc := "Purge transcripts will no longer be posted."
+	if channelID != "" {
+		desc = "Purge transcripts will be posted to <#" + channelID + ">. Add `archive` to `/purge now` or `/purge auto` to write them."
+	}
+	return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{Description: desc})
 }
 
 func runPurgeJobs(ctx *command.SlashInteractionContext) error {
@@ -313,7 +374,7 @@ func runPurgeJobs(ctx *command.SlashInteractionContext) error {
 	event := ctx.Event
 	storage := ctx.Storage
 
-	jobs, err := storage.GetDeletionJobsList(event.GuildID)
+	jobs, err := storage.GetPurgeJobsList(event.GuildID)
 	if err != nil || len(jobs) == 0 {
 		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
 			Description: "No active purge jobs found.",
@@ -322,63 +383,65 @@ func runPurgeJobs(ctx *command.SlashInteractionContext) error {
 
 	var sb strings.Builder
 	sb.WriteString("☢️ **Active Message Purge Jobs**\n\n")
-	for _, job := range jobs {
-		sb.WriteString("<#" + job.ChannelID + ">\n")
-		switch job.Mode {
-		case "delayed":
-			eta := time.Until(job.DelayUntil).Truncate(time.Second)
-			if eta > 0 {
-				sb.WriteString("Runs in: `" + eta.String() + "`\n")
-			} else {
-				sb.WriteString("Overdue by: `" + (-eta).String() + "`\n")
-			}
-		case "recurring":
-			sb.WriteString("Recurring purge of messages older than `" + job.OlderThan + "`\n")
-		default:
-			sb.WriteString("Unknown mode: " + job.Mode + "\n")
-		}
+	for _, job := range sortedRules(jobs) {
+		sb.WriteString("**" + job.Name + "** · " + ruleTarget(job) + "\n")
+		sb.WriteString(describeRule(job))
 		sb.WriteString("\n")
 	}
-	sb.WriteString("Use `/purge stop` to cancel any listed job.")
+	sb.WriteString("Use `/purge rules edit` or `/purge rules delete` to change a rule, or `/purge stop` to stop every rule of a channel.")
 	return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{Description: sb.String()})
 }
 
+// runPurgeStop stops and removes the rules of the current channel; category rules keep running.
 func runPurgeStop(ctx *command.SlashInteractionContext) error {
 	session := ctx.Session
 	event := ctx.Event
 	storage := ctx.Storage
 
-	stopDeletion(event.ChannelID)
-	if _, err := storage.GetDeletionJob(event.GuildID, event.ChannelID); err == nil {
-		_ = storage.ClearDeletionJob(event.GuildID, event.ChannelID)
-		ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
-			Description: "Message purge job stopped.",
+	jobs, err := storage.GetPurgeJobsList(event.GuildID)
+	if err != nil {
+		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
+			Description: "Failed to load purge jobs: " + err.Error(),
 		})
-	} else {
-		ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
+	}
+	stopped := 0
+	for key, job := range jobs {
+		if job.CategoryID != "" || job.ChannelID != event.ChannelID {
+			continue
+		}
+		stopDeletion(key)
+		_ = storage.ClearPurgeJob(event.GuildID, key)
+		stopped++
+	}
+	if stopped == 0 {
+		return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
 			Description: "No active purge job in this channel.",
 		})
 	}
-	return nil
+	return ctx.Responder.RespondEmbedEphemeral(session, event, &discordgo.MessageEmbed{
+		Description: fmt.Sprintf("Stopped %d purge job(s) in this channel. Category rules keep running; remove them with `/purge rules delete`.", stopped),
+	})
 }
 
 var (
+	// ActiveDeletions holds the stop channels of running rules by key (see domain.PurgeJobKey).
 	ActiveDeletions   = make(map[string]chan struct{})
 	ActiveDeletionsMu sync.Mutex
 
 	timePattern = regexp.MustCompile(`(?i)(\d+)([smhdw])`)
 )
 
-func stopDeletion(channelID string) {
+func stopDeletion(key string) {
 	ActiveDeletionsMu.Lock()
 	defer ActiveDeletionsMu.Unlock()
-	if ch, ok := ActiveDeletions[channelID]; ok {
+	if ch, ok := ActiveDeletions[key]; ok {
 		close(ch)
-		delete(ActiveDeletions, channelID)
+		delete(ActiveDeletions, key)
 	}
 }
 
-func parseDuration(input string) (time.Duration, error) {
+// ParseDuration parses durations like `10m`, `2h`, `1d` or `1w2d`.
+func ParseDuration(input string) (time.Duration, error) {
 	matches := timePattern.FindAllStringSubmatch(input, -1)
 	if matches == nil {
 		return 0, errors.New("invalid duration format")
@@ -408,44 +471,49 @@ func parseDuration(input string) (time.Duration, error) {
 	return total, nil
 }
 
-func DeleteMessages(s *discordgo.Session, channelID string, startTime, endTime *time.Time, stopChan <-chan struct{}) {
-	var lastID string
+// filterSuffix is describeFilter for the middle of a sentence.
+func filterSuffix(f st.PurgeFilter) string {
+	if desc := describeFilter(f); desc != "" {
+		return " " + desc
+	}
+	return ""
+}
 
-	for {
-		select {
-		case <-stopChan:
+// DeleteMessages deletes the messages of a channel that were posted within [startTime, endTime] (nil
+// bounds are open) and match filter, until stopChan closes. Messages younger than bulkDeleteMaxAge go
+// through the bulk-delete endpoint, older ones one at a time; the session's rate limiter paces both.
+// With an archive format set, each batch is written to a transcript before it is deleted.
+func DeleteMessages(s *discordgo.Session, guildID, channelID string, startTime, endTime *time.Time, filter st.PurgeFilter, archive Archive, stopChan <-chan struct{}) {
+	m, err := newMatcher(filter, sessionMemberRoles(s, guildID))
+	if err != nil {
+		log.Printf("[ERR] Skipping purge of channel %s: %v", channelID, err)
+		return
+	}
+	var t *transcript
+	var save func([]*discordgo.Message) error
+	if archive.Format != "" {
+		t = newTranscript(archive, guildID, channelID, time.Now())
+		save = t.write
+	}
+	defer func() {
+		if t == nil {
 			return
-		default:
 		}
-
-		msgs, err := s.ChannelMessages(channelID, 100, lastID, "", "")
-		if err != nil || len(msgs) == 0 {
-			break
+		written, err := t.close()
+		if err != nil {
+			log.Printf("[ERR] Failed to finish purge transcript %s: %v", t.path, err)
 		}
-
-		for _, msg := range msgs {
-			select {
-			case <-stopChan:
-				return
-			default:
-			}
-
-			if startTime != nil && msg.Timestamp.Before(*startTime) {
-				continue
-			}
-			if endTime != nil && msg.Timestamp.After(*endTime) {
-				continue
-			}
-
-			_ = s.ChannelMessageDelete(channelID, msg.ID)
-			time.Sleep(300 * time.Millisecond)
+		if written {
+			postTranscript(s, archive, channelID, t)
 		}
-
-		lastID = msgs[len(msgs)-1].ID
-		if len(msgs) < 100 {
-			break
+	}()
+	deleteMessages(s, channelID, time.Now(), func(msg *discordgo.Message) bool {
+		if startTime != nil && msg.Timestamp.Before(*startTime) {
+			return false
 		}
-	}
+		if endTime != nil && msg.Timestamp.After(*endTime) {
+			return false
+		}
+		return m.match(msg)
+	}, save, stopChan)
 }
-
-
@@ -41,6 +41,33 @@ type Config struct {
 	// Applies to mode=soft only.
 	PlayerTransportSoftAttempts int `env:"PLAYER_TRANSPORT_SOFT_ATTEMPTS" envDefault:"1"`
 
+	// VoiceEmptyTimeout disconnects the bot after its voice channel has had no listeners for this long (0 disables).
+	VoiceEmptyTimeout time.Duration `env:"VOICE_EMPTY_TIMEOUT" envDefault:"2m"`
+	// VoiceIdleTimeout disconnects the bot after it has been connected with nothing playing and an empty queue
+	// for this long (0 disables).
+	VoiceIdleTimeout time.Duration `env:"VOICE_IDLE_TIMEOUT" envDefault:"10m"`
+
+	// MusicResumeMode controls playback saved before a restart: auto (resume on ready), offer (post a
+	// pointer to /resume) or off.
+	MusicResumeMode string `env:"MUSIC_RESUME_MODE" envDefault:"offer"`
+	// MusicLibraryDir holds the server-side track library; each guild plays files from <dir>/<guildID>/.
+	MusicLibraryDir string `env:"MUSIC_LIBRARY_DIR" envDefault:"assets/music"`
+	// ResolverCacheDir keeps resolved links and search results on disk so repeated lookups skip the
+	// network; entries expire after ResolverCacheTTL (empty dir or 0 disables the cache).
+	ResolverCacheDir string        `env:"RESOLVER_CACHE_DIR" envDefault:"data/cache/resolve"`
+	ResolverCacheTTL time.Duration `env:"RESOLVER_CACHE_TTL" envDefault:"24h"`
+	// SoundboardDir holds the uploaded soundboard clips; each guild's clips live in <dir>/<guildID>/.
+	SoundboardDir string `env:"SOUNDBOARD_DIR" envDefault:"assets/soundboard"`
+	// RecordingsDir holds voice recordings; each guild's live in <dir>/<guildID>/<start time>/.
+	RecordingsDir string `env:"RECORDINGS_DIR" envDefault:"data/recordings"`
+	// RecordingsURL is the public URL RecordingsDir is served at, used to link recordings too large to
+	// upload (empty: the server path is shown instead).
+	RecordingsURL string `env:"RECORDINGS_URL"`
+	// AudioStreamAddr serves each guild's playback as Ogg Opus at http://<addr>/<guildID>.ogg (empty disables).
+	AudioStreamAddr string `env:"AUDIO_STREAM_ADDR"`
+	// PurgeArchivesDir holds purge transcripts; each channel's live in <dir>/<guildID>/<channelID>/.
+	PurgeArchivesDir string `env:"PURGE_ARCHIVES_DIR" envDefault:"data/archives"`
+
 	// Logging (applog / zerolog). LOG_FILE empty = stderr only (pretty console).
 	LogLevel      string `env:"LOG_LEVEL" envDefault:"info"`
 	LogFile       string `env:"LOG_FILE"`
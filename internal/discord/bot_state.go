@@ -26,6 +26,8 @@ type Bot struct {
 	voice     *voice.Service
 	log       zerolog.Logger
 
+	voiceWatch *voiceWatcher
+
 	cmdSyncer *commandsync.Syncer
 	cmdLogger *commandlogger.Logger
 
@@ -35,6 +37,9 @@ type Bot struct {
 	// once ensures one-time background services (e.g. /internal/readme) are not
 	// re-launched on subsequent reconnects.
 	once sync.Once
+	// bgCtx scopes those background services; bgCancel stops them on shutdown.
+	bgCtx    context.Context
+	bgCancel context.CancelFunc
 }
 
 type sessionCtxHolder struct {
@@ -56,6 +61,12 @@ func (b *Bot) baseSessionContext() context.Context {
 	return context.Background()
 }
 
+// BackgroundContext is the context of the bot's background services, such as purge rules; it ends on
+// shutdown.
+func (b *Bot) BackgroundContext() context.Context {
+	return b.bgCtx
+}
+
 func (b *Bot) guard() *execguard.Guard {
 	if v := b.cmdGuard.Load(); v != nil {
 		if holder, ok := v.(*cmdGuardHolder); ok && holder != nil && holder.g != nil {
@@ -4,8 +4,11 @@ import (
 	"fmt"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/parsers"
 	"github.com/keshon/melodix/pkg/music/player"
 	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/server-domme/internal/discord/voice"
+	"github.com/keshon/server-domme/internal/discord/voice/dsp"
 )
 
 // VoiceAPI is the interface the Discord bot exposes for voice/music commands.
@@ -13,14 +16,118 @@ type VoiceAPI interface {
 	// GetOrCreatePlayer returns an existing player for the guild or creates a new one.
 	GetOrCreatePlayer(guildID string) *player.Player
 
+	// ExistingPlayer returns the guild's player without creating one; nil means nothing has played yet.
+	ExistingPlayer(guildID string) *player.Player
+
 	// FindUserVoiceState returns the voice channel a user is currently in, or an error if none.
 	FindUserVoiceState(guildID, userID string) (*UserVoiceState, error)
 
 	// Resolve resolves input to tracks using the bot's shared resolver.
 	ResolveTracks(guildID, input, source, parser string) ([]sources.TrackInfo, error)
 
+	// SearchTracks returns up to limit matches for a title query, best first.
+	SearchTracks(guildID, query, source, parser string, limit int) ([]sources.TrackInfo, error)
+
 	// UpdatePlaybackStatus creates or edits the guild's music status message so updates work beyond 15 min token expiry.
 	UpdatePlaybackStatus(s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, embed *discordgo.MessageEmbed) error
+
+	// Queue returns a copy of the guild's upcoming tracks.
+	Queue(guildID string) []parsers.TrackParse
+
+	// QueueRemove removes the track at a 1-based queue position and returns it.
+	QueueRemove(guildID string, pos int) (parsers.TrackParse, error)
+
+	// QueueMove moves a track between two 1-based queue positions.
+	QueueMove(guildID string, from, to int) error
+
+	// QueueClear empties the queue without stopping the current track and returns how many tracks were removed.
+	QueueClear(guildID string) int
+
+	// QueueShuffle randomizes the order of queued tracks.
+	QueueShuffle(guildID string)
+
+	// SetLoopMode sets and persists what the guild's player does after a track finishes.
+	SetLoopMode(guildID string, mode player.LoopMode) error
+
+	// LoopMode returns the guild's current loop mode.
+	LoopMode(guildID string) player.LoopMode
+
+	// SetVolume applies a volume in percent (0–200) live and persists it as the guild default; returns the applied value.
+	SetVolume(guildID string, pct int) (int, error)
+
+	// Volume returns the guild's current volume in percent.
+	Volume(guildID string) int
+
+	// SetNormalize toggles and persists loudness normalisation for the guild.
+	SetNormalize(guildID string, on bool) error
+
+	// Normalize reports whether loudness normalisation is enabled for the guild.
+	Normalize(guildID string) bool
+
+	// SetFilter switches the guild's audio filter preset live and persists it.
+	SetFilter(guildID string, preset dsp.Preset) error
+
+	// Filter returns the guild's current audio filter preset.
+	Filter(guildID string) dsp.Preset
+
+	// SetCrossfade applies the crossfade between tracks in seconds (0 turns it off) and persists it; returns the applied value.
+	SetCrossfade(guildID string, sec int) (int, error)
+
+	// Crossfade returns the guild's crossfade between tracks in seconds.
+	Crossfade(guildID string) int
+
+	// SetGapless toggles and persists gapless transitions between tracks.
+	SetGapless(guildID string, on bool) error
+
+	// Gapless reports whether gapless transitions are enabled for the guild.
+	Gapless(guildID string) bool
+
+	// ShowPlaybackPanel renders the guild's now-playing panel, editing it in place or posting it as a followup to i
+	// (when missing or repost is set). posted reports whether i was answered by the panel.
+	ShowPlaybackPanel(s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, repost bool) (posted bool, err error)
+
+	// RestoreSession continues playback saved before a restart in its voice channel; returns the number of tracks restored.
+	// It fails with voice.ErrNoSavedSession when there is nothing to restore.
+	RestoreSession(guildID string) (int, error)
+
+	// VoteSkip records a vote to skip the current track; passed reports that votes reached needed and the
+	// caller should skip. It fails with voice.ErrAlreadyVoted on a repeated vote.
+	VoteSkip(guildID, userID string) (votes, needed int, passed bool, err error)
+
+	// PanelState returns the state the now-playing panel shows besides the player's own (e.g. skip votes).
+	PanelState(guildID string) voice.PanelState
+
+	// LibraryTracks lists the audio files in the guild's server-side library.
+	LibraryTracks(guildID string) ([]sources.TrackInfo, error)
+
+	// FindLibraryTrack returns the guild library file matching name. It fails with voice.ErrLibraryTrackNotFound
+	// or *voice.LibraryAmbiguousError.
+	FindLibraryTrack(guildID, name string) (sources.TrackInfo, error)
+
+	// SoundboardDir returns the directory holding the guild's soundboard clips ("" when disabled).
+	SoundboardDir(guildID string) string
+
+	// PlaySoundboardClip plays a clip file from the guild's soundboard in channelID, mixed over any music.
+	// It fails with voice.ErrSoundboardOtherChannel when the bot is in another voice channel.
+	PlaySoundboardClip(guildID, channelID, file string) error
+
+	// StartRecording records channelID, capturing only users who consent (startedBy counts as consenting).
+	// It fails with voice.ErrRecordingActive or voice.ErrRecordingOtherChannel.
+	StartRecording(guildID, channelID, noticeChannelID, startedBy string) (voice.RecordingInfo, error)
+
+	// Recording returns the guild's running recording, if any.
+	Recording(guildID string) (voice.RecordingInfo, bool)
+
+	// SetRecordingConsent stores userID's answer to the consent prompt of recording id. It fails with
+	// voice.ErrNoRecording when that recording is over.
+	SetRecordingConsent(guildID, id, userID string, accepted bool) (voice.RecordingInfo, error)
+
+	// StopRecording ends the guild's recording and returns its files. It fails with voice.ErrNoRecording.
+	StopRecording(guildID string) (*voice.RecordingResult, error)
+
+	// VoiceMetrics returns the guild's voice transport counters and current voice channel ("" when not
+	// connected). ok is false when the guild has not used voice since the bot started.
+	VoiceMetrics(guildID string) (m voice.VoiceMetrics, channelID string, ok bool)
 }
 
 // UserVoiceState holds minimal voice channel state for a user.
@@ -37,6 +144,14 @@ func (b *Bot) GetOrCreatePlayer(guildID string) *player.Player {
 	return b.voice.GetOrCreatePlayer(guildID)
 }
 
+// ExistingPlayer returns the guild's player without creating one (nil if none).
+func (b *Bot) ExistingPlayer(guildID string) *player.Player {
+	if b.voice == nil {
+		return nil
+	}
+	return b.voice.ExistingPlayer(guildID)
+}
+
 // FindUserVoiceState returns the voice channel a user is currently in, or an error if none.
 func (b *Bot) FindUserVoiceState(guildID, userID string) (*UserVoiceState, error) {
 	guild, err := b.dg.State.Guild(guildID)
@@ -59,6 +174,14 @@ func (b *Bot) ResolveTracks(guildID, input, source, parser string) ([]sources.Tr
 	return b.voice.ResolveTracks(guildID, input, source, parser)
 }
 
+// SearchTracks lists the top matches for a title query (delegates to voice service).
+func (b *Bot) SearchTracks(guildID, query, source, parser string, limit int) ([]sources.TrackInfo, error) {
+	if b.voice == nil {
+		return nil, fmt.Errorf("voice service not available")
+	}
+	return b.voice.SearchTracks(guildID, query, source, parser, limit)
+}
+
 // UpdatePlaybackStatus creates or edits the guild's music status message (delegates to voice service).
 func (b *Bot) UpdatePlaybackStatus(s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, embed *discordgo.MessageEmbed) error {
 	if b.voice == nil {
@@ -66,3 +189,235 @@ func (b *Bot) UpdatePlaybackStatus(s *discordgo.Session, i *discordgo.Interactio
 	}
 	return b.voice.UpdatePlaybackStatus(s, i, guildID, embed)
 }
+
+// Queue returns a copy of the guild's upcoming tracks (delegates to voice service).
+func (b *Bot) Queue(guildID string) []parsers.TrackParse {
+	if b.voice == nil {
+		return nil
+	}
+	return b.voice.Queue(guildID)
+}
+
+// QueueRemove removes the track at a 1-based queue position (delegates to voice service).
+func (b *Bot) QueueRemove(guildID string, pos int) (parsers.TrackParse, error) {
+	if b.voice == nil {
+		return parsers.TrackParse{}, fmt.Errorf("voice service not available")
+	}
+	return b.voice.QueueRemove(guildID, pos)
+}
+
+// QueueMove moves a track between two 1-based queue positions (delegates to voice service).
+func (b *Bot) QueueMove(guildID string, from, to int) error {
+	if b.voice == nil {
+		return fmt.Errorf("voice service not available")
+	}
+	return b.voice.QueueMove(guildID, from, to)
+}
+
+// QueueClear empties the guild's queue (delegates to voice service).
+func (b *Bot) QueueClear(guildID string) int {
+	if b.voice == nil {
+		return 0
+	}
+	return b.voice.QueueClear(guildID)
+}
+
+// QueueShuffle randomizes the guild's queue order (delegates to voice service).
+func (b *Bot) QueueShuffle(guildID string) {
+	if b.voice == nil {
+		return
+	}
+	b.voice.QueueShuffle(guildID)
+}
+
+// SetLoopMode sets and persists the guild's loop mode (delegates to voice service).
+func (b *Bot) SetLoopMode(guildID string, mode player.LoopMode) error {
+	if b.voice == nil {
+		return fmt.Errorf("voice service not available")
+	}
+	return b.voice.SetLoopMode(guildID, mode)
+}
+
+// LoopMode returns the guild's loop mode (delegates to voice service).
+func (b *Bot) LoopMode(guildID string) player.LoopMode {
+	if b.voice == nil {
+		return player.LoopOff
+	}
+	return b.voice.LoopMode(guildID)
+}
+
+// SetVolume applies and persists the guild's volume (delegates to voice service).
+func (b *Bot) SetVolume(guildID string, pct int) (int, error) {
+	if b.voice == nil {
+		return 0, fmt.Errorf("voice service not available")
+	}
+	return b.voice.SetVolume(guildID, pct)
+}
+
+// Volume returns the guild's volume in percent (delegates to voice service).
+func (b *Bot) Volume(guildID string) int {
+	if b.voice == nil {
+		return 0
+	}
+	return b.voice.Volume(guildID)
+}
+
+// SetNormalize toggles and persists loudness normalisation (delegates to voice service).
+func (b *Bot) SetNormalize(guildID string, on bool) error {
+	if b.voice == nil {
+		return fmt.Errorf("voice service not available")
+	}
+	return b.voice.SetNormalize(guildID, on)
+}
+
+// Normalize reports whether loudness normalisation is enabled (delegates to voice service).
+func (b *Bot) Normalize(guildID string) bool {
+	if b.voice == nil {
+		return false
+	}
+	return b.voice.Normalize(guildID)
+}
+
+// SetFilter switches and persists the guild's audio filter preset (delegates to voice service).
+func (b *Bot) SetFilter(guildID string, preset dsp.Preset) error {
+	if b.voice == nil {
+		return fmt.Errorf("voice service not available")
+	}
+	return b.voice.SetFilter(guildID, preset)
+}
+
+// Filter returns the guild's audio filter preset (delegates to voice service).
+func (b *Bot) Filter(guildID string) dsp.Preset {
+	if b.voice == nil {
+		return dsp.PresetOff
+	}
+	return b.voice.Filter(guildID)
+}
+
+// SetCrossfade applies and persists the guild's crossfade (delegates to voice service).
+func (b *Bot) SetCrossfade(guildID string, sec int) (int, error) {
+	if b.voice == nil {
+		return 0, fmt.Errorf("voice service not available")
+	}
+	return b.voice.SetCrossfade(guildID, sec)
+}
+
+// Crossfade returns the guild's crossfade in seconds (delegates to voice service).
+func (b *Bot) Crossfade(guildID string) int {
+	if b.voice == nil {
+		return 0
+	}
+	return b.voice.Crossfade(guildID)
+}
+
+// SetGapless toggles and persists gapless transitions (delegates to voice service).
+func (b *Bot) SetGapless(guildID string, on bool) error {
+	if b.voice == nil {
+		return fmt.Errorf("voice service not available")
+	}
+	return b.voice.SetGapless(guildID, on)
+}
+
+// Gapless reports whether gapless transitions are enabled (delegates to voice service).
+func (b *Bot) Gapless(guildID string) bool {
+	if b.voice == nil {
+		return false
+	}
+	return b.voice.Gapless(guildID)
+}
+
+// ShowPlaybackPanel renders the guild's now-playing panel (delegates to voice service).
+func (b *Bot) ShowPlaybackPanel(s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, repost bool) (bool, error) {
+	if b.voice == nil {
+		return false, nil
+	}
+	return b.voice.ShowPlaybackPanel(s, i, guildID, repost)
+}
+
+// SetPanelRenderer sets how now-playing panels are drawn. Call once while registering commands.
+func (b *Bot) SetPanelRenderer(r voice.PanelRenderer) {
+	if b.voice == nil {
+		return
+	}
+	b.voice.SetPanelRenderer(r)
+}
+
+// RestoreSession continues the guild's saved playback session (delegates to voice service).
+func (b *Bot) RestoreSession(guildID string) (int, error) {
+	if b.voice == nil {
+		return 0, fmt.Errorf("voice service not available")
+	}
+	return b.voice.RestoreSession(guildID)
+}
+
+// LibraryTracks lists the guild's library files (delegates to voice service).
+func (b *Bot) LibraryTracks(guildID string) ([]sources.TrackInfo, error) {
+	if b.voice == nil {
+		return nil, fmt.Errorf("voice service not available")
+	}
+	return b.voice.LibraryTracks(guildID)
+}
+
+// FindLibraryTrack returns the guild library file matching name (delegates to voice service).
+func (b *Bot) FindLibraryTrack(guildID, name string) (sources.TrackInfo, error) {
+	if b.voice == nil {
+		return sources.TrackInfo{}, fmt.Errorf("voice service not available")
+	}
+	return b.voice.FindLibraryTrack(guildID, name)
+}
+
+// SoundboardDir returns the guild's soundboard directory (delegates to voice service).
+func (b *Bot) SoundboardDir(guildID string) string {
+	if b.voice == nil {
+		return ""
+	}
+	return b.voice.SoundboardDir(guildID)
+}
+
+// PlaySoundboardClip plays a soundboard clip in the voice channel (delegates to voice service).
+func (b *Bot) PlaySoundboardClip(guildID, channelID, file string) error {
+	if b.voice == nil {
+		return fmt.Errorf("voice service not available")
+	}
+	return b.voice.PlaySoundboardClip(guildID, channelID, file)
+}
+
+// StartRecording starts recording a voice channel (delegates to voice service).
+func (b *Bot) StartRecording(guildID, channelID, noticeChannelID, startedBy string) (voice.RecordingInfo, error) {
+	if b.voice == nil {
+		return voice.RecordingInfo{}, fmt.Errorf("voice service not available")
+	}
+	return b.voice.StartRecording(guildID, channelID, noticeChannelID, startedBy)
+}
+
+// Recording returns the guild's running recording (delegates to voice service).
+func (b *Bot) Recording(guildID string) (voice.RecordingInfo, bool) {
+	if b.voice == nil {
+		return voice.RecordingInfo{}, false
+	}
+	return b.voice.Recording(guildID)
+}
+
+// SetRecordingConsent stores a consent answer for a recording (delegates to voice service).
+func (b *Bot) SetRecordingConsent(guildID, id, userID string, accepted bool) (voice.RecordingInfo, error) {
+	if b.voice == nil {
+		return voice.RecordingInfo{}, fmt.Errorf("voice service not available")
+	}
+	return b.voice.SetRecordingConsent(guildID, id, userID, accepted)
+}
+
+// StopRecording ends the guild's recording (delegates to voice service).
+func (b *Bot) StopRecording(guildID string) (*voice.RecordingResult, error) {
+	if b.voice == nil {
+		return nil, fmt.Errorf("voice service not available")
+	}
+	return b.voice.StopRecording(guildID)
+}
+
+// VoiceMetrics returns the guild's voice transport counters (delegates to voice service).
+func (b *Bot) VoiceMetrics(guildID string) (voice.VoiceMetrics, string, bool) {
+	if b.voice == nil {
+		return voice.VoiceMetrics{}, "", false
+	}
+	return b.voice.VoiceMetrics(guildID)
+}
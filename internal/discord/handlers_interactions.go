@@ -9,13 +9,15 @@ import (
 	"github.com/keshon/server-domme/internal/discord/discordreply"
 )
 
-// onInteractionCreate dispatches slash commands, context menu commands, and component interactions.
+// onInteractionCreate dispatches slash commands, context menu commands, component interactions and autocomplete.
 func (b *Bot) onInteractionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	switch i.Type {
 	case discordgo.InteractionApplicationCommand:
 		b.onApplicationCommand(s, i)
 	case discordgo.InteractionMessageComponent:
 		b.onComponentInteraction(s, i)
+	case discordgo.InteractionApplicationCommandAutocomplete:
+		b.onAutocomplete(s, i)
 	default:
 		b.log.Debug().Int("interaction_type", int(i.Type)).Msg("interaction_unhandled")
 	}
@@ -93,6 +95,54 @@ func (b *Bot) onComponentInteraction(s *discordgo.Session, i *discordgo.Interact
 	})
 }
 
+// onAutocomplete answers option suggestions. It skips the command guard: Discord expects the choices within
+// 3 seconds and handlers only read local state.
+func (b *Bot) onAutocomplete(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	name := i.ApplicationCommandData().Name
+	c := commandkit.DefaultRegistry.Get(name)
+	if c == nil {
+		b.log.Warn().Str("command", name).Msg("command_unknown")
+		return
+	}
+	handler, ok := commandkit.Root(c).(command.AutocompleteHandler)
+	if !ok {
+		return
+	}
+	// Suggestions can list guild data (rule names, library files), so they need the command's permissions.
+	if meta, ok := commandkit.Root(c).(command.Meta); ok && !memberHasAny(i.Member, meta.UserPermissions()) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionApplicationCommandAutocompleteResult,
+			Data: &discordgo.InteractionResponseData{Choices: []*discordgo.ApplicationCommandOptionChoice{}},
+		})
+		return
+	}
+	if err := handler.Autocomplete(&command.AutocompleteInteractionContext{
+		Session: s, Event: i, Storage: b.storage, Config: b.cfg, AppLog: b.log,
+	}); err != nil {
+		b.log.Warn().Str("command", name).Err(err).Msg("autocomplete_failed")
+	}
+}
+
+// memberHasAny reports whether the interaction member holds one of required (or is an administrator).
+// Discord sends the member's permissions in the channel with every guild interaction.
+func memberHasAny(m *discordgo.Member, required []int64) bool {
+	if len(required) == 0 {
+		return true
+	}
+	if m == nil {
+		return false
+	}
+	if m.Permissions&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+	for _, p := range required {
+		if m.Permissions&p != 0 {
+			return true
+		}
+	}
+	return false
+}
+
 // matchesComponentID reports whether a component customID belongs to a command.
 // CustomIDs follow the convention "commandName", "commandName:...", or "commandName_...".
 func matchesComponentID(customID, commandName string) bool {
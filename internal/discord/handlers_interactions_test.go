@@ -0,0 +1,29 @@
+package discord
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestMemberHasAny(t *testing.T) {
+	admin := []int64{discordgo.PermissionAdministrator}
+	cases := []struct {
+		name     string
+		member   *discordgo.Member
+		required []int64
+		want     bool
+	}{
+		{"open command", &discordgo.Member{}, nil, true},
+		{"open command in DMs", nil, nil, true},
+		{"no member", nil, admin, false},
+		{"missing permission", &discordgo.Member{Permissions: discordgo.PermissionSendMessages}, admin, false},
+		{"administrator", &discordgo.Member{Permissions: discordgo.PermissionAdministrator}, []int64{discordgo.PermissionManageMessages}, true},
+		{"one of several", &discordgo.Member{Permissions: discordgo.PermissionManageMessages}, []int64{discordgo.PermissionAdministrator, discordgo.PermissionManageMessages}, true},
+	}
+	for _, c := range cases {
+		if got := memberHasAny(c.member, c.required); got != c.want {
+			t.Errorf("%s: got %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package discord
 
 import (
-	"context"
-
 	"github.com/bwmarrin/discordgo"
 	"github.com/keshon/commandkit"
 	"github.com/keshon/server-domme/internal/config"
@@ -36,13 +34,23 @@ func (b *Bot) onReady(s *discordgo.Session, r *discordgo.Ready) {
 
 	// Background services start once across all reconnects.
 	b.once.Do(func() {
+		if b.voice != nil {
+			guildIDs := make([]string, 0, len(r.Guilds))
+			for _, g := range r.Guilds {
+				if !b.isGuildBlacklisted(g.ID) {
+					guildIDs = append(guildIDs, g.ID)
+				}
+			}
+			go b.voice.ResumeSavedSessions(guildIDs, b.cfg.MusicResumeMode)
+			go b.voice.RunAudioStreams(b.bgCtx)
+		}
 		b.log.Info().Msg("bg_services_started")
 		if err := readme.UpdateReadme(commandkit.DefaultRegistry, config.CategoryWeights, b.log); err != nil {
 			b.log.Error().Err(err).Msg("readme_update_failed")
 		}
-		bgCtx, _ := context.WithCancel(context.Background())
-		purge.RunScheduler(bgCtx, b.storage, s)
-		go shortlink.RunServerWithContext(bgCtx, b.storage)
+		purge.RunScheduler(b.bgCtx, b.storage, s, b.cfg.PurgeArchivesDir)
+		go shortlink.RunServerWithContext(b.bgCtx, b.storage)
+		go b.runVoiceWatcher(b.bgCtx)
 	})
 
 	b.log.Info().Str("username", botInfo.Username).Msg("discord_ready")
@@ -26,6 +26,8 @@ func NewBot(cfg *config.Config, storage *storage.Storage, log zerolog.Logger) *B
 		b.mu.RUnlock()
 		return s
 	}, cfg, storage, log)
+	b.voiceWatch = newVoiceWatcher(cfg.VoiceEmptyTimeout, cfg.VoiceIdleTimeout)
+	b.bgCtx, b.bgCancel = context.WithCancel(context.Background())
 	b.sessionCtx.Store(&sessionCtxHolder{ctx: context.Background()})
 	b.cmdGuard.Store(&cmdGuardHolder{g: disabledGuard})
 	return b
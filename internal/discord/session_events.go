@@ -20,4 +20,5 @@ func (b *Bot) wireSessionHandlers(dg *discordgo.Session, tracker *watchdog.Track
 	dg.AddHandler(b.onMessageCreate)
 	dg.AddHandler(b.onMessageReactionAdd)
 	dg.AddHandler(b.onInteractionCreate)
+	dg.AddHandler(b.onVoiceStateUpdate)
 }
@@ -58,6 +58,7 @@ func (b *Bot) RunSession(ctx context.Context) error {
 	case <-ctx.Done():
 		b.log.Info().Msg("shutdown_signal_received")
 		b.stopAllPlayers()
+		b.bgCancel()
 		return nil
 	case <-disconnected:
 		return fmt.Errorf("%w: websocket disconnected", ErrSessionUnhealthy)
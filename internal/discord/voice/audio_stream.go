@@ -0,0 +1,14 @@
+package voice
+
+import "context"
+
+// RunAudioStreams serves every guild's playback as an Ogg Opus stream on cfg.AudioStreamAddr until ctx is
+// cancelled. It returns at once when the streams are disabled; run it in a goroutine.
+func (s *Service) RunAudioStreams(ctx context.Context) {
+	if s.streams == nil {
+		return
+	}
+	if err := s.streams.Run(ctx, s.cfg.AudioStreamAddr); err != nil {
+		s.log.Error().Str("addr", s.cfg.AudioStreamAddr).Err(err).Msg("audio_stream_server_failed")
+	}
+}
@@ -0,0 +1,64 @@
+package voice
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// ConnectedChannels returns the voice channel the bot is connected to, keyed by guild ID.
+func (s *Service) ConnectedChannels() map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]string, len(s.sinkProviders))
+	for guildID, provider := range s.sinkProviders {
+		if provider == nil {
+			continue
+		}
+		if ch := provider.ChannelID(); ch != "" {
+			out[guildID] = ch
+		}
+	}
+	return out
+}
+
+// IsIdle reports whether the guild's player has nothing playing and nothing queued, and nothing is being
+// recorded.
+func (s *Service) IsIdle(guildID string) bool {
+	if s.isRecording(guildID) {
+		return false
+	}
+	p := s.ExistingPlayer(guildID)
+	return p == nil || (!p.IsPlaying() && len(p.Queue()) == 0)
+}
+
+// Disconnect stops the guild's player, clears its queue and leaves the voice channel. When notice is
+// non-empty it is posted as a reply to the guild's status message.
+func (s *Service) Disconnect(guildID, notice string) {
+	if p := s.ExistingPlayer(guildID); p != nil {
+		_ = p.Stop(true)
+	}
+	// Stop only releases the player's own target; the connection may outlive it (e.g. after a failed start).
+	s.mu.RLock()
+	provider := s.sinkProviders[guildID]
+	s.mu.RUnlock()
+	if provider != nil {
+		provider.ReleaseSink("")
+	}
+	if notice != "" {
+		s.postStatusNotice(guildID, notice)
+	}
+}
+
+// postStatusNotice replies to the guild's status message. Without one there is nowhere to post.
+func (s *Service) postStatusNotice(guildID, notice string) {
+	s.guildMusicStatusMu.RLock()
+	msg, ok := s.guildMusicStatus[guildID]
+	s.guildMusicStatusMu.RUnlock()
+	if !ok {
+		return
+	}
+	s.postChannelNotice(guildID, msg.ChannelID, &discordgo.MessageReference{
+		MessageID: msg.MessageID,
+		ChannelID: msg.ChannelID,
+		GuildID:   guildID,
+	}, notice)
+}
@@ -0,0 +1,82 @@
+package dsp
+
+import "math"
+
+// Biquad is a second-order IIR section with separate state per stereo channel. The constructors follow
+// the RBJ audio EQ cookbook.
+type Biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     [2]float64
+}
+
+func newBiquad(b0, b1, b2, a0, a1, a2 float64) *Biquad {
+	return &Biquad{b0: b0 / a0, b1: b1 / a0, b2: b2 / a0, a1: a1 / a0, a2: a2 / a0}
+}
+
+// NewLowShelf boosts or cuts everything below freq by gainDB (shelf slope 1).
+func NewLowShelf(freq, gainDB float64) *Biquad {
+	a := math.Sqrt(DBToGain(gainDB))
+	w := 2 * math.Pi * freq / sampleRate
+	cosw, alpha := math.Cos(w), math.Sin(w)/math.Sqrt2
+	sq := 2 * math.Sqrt(a) * alpha
+	return newBiquad(
+		a*((a+1)-(a-1)*cosw+sq),
+		2*a*((a-1)-(a+1)*cosw),
+		a*((a+1)-(a-1)*cosw-sq),
+		(a+1)+(a-1)*cosw+sq,
+		-2*((a-1)+(a+1)*cosw),
+		(a+1)+(a-1)*cosw-sq,
+	)
+}
+
+// NewHighShelf boosts or cuts everything above freq by gainDB (shelf slope 1).
+func NewHighShelf(freq, gainDB float64) *Biquad {
+	a := math.Sqrt(DBToGain(gainDB))
+	w := 2 * math.Pi * freq / sampleRate
+	cosw, alpha := math.Cos(w), math.Sin(w)/math.Sqrt2
+	sq := 2 * math.Sqrt(a) * alpha
+	return newBiquad(
+		a*((a+1)+(a-1)*cosw+sq),
+		-2*a*((a-1)+(a+1)*cosw),
+		a*((a+1)+(a-1)*cosw-sq),
+		(a+1)-(a-1)*cosw+sq,
+		2*((a-1)-(a+1)*cosw),
+		(a+1)-(a-1)*cosw-sq,
+	)
+}
+
+// NewPeaking boosts or cuts a band around freq by gainDB; q sets the bandwidth.
+func NewPeaking(freq, q, gainDB float64) *Biquad {
+	a := math.Sqrt(DBToGain(gainDB))
+	w := 2 * math.Pi * freq / sampleRate
+	cosw, alpha := math.Cos(w), math.Sin(w)/(2*q)
+	return newBiquad(1+alpha*a, -2*cosw, 1-alpha*a, 1+alpha/a, -2*cosw, 1-alpha/a)
+}
+
+// NewLowPass passes frequencies below freq (Butterworth response).
+func NewLowPass(freq float64) *Biquad {
+	w := 2 * math.Pi * freq / sampleRate
+	cosw, alpha := math.Cos(w), math.Sin(w)/math.Sqrt2
+	return newBiquad((1-cosw)/2, 1-cosw, (1-cosw)/2, 1+alpha, -2*cosw, 1-alpha)
+}
+
+// NewKWeighting returns the two BS.1770 K-weighting stages for 48 kHz, a high shelf and the RLB
+// high-pass, which loudness measurement applies in that order.
+func NewKWeighting() (shelf, highPass *Biquad) {
+	return newBiquad(1.53512485958697, -2.69169618940638, 1.19839281085285, 1, -1.69065929318241, 0.73248077421585),
+		newBiquad(1, -2, 1, 1, -1.99004745483398, 0.99007225036621)
+}
+
+// sample filters one sample of channel ch.
+func (f *Biquad) sample(ch int, x float64) float64 {
+	y := f.b0*x + f.b1*f.x1[ch] + f.b2*f.x2[ch] - f.a1*f.y1[ch] - f.a2*f.y2[ch]
+	f.x2[ch], f.x1[ch] = f.x1[ch], x
+	f.y2[ch], f.y1[ch] = f.y1[ch], y
+	return y
+}
+
+func (f *Biquad) Process(buf []float64) {
+	for i := range buf {
+		buf[i] = f.sample(i%2, buf[i])
+	}
+}
@@ -0,0 +1,46 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLowShelfBoostsBassOnly(t *testing.T) {
+	t.Parallel()
+	if g := gainDB(NewLowShelf(120, 9), 40); math.Abs(g-9) > 1 {
+		t.Fatalf("40 Hz gain = %.2f dB, want about +9", g)
+	}
+	if g := gainDB(NewLowShelf(120, 9), 5000); math.Abs(g) > 0.5 {
+		t.Fatalf("5 kHz gain = %.2f dB, want about 0", g)
+	}
+}
+
+func TestHighShelfCutsTreble(t *testing.T) {
+	t.Parallel()
+	if g := gainDB(NewHighShelf(4000, -6), 15000); math.Abs(g+6) > 1 {
+		t.Fatalf("15 kHz gain = %.2f dB, want about -6", g)
+	}
+	if g := gainDB(NewHighShelf(4000, -6), 100); math.Abs(g) > 0.5 {
+		t.Fatalf("100 Hz gain = %.2f dB, want about 0", g)
+	}
+}
+
+func TestPeakingHitsCentreFrequency(t *testing.T) {
+	t.Parallel()
+	if g := gainDB(NewPeaking(1000, 1, 6), 1000); math.Abs(g-6) > 0.3 {
+		t.Fatalf("1 kHz gain = %.2f dB, want +6", g)
+	}
+	if g := gainDB(NewPeaking(1000, 1, 6), 10000); g > 1 {
+		t.Fatalf("10 kHz gain = %.2f dB, want close to 0", g)
+	}
+}
+
+func TestLowPass(t *testing.T) {
+	t.Parallel()
+	if g := gainDB(NewLowPass(200), 50); math.Abs(g) > 0.5 {
+		t.Fatalf("50 Hz gain = %.2f dB, want about 0", g)
+	}
+	if g := gainDB(NewLowPass(200), 5000); g > -40 {
+		t.Fatalf("5 kHz gain = %.2f dB, want below -40", g)
+	}
+}
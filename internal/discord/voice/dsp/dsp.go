@@ -0,0 +1,68 @@
+// Package dsp implements the audio filters behind /filter. Filters work on the 48 kHz interleaved
+// stereo int16 frames the Discord sink encodes, converted to float64 in int16 scale.
+package dsp
+
+import (
+	"math"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+const sampleRate = float64(stream.SampleRate)
+
+// Filter processes interleaved stereo samples in place and keeps its state between frames.
+type Filter interface {
+	Process(buf []float64)
+}
+
+// Chain runs filters in order over int16 frames.
+type Chain struct {
+	filters []Filter
+	buf     []float64
+}
+
+// NewChain returns a chain of filters; with none, Process leaves frames untouched.
+func NewChain(filters ...Filter) *Chain {
+	return &Chain{filters: filters}
+}
+
+// Process filters one interleaved int16 frame in place, saturating at the int16 range.
+func (c *Chain) Process(frame []int16) {
+	if len(c.filters) == 0 {
+		return
+	}
+	if cap(c.buf) < len(frame) {
+		c.buf = make([]float64, len(frame))
+	}
+	buf := c.buf[:len(frame)]
+	for i, s := range frame {
+		buf[i] = float64(s)
+	}
+	for _, f := range c.filters {
+		f.Process(buf)
+	}
+	for i, v := range buf {
+		v = math.Round(v)
+		switch {
+		case v > math.MaxInt16:
+			v = math.MaxInt16
+		case v < math.MinInt16:
+			v = math.MinInt16
+		}
+		frame[i] = int16(v)
+	}
+}
+
+// Gain scales all samples by a fixed factor (e.g. headroom before a boost).
+type Gain float64
+
+func (g Gain) Process(buf []float64) {
+	for i := range buf {
+		buf[i] *= float64(g)
+	}
+}
+
+// DBToGain converts a level change in decibels to a linear gain factor.
+func DBToGain(db float64) float64 {
+	return math.Pow(10, db/20)
+}
@@ -0,0 +1,80 @@
+package dsp
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// sine returns seconds of an interleaved stereo sine at freq Hz and peak amplitude amp (int16 scale).
+// With inverted the right channel is the negated left one (pure side signal).
+func sine(freq, amp, seconds float64, inverted bool) []float64 {
+	n := int(seconds * sampleRate)
+	buf := make([]float64, 2*n)
+	for i := 0; i < n; i++ {
+		v := amp * math.Sin(2*math.Pi*freq*float64(i)/sampleRate)
+		buf[2*i] = v
+		buf[2*i+1] = v
+		if inverted {
+			buf[2*i+1] = -v
+		}
+	}
+	return buf
+}
+
+// rms returns the RMS level of channel ch over the second half of buf (past filter settling).
+func rms(buf []float64, ch int) float64 {
+	var sum float64
+	var n int
+	for i := len(buf)/2 + ch; i < len(buf); i += 2 {
+		sum += buf[i] * buf[i]
+		n++
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+func gainDB(f Filter, freq float64) float64 {
+	in := sine(freq, 10000, 0.5, false)
+	before := rms(in, 0)
+	f.Process(in)
+	return 20 * math.Log10(rms(in, 0)/before)
+}
+
+func TestChainSaturates(t *testing.T) {
+	t.Parallel()
+	frame := []int16{20000, -20000}
+	NewChain(Gain(2)).Process(frame)
+	if frame[0] != math.MaxInt16 || frame[1] != math.MinInt16 {
+		t.Fatalf("frame = %v, want saturated", frame)
+	}
+}
+
+func TestParsePreset(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]Preset{"": PresetOff, "Nightcore": PresetNightcore, " 8D ": Preset8D} {
+		if got, err := ParsePreset(in); err != nil || got != want {
+			t.Fatalf("ParsePreset(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParsePreset("chipmunk"); !errors.Is(err, ErrUnknownPreset) {
+		t.Fatalf("err = %v, want ErrUnknownPreset", err)
+	}
+}
+
+func TestPresetChainsStayFinite(t *testing.T) {
+	t.Parallel()
+	for _, p := range Presets {
+		frame := make([]int16, 1920)
+		for i := range frame {
+			frame[i] = int16(8000 * math.Sin(float64(i)/7))
+		}
+		p.NewChain().Process(frame)
+		var peak int16
+		for _, s := range frame {
+			peak = max(peak, s, -s)
+		}
+		if p != PresetKaraoke && peak == 0 {
+			t.Fatalf("%s silenced the signal", p)
+		}
+	}
+}
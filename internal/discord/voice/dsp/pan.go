@@ -0,0 +1,29 @@
+package dsp
+
+import "math"
+
+// AutoPan sweeps the stereo image from side to side with a sine LFO ("8D audio"). rate is the sweep
+// frequency in Hz and depth (0..1) how far towards one side it goes. Equal-power gains keep the level
+// steady; in the centre both channels are unchanged.
+type AutoPan struct {
+	rate, depth float64
+	phase       float64 // radians
+}
+
+func NewAutoPan(rate, depth float64) *AutoPan {
+	return &AutoPan{rate: rate, depth: math.Max(0, math.Min(1, depth))}
+}
+
+func (p *AutoPan) Process(buf []float64) {
+	step := 2 * math.Pi * p.rate / sampleRate
+	for i := 0; i+1 < len(buf); i += 2 {
+		pan := p.depth * math.Sin(p.phase) // -1 left .. 1 right
+		angle := (pan + 1) * math.Pi / 4
+		buf[i] *= math.Cos(angle) * math.Sqrt2
+		buf[i+1] *= math.Sin(angle) * math.Sqrt2
+		p.phase += step
+		if p.phase >= 2*math.Pi {
+			p.phase -= 2 * math.Pi
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package dsp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAutoPanSweepsKeepingPower(t *testing.T) {
+	t.Parallel()
+	// A 1 Hz sweep is fully right at 0.25 s and fully left at 0.75 s.
+	buf := sine(1000, 10000, 1, false)
+	NewAutoPan(1, 1).Process(buf)
+
+	window := func(from, to float64) (l, r float64) {
+		part := buf[2*int(from*sampleRate) : 2*int(to*sampleRate)]
+		// rms measures the second half of what it is given; hand it the window twice.
+		w := append(append([]float64(nil), part...), part...)
+		return rms(w, 0), rms(w, 1)
+	}
+	l, r := window(0.24, 0.26)
+	if r < 10*l {
+		t.Fatalf("at 0.25 s: left %.0f right %.0f, want right only", l, r)
+	}
+	l, r = window(0.74, 0.76)
+	if l < 10*r {
+		t.Fatalf("at 0.75 s: left %.0f right %.0f, want left only", l, r)
+	}
+
+	in := 10000 / math.Sqrt2
+	l, r = window(0, 1)
+	if p := l*l + r*r; math.Abs(p-2*in*in)/(2*in*in) > 0.02 {
+		t.Fatalf("power %.0f, want %.0f", p, 2*in*in)
+	}
+}
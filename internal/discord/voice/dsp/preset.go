@@ -0,0 +1,87 @@
+package dsp
+
+import (
+	"errors"
+	"strings"
+)
+
+// Preset names a filter combination selectable with /filter.
+type Preset string
+
+const (
+	PresetOff       Preset = "off"
+	PresetBassBoost Preset = "bassboost"
+	PresetNightcore Preset = "nightcore"
+	PresetVaporwave Preset = "vaporwave"
+	Preset8D        Preset = "8d"
+	PresetKaraoke   Preset = "karaoke"
+)
+
+var ErrUnknownPreset = errors.New("unknown filter preset")
+
+// Presets lists the presets in display order.
+var Presets = []Preset{PresetOff, PresetBassBoost, PresetNightcore, PresetVaporwave, Preset8D, PresetKaraoke}
+
+// ParsePreset maps a stored or user-supplied name to a Preset ("" is PresetOff).
+func ParsePreset(s string) (Preset, error) {
+	p := Preset(strings.ToLower(strings.TrimSpace(s)))
+	if p == "" {
+		return PresetOff, nil
+	}
+	for _, known := range Presets {
+		if p == known {
+			return p, nil
+		}
+	}
+	return PresetOff, ErrUnknownPreset
+}
+
+// Label is the human-readable preset name.
+func (p Preset) Label() string {
+	switch p {
+	case PresetBassBoost:
+		return "Bass boost"
+	case PresetNightcore:
+		return "Nightcore"
+	case PresetVaporwave:
+		return "Vaporwave"
+	case Preset8D:
+		return "8D"
+	case PresetKaraoke:
+		return "Karaoke"
+	default:
+		return "Off"
+	}
+}
+
+// Speed is the resampling ratio of the preset (1 leaves tempo and pitch alone).
+func (p Preset) Speed() float64 {
+	switch p {
+	case PresetNightcore:
+		return 1.25
+	case PresetVaporwave:
+		return 0.8
+	default:
+		return 1
+	}
+}
+
+// NewChain returns fresh per-frame filters for the preset. Speed changes are not part of the chain:
+// they resample the stream before it is cut into frames (see Resampler).
+func (p Preset) NewChain() *Chain {
+	switch p {
+	case PresetBassBoost:
+		// Headroom first so boosted bass does not clip.
+		return NewChain(Gain(DBToGain(-4)), NewLowShelf(120, 9), NewPeaking(60, 1, 3))
+	case PresetNightcore:
+		return NewChain(NewHighShelf(6000, 2))
+	case PresetVaporwave:
+		return NewChain(NewHighShelf(8000, -4))
+	case Preset8D:
+		return NewChain(NewAutoPan(0.125, 0.9))
+	case PresetKaraoke:
+		return NewChain(NewVocalCancel(200))
+	default:
+		return NewChain()
+	}
+}
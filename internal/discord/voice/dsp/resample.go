@@ -0,0 +1,117 @@
+package dsp
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Resampler changes playback speed by resampling s16le stereo PCM with linear interpolation: a ratio
+// of 1.25 plays 25% faster and higher (nightcore), 0.8 slower and lower. ratio is read on every Read,
+// so the speed can change while a track plays.
+//
+// Each Read takes from src only the input its output needs, so a caller can hand src on after the
+// last Read without samples going missing (at ratio 1 exactly; otherwise at most the pair an
+// interpolation between two samples still holds).
+type Resampler struct {
+	src   io.Reader
+	ratio func() float64
+	in    [][2]float64 // input pairs read but not yet passed; in[0] is at the read position
+	skip  int          // input pairs the read position moved past without reading them
+	frac  float64      // read position between in[0] (0) and in[1] (1)
+	err   error
+	raw   []byte
+}
+
+func NewResampler(src io.Reader, ratio func() float64) *Resampler {
+	return &Resampler{src: src, ratio: ratio}
+}
+
+// fill reads the input pairs up to index need-1, first dropping r.skip pairs.
+func (r *Resampler) fill(need int) {
+	missing := r.skip + need - len(r.in)
+	if missing <= 0 {
+		return
+	}
+	if cap(r.raw) < missing*4 {
+		r.raw = make([]byte, missing*4)
+	}
+	raw := r.raw[:missing*4]
+	n, err := io.ReadFull(r.src, raw)
+	for i := 0; i+4 <= n; i += 4 {
+		if r.skip > 0 {
+			r.skip--
+			continue
+		}
+		r.in = append(r.in, [2]float64{
+			float64(int16(binary.LittleEndian.Uint16(raw[i:]))),
+			float64(int16(binary.LittleEndian.Uint16(raw[i+2:]))),
+		})
+	}
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	r.err = err
+}
+
+// Read fills p with whole stereo pairs (4 bytes each).
+func (r *Resampler) Read(p []byte) (int, error) {
+	if r.err != nil && len(r.in) == 0 {
+		return 0, r.err
+	}
+	ratio := r.ratio()
+	if ratio <= 0 {
+		ratio = 1
+	}
+	pairs := len(p) / 4
+
+	// Find how far into the input these pairs reach: pair k reads in[i], plus in[i+1] between samples.
+	need, i, frac := 0, 0, r.frac
+	for k := 0; k < pairs; k++ {
+		reach := i + 1
+		if frac > 0 {
+			reach++
+		}
+		need = max(need, reach)
+		frac += ratio
+		for frac >= 1 {
+			frac--
+			i++
+		}
+	}
+	if r.err == nil {
+		r.fill(need)
+	}
+
+	n, i := 0, 0
+	for n+4 <= len(p) {
+		if i >= len(r.in) || (r.frac > 0 && i+1 >= len(r.in)) {
+			break
+		}
+		a, b := r.in[i], r.in[i]
+		if r.frac > 0 {
+			b = r.in[i+1]
+		}
+		for ch := 0; ch < 2; ch++ {
+			v := math.Round(a[ch] + (b[ch]-a[ch])*r.frac)
+			binary.LittleEndian.PutUint16(p[n+ch*2:], uint16(int16(v)))
+		}
+		n += 4
+		r.frac += ratio
+		for r.frac >= 1 {
+			r.frac--
+			i++
+		}
+	}
+	if i <= len(r.in) {
+		r.in = append(r.in[:0], r.in[i:]...)
+	} else {
+		r.skip += i - len(r.in)
+		r.in = r.in[:0]
+	}
+	if n == 0 && r.err != nil {
+		r.in = r.in[:0]
+		return 0, r.err
+	}
+	return n, nil
+}
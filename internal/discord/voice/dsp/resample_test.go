@@ -0,0 +1,91 @@
+package dsp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+)
+
+func pcm(buf []float64) []byte {
+	out := make([]byte, 2*len(buf))
+	for i, v := range buf {
+		binary.LittleEndian.PutUint16(out[2*i:], uint16(int16(math.Round(v))))
+	}
+	return out
+}
+
+// zeroCrossings counts sign changes of the left channel.
+func zeroCrossings(data []byte) int {
+	n := 0
+	prev := int16(0)
+	for i := 0; i+4 <= len(data); i += 4 {
+		v := int16(binary.LittleEndian.Uint16(data[i:]))
+		if (prev < 0 && v >= 0) || (prev >= 0 && v < 0) {
+			n++
+		}
+		prev = v
+	}
+	return n
+}
+
+func TestResamplerNightcoreRaisesPitchAndShortens(t *testing.T) {
+	t.Parallel()
+	in := pcm(sine(1000, 10000, 1, false))
+	out, err := io.ReadAll(NewResampler(bytes.NewReader(in), func() float64 { return 1.25 }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(out)/4, int(sampleRate/1.25); math.Abs(float64(got-want)) > 2 {
+		t.Fatalf("output pairs = %d, want about %d", got, want)
+	}
+	// 1000 Hz sped up by 1.25 over 0.8 s of output still crosses zero 2000 times.
+	if zc := zeroCrossings(out); math.Abs(float64(zc-2000)) > 4 {
+		t.Fatalf("zero crossings = %d, want about 2000 (1250 Hz for 0.8 s)", zc)
+	}
+}
+
+func TestResamplerUnityIsTransparent(t *testing.T) {
+	t.Parallel()
+	in := pcm(sine(440, 10000, 0.1, false))
+	out, err := io.ReadAll(NewResampler(bytes.NewReader(in), func() float64 { return 1 }))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, in) {
+		t.Fatal("ratio 1 changed the samples")
+	}
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestResamplerReadsOnlyWhatItOutputs(t *testing.T) {
+	t.Parallel()
+	in := pcm(sine(440, 10000, 0.2, false))
+	for _, ratio := range []float64{1, 1.25, 0.8} {
+		src := &countingReader{r: bytes.NewReader(in)}
+		r := NewResampler(src, func() float64 { return ratio })
+		out := make([]byte, 3840)
+		for range 5 {
+			if _, err := io.ReadFull(r, out); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// 5 frames of 960 pairs cover 4800*ratio input pairs, plus one held for interpolation.
+		want := int(math.Ceil(4800*ratio)) * 4
+		if src.n < want-4 || src.n > want+4 {
+			t.Fatalf("ratio %v: read %d bytes for 4800 output pairs, want about %d", ratio, src.n, want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package dsp
+
+// VocalCancel removes what is mixed dead centre (usually the lead vocal) by dropping the mid signal
+// (L+R) and keeping the side signal (L-R). Mid content below keepBelow Hz is kept, so bass and kick
+// drum, which are also mixed centre, survive; two low-pass sections keep the crossover steep so little
+// of the vocal range leaks through.
+type VocalCancel struct {
+	low [2]*Biquad
+}
+
+func NewVocalCancel(keepBelow float64) *VocalCancel {
+	return &VocalCancel{low: [2]*Biquad{NewLowPass(keepBelow), NewLowPass(keepBelow)}}
+}
+
+func (v *VocalCancel) Process(buf []float64) {
+	for i := 0; i+1 < len(buf); i += 2 {
+		mid := (buf[i] + buf[i+1]) / 2
+		side := (buf[i] - buf[i+1]) / 2
+		bass := v.low[1].sample(0, v.low[0].sample(0, mid))
+		buf[i] = bass + side
+		buf[i+1] = bass - side
+	}
+}
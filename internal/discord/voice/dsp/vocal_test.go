@@ -0,0 +1,26 @@
+package dsp
+
+import "testing"
+
+func TestVocalCancelRemovesCentreKeepsSideAndBass(t *testing.T) {
+	t.Parallel()
+	centre := sine(1000, 10000, 0.5, false)
+	NewVocalCancel(200).Process(centre)
+	if l := rms(centre, 0); l > 100 {
+		t.Fatalf("centred 1 kHz left at %.0f RMS, want removed", l)
+	}
+
+	side := sine(1000, 10000, 0.5, true)
+	want := rms(side, 0)
+	NewVocalCancel(200).Process(side)
+	if l := rms(side, 0); l < 0.99*want {
+		t.Fatalf("side signal RMS %.0f, want %.0f", l, want)
+	}
+
+	bass := sine(40, 10000, 0.5, false)
+	want = rms(bass, 0)
+	NewVocalCancel(200).Process(bass)
+	if l := rms(bass, 0); l < 0.9*want {
+		t.Fatalf("centred 40 Hz RMS %.0f, want kept near %.0f", l, want)
+	}
+}
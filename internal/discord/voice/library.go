@@ -0,0 +1,160 @@
+package voice
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/resolve"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+)
+
+// ErrLibraryTrackNotFound is returned by FindLibraryTrack when no library file matches the name.
+var ErrLibraryTrackNotFound = errors.New("no library track matches that name")
+
+// libraryMaxFiles bounds a library scan so a misconfigured directory cannot stall a command.
+const libraryMaxFiles = 5000
+
+// LibraryAmbiguousError is returned by FindLibraryTrack when a name matches several files.
+type LibraryAmbiguousError struct {
+	Matches []string // titles, sorted
+}
+
+func (e *LibraryAmbiguousError) Error() string {
+	return fmt.Sprintf("%d library tracks match that name", len(e.Matches))
+}
+
+// newResolver returns the shared resolver with the local source rooted at the library directory,
+// so file:// tracks outside it never resolve.
+func (s *Service) newResolver() *resolve.Resolver {
+	r := resolve.New()
+	if s.cfg != nil && s.cfg.MusicLibraryDir != "" {
+		r.AddSource(local.New(s.cfg.MusicLibraryDir))
+	}
+	if s.cfg != nil && s.cfg.ResolverCacheDir != "" && s.cfg.ResolverCacheTTL > 0 {
+		r.SetCache(resolve.NewFileCache(s.cfg.ResolverCacheDir, s.cfg.ResolverCacheTTL))
+	}
+	return r
+}
+
+// LibraryDir returns the guild's library directory ("" when the library is disabled).
+func (s *Service) LibraryDir(guildID string) string {
+	if s.cfg == nil || s.cfg.MusicLibraryDir == "" || guildID == "" {
+		return ""
+	}
+	return filepath.Join(s.cfg.MusicLibraryDir, guildID)
+}
+
+// LibraryTracks lists the audio files in the guild's library, sorted by relative path. A missing
+// directory is an empty library.
+func (s *Service) LibraryTracks(guildID string) ([]sources.TrackInfo, error) {
+	dir := s.LibraryDir(guildID)
+	if dir == "" {
+		return nil, nil
+	}
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.Type().IsRegular() && local.IsAudioFile(path) {
+			paths = append(paths, path)
+			if len(paths) >= libraryMaxFiles {
+				return fs.SkipAll
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	out := make([]sources.TrackInfo, 0, len(paths))
+	for _, path := range paths {
+		out = append(out, sources.TrackInfo{
+			URL:              local.URI(path),
+			Title:            libraryTitle(dir, path),
+			SourceName:       sources.Local,
+			AvailableParsers: []string{"ffmpeg-file"},
+		})
+	}
+	return out, nil
+}
+
+// libraryTitle is the file's path below dir without its extension, e.g. "scenes/rain".
+func libraryTitle(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	return filepath.ToSlash(strings.TrimSuffix(rel, filepath.Ext(rel)))
+}
+
+// FindLibraryTrack returns the guild library file named name: an exact title (case-insensitive, path
+// below the guild directory without extension) or file name wins, otherwise a single title containing
+// name. It fails with ErrLibraryTrackNotFound or *LibraryAmbiguousError.
+func (s *Service) FindLibraryTrack(guildID, name string) (sources.TrackInfo, error) {
+	tracks, err := s.LibraryTracks(guildID)
+	if err != nil {
+		return sources.TrackInfo{}, err
+	}
+	return matchLibraryTrack(tracks, name)
+}
+
+func matchLibraryTrack(tracks []sources.TrackInfo, name string) (sources.TrackInfo, error) {
+	want := strings.ToLower(strings.TrimSpace(name))
+	if want == "" {
+		return sources.TrackInfo{}, ErrLibraryTrackNotFound
+	}
+
+	var exact, partial []sources.TrackInfo
+	for _, t := range tracks {
+		title := strings.ToLower(t.Title)
+		switch {
+		case title == want, local.Title(title) == want:
+			exact = append(exact, t)
+		case strings.Contains(title, want):
+			partial = append(partial, t)
+		}
+	}
+	for _, candidates := range [][]sources.TrackInfo{exact, partial} {
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			amb := &LibraryAmbiguousError{}
+			for _, t := range candidates {
+				amb.Matches = append(amb.Matches, t.Title)
+			}
+			return sources.TrackInfo{}, amb
+		}
+	}
+	return sources.TrackInfo{}, ErrLibraryTrackNotFound
+}
+
+// checkLocalInput keeps file:// input inside the guild's own library directory.
+func (s *Service) checkLocalInput(guildID, input string) error {
+	path, ok := local.Path(input)
+	if !ok {
+		return nil
+	}
+	dir := s.LibraryDir(guildID)
+	if dir == "" {
+		return errors.New("the music library is disabled")
+	}
+	if _, err := os.Stat(dir); err != nil || !local.Within(dir, path) {
+		return errors.New("file is not in this server's music library")
+	}
+	return nil
+}
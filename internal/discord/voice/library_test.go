@@ -0,0 +1,77 @@
+package voice
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keshon/server-domme/internal/config"
+)
+
+func newLibraryService(t *testing.T, files ...string) (*Service, string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, f := range files {
+		path := filepath.Join(root, "g1", f)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &Service{cfg: &config.Config{MusicLibraryDir: root}}, root
+}
+
+func TestFindLibraryTrack(t *testing.T) {
+	s, _ := newLibraryService(t, "Rain.mp3", "scenes/rain storm.ogg", "scenes/Tavern.flac", "readme.txt")
+
+	tracks, err := s.LibraryTracks("g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 3 {
+		t.Fatalf("want 3 audio files, got %+v", tracks)
+	}
+
+	// Exact title wins over the partial match "scenes/rain storm".
+	if ti, err := s.FindLibraryTrack("g1", "rain"); err != nil || ti.Title != "Rain" {
+		t.Fatalf("rain: %+v, %v", ti, err)
+	}
+	// File name without the folder is an exact match too.
+	if ti, err := s.FindLibraryTrack("g1", "tavern"); err != nil || ti.Title != "scenes/Tavern" {
+		t.Fatalf("tavern: %+v, %v", ti, err)
+	}
+	var amb *LibraryAmbiguousError
+	if _, err := s.FindLibraryTrack("g1", "scenes"); !errors.As(err, &amb) || len(amb.Matches) != 2 {
+		t.Fatalf("scenes: err = %v", err)
+	}
+	if _, err := s.FindLibraryTrack("g1", "ocean"); !errors.Is(err, ErrLibraryTrackNotFound) {
+		t.Fatalf("ocean: err = %v", err)
+	}
+	if tracks, err := s.LibraryTracks("other"); err != nil || len(tracks) != 0 {
+		t.Fatalf("missing guild dir: %+v, %v", tracks, err)
+	}
+}
+
+func TestCheckLocalInputStaysInGuildLibrary(t *testing.T) {
+	s, root := newLibraryService(t, "Rain.mp3")
+	other := filepath.Join(root, "g2", "secret.mp3")
+	if err := os.MkdirAll(filepath.Dir(other), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.checkLocalInput("g1", "file://"+filepath.ToSlash(filepath.Join(root, "g1", "Rain.mp3"))); err != nil {
+		t.Fatalf("own library: %v", err)
+	}
+	if err := s.checkLocalInput("g1", "file://"+filepath.ToSlash(other)); err == nil {
+		t.Fatal("another guild's library must be rejected")
+	}
+	if err := s.checkLocalInput("g1", "https://example.com/a.mp3"); err != nil {
+		t.Fatalf("non-file input: %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package voice
+
+import "github.com/keshon/server-domme/internal/discord/voice/sink"
+
+// VoiceMetrics are a guild's voice transport counters (see sink.VoiceMetrics).
+type VoiceMetrics = sink.VoiceMetrics
+
+// VoiceMetrics returns the guild's voice transport counters and the voice channel the bot is in ("" when
+// not connected). ok is false when the guild has not used voice since the bot started.
+func (s *Service) VoiceMetrics(guildID string) (m VoiceMetrics, channelID string, ok bool) {
+	s.mu.RLock()
+	provider := s.sinkProviders[guildID]
+	s.mu.RUnlock()
+	if provider == nil {
+		return VoiceMetrics{}, "", false
+	}
+	return provider.Metrics(), provider.ChannelID(), true
+}
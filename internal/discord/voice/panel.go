@@ -0,0 +1,153 @@
+package voice
+
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+)
+
+// PanelState is the guild state kept by the service rather than the player that the panel shows.
+type PanelState struct {
+	SkipVotes       int // votes to skip the current track so far
+	SkipVotesNeeded int // votes that pass the skip; 0 while nobody has voted
+}
+
+// PanelRenderer builds the now-playing panel (embed and control buttons) for a player.
+// It is supplied by the command layer, which owns the button custom IDs and their handlers.
+type PanelRenderer func(p *player.Player, st PanelState) (*discordgo.MessageEmbed, []discordgo.MessageComponent)
+
+// panelRefreshInterval is how often the panel is re-rendered while a track plays (elapsed time).
+const panelRefreshInterval = 15 * time.Second
+
+// panelDebounce coalesces bursts of player events (e.g. Stopped+Playing on skip) into one edit.
+const panelDebounce = 500 * time.Millisecond
+
+// SetPanelRenderer sets the renderer used for the guild now-playing panels.
+func (s *Service) SetPanelRenderer(r PanelRenderer) {
+	s.guildMusicStatusMu.Lock()
+	s.panelRenderer = r
+	s.guildMusicStatusMu.Unlock()
+}
+
+func (s *Service) renderPanel(guildID string, p *player.Player) (*discordgo.MessageEmbed, []discordgo.MessageComponent, bool) {
+	s.guildMusicStatusMu.RLock()
+	r := s.panelRenderer
+	s.guildMusicStatusMu.RUnlock()
+	if r == nil {
+		return nil, nil, false
+	}
+	embed, components := r(p, s.PanelState(guildID))
+	return embed, components, embed != nil
+}
+
+// startPanelWatcher follows the player's status events for as long as the player exists and keeps the
+// guild panel and the saved playback session current. Caller must hold s.mu.
+func (s *Service) startPanelWatcher(guildID string, p *player.Player) {
+	if s.panelStops == nil {
+		s.panelStops = make(map[string]chan struct{})
+	}
+	stop := make(chan struct{})
+	s.panelStops[guildID] = stop
+	go s.watchPanel(guildID, p, stop)
+}
+
+func (s *Service) watchPanel(guildID string, p *player.Player, stop <-chan struct{}) {
+	ticker := time.NewTicker(panelRefreshInterval)
+	defer ticker.Stop()
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-stop:
+			return
+		case _, ok := <-p.PlayerStatus:
+			if !ok {
+				return
+			}
+			if debounce == nil {
+				debounce = time.After(panelDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			s.refreshPanel(guildID, p)
+			s.snapshotSession(guildID, p)
+		case <-ticker.C:
+			if p.IsPlaying() && !p.IsPaused() {
+				s.refreshPanel(guildID, p)
+				s.snapshotSession(guildID, p)
+			}
+		}
+	}
+}
+
+// refreshPanel edits the guild's panel message in place. It never posts a new message.
+func (s *Service) refreshPanel(guildID string, p *player.Player) {
+	s.guildMusicStatusMu.RLock()
+	msg, ok := s.guildMusicStatus[guildID]
+	s.guildMusicStatusMu.RUnlock()
+	if !ok {
+		return
+	}
+	embed, components, ok := s.renderPanel(guildID, p)
+	if !ok {
+		return
+	}
+	session := s.getSession()
+	if session == nil {
+		return
+	}
+	if _, err := session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		Channel:    msg.ChannelID,
+		ID:         msg.MessageID,
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	}); err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("panel_refresh_failed")
+	}
+}
+
+// ShowPlaybackPanel renders the guild's panel. The existing panel message is edited in place; when there is
+// none, the edit fails (message deleted) or repost is true, the panel is posted as a followup to i and becomes
+// the guild's panel. posted reports whether the followup was used, i.e. whether i has been answered.
+func (s *Service) ShowPlaybackPanel(session *discordgo.Session, i *discordgo.InteractionCreate, guildID string, repost bool) (bool, error) {
+	p := s.GetOrCreatePlayer(guildID)
+	embed, components, ok := s.renderPanel(guildID, p)
+	if !ok {
+		return false, nil
+	}
+
+	s.guildMusicStatusMu.RLock()
+	msg, exists := s.guildMusicStatus[guildID]
+	s.guildMusicStatusMu.RUnlock()
+
+	if exists && !repost {
+		_, err := session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			Channel:    msg.ChannelID,
+			ID:         msg.MessageID,
+			Embeds:     &[]*discordgo.MessageEmbed{embed},
+			Components: &components,
+		})
+		if err == nil {
+			return false, nil
+		}
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("panel_edit_failed_reposting")
+	}
+
+	if i == nil {
+		return false, nil
+	}
+	m, err := session.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	if err != nil {
+		return false, err
+	}
+	if m != nil {
+		s.guildMusicStatusMu.Lock()
+		s.guildMusicStatus[guildID] = guildMusicStatus{ChannelID: m.ChannelID, MessageID: m.ID}
+		s.guildMusicStatusMu.Unlock()
+	}
+	return true, nil
+}
@@ -0,0 +1,220 @@
+package voice
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/keshon/server-domme/internal/discord/voice/sink"
+)
+
+var (
+	// ErrRecordingActive is returned by StartRecording when the guild is already being recorded.
+	ErrRecordingActive = errors.New("a recording is already running in this server")
+	// ErrNoRecording is returned when the guild has no recording (or not the one asked for).
+	ErrNoRecording = errors.New("no recording is running")
+	// ErrRecordingOtherChannel is returned by StartRecording when the bot is in another voice channel.
+	ErrRecordingOtherChannel = errors.New("the bot is in another voice channel")
+)
+
+// recording is a guild's running (or ended, not yet collected) recording.
+type recording struct {
+	id            string
+	voiceChannel  string
+	noticeChannel string
+	startedBy     string
+	started       time.Time
+	rec           *sink.Recorder
+	stop          chan struct{}
+	done          chan struct{}
+
+	mu          sync.Mutex
+	consent     map[string]bool // users who answered the consent prompt
+	interrupted bool
+	err         error
+	ended       time.Time
+}
+
+func (r *recording) allowed(userID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.consent[userID]
+}
+
+// RecordingInfo describes a guild's recording.
+type RecordingInfo struct {
+	ID             string
+	VoiceChannelID string
+	StartedBy      string
+	Started        time.Time
+	// Accepted and Declined list the users who answered the consent prompt, in no particular order.
+	Accepted []string
+	Declined []string
+}
+
+// RecordingResult describes a finished recording.
+type RecordingResult struct {
+	RecordingInfo
+	Dir string
+	// MixFile is the mixed track and UserFiles each recorded user's track, by user ID.
+	MixFile   string
+	UserFiles map[string]string
+	Duration  time.Duration
+	// Interrupted is set when the bot left or lost the voice channel before the recording was stopped.
+	Interrupted bool
+}
+
+// RecordingsDir returns the directory holding the guild's recordings ("" when recording is disabled).
+func (s *Service) RecordingsDir(guildID string) string {
+	if s.cfg == nil || s.cfg.RecordingsDir == "" || guildID == "" {
+		return ""
+	}
+	return filepath.Join(s.cfg.RecordingsDir, guildID)
+}
+
+// StartRecording joins channelID (or keeps the bot there) and records it into a new directory under
+// RecordingsDir. Only users who accept the consent prompt (see SetRecordingConsent) are captured;
+// startedBy is counted as accepted. noticeChannelID gets a notice if the recording is interrupted.
+func (s *Service) StartRecording(guildID, channelID, noticeChannelID, startedBy string) (RecordingInfo, error) {
+	base := s.RecordingsDir(guildID)
+	if base == "" {
+		return RecordingInfo{}, errors.New("recording is disabled")
+	}
+	provider := s.sinkProvider(guildID)
+	if provider == nil {
+		return RecordingInfo{}, errors.New("voice service not available")
+	}
+	if ch := provider.ChannelID(); ch != "" && ch != channelID {
+		return RecordingInfo{}, ErrRecordingOtherChannel
+	}
+
+	s.recordingsMu.Lock()
+	defer s.recordingsMu.Unlock()
+	if s.recordings == nil {
+		s.recordings = make(map[string]*recording)
+	}
+	if _, ok := s.recordings[guildID]; ok {
+		return RecordingInfo{}, ErrRecordingActive
+	}
+
+	now := time.Now()
+	r := &recording{
+		id:            strconv.FormatInt(now.Unix(), 10),
+		voiceChannel:  channelID,
+		noticeChannel: noticeChannelID,
+		startedBy:     startedBy,
+		started:       now,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		consent:       map[string]bool{startedBy: true},
+	}
+	rec, err := sink.NewRecorder(filepath.Join(base, now.Format("20060102-150405")), r.allowed)
+	if err != nil {
+		return RecordingInfo{}, fmt.Errorf("failed to create recording: %w", err)
+	}
+	r.rec = rec
+	vc, err := provider.Receive(channelID)
+	if err != nil {
+		_ = rec.Close(now)
+		return RecordingInfo{}, err
+	}
+	s.recordings[guildID] = r
+	s.log.Info().Str("guild_id", guildID).Str("channel_id", channelID).Str("dir", rec.Dir()).Msg("recording_started")
+
+	go func() {
+		defer close(r.done)
+		interrupted, err := rec.Run(vc, r.stop)
+		provider.StopReceive()
+		r.mu.Lock()
+		r.interrupted, r.err, r.ended = interrupted, err, time.Now()
+		r.mu.Unlock()
+		if err != nil {
+			s.log.Warn().Str("guild_id", guildID).Err(err).Msg("recording_failed")
+		}
+		if interrupted {
+			s.log.Info().Str("guild_id", guildID).Msg("recording_interrupted")
+			s.postChannelNotice(guildID, r.noticeChannel, nil,
+				"⏺ The recording stopped because I left the voice channel. Use `/record stop` to get the files.")
+		}
+	}()
+	return r.info(), nil
+}
+
+func (r *recording) info() RecordingInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info := RecordingInfo{ID: r.id, VoiceChannelID: r.voiceChannel, StartedBy: r.startedBy, Started: r.started}
+	for userID, ok := range r.consent {
+		if ok {
+			info.Accepted = append(info.Accepted, userID)
+		} else {
+			info.Declined = append(info.Declined, userID)
+		}
+	}
+	return info
+}
+
+// Recording returns the guild's recording, if any.
+func (s *Service) Recording(guildID string) (RecordingInfo, bool) {
+	s.recordingsMu.Lock()
+	r, ok := s.recordings[guildID]
+	s.recordingsMu.Unlock()
+	if !ok {
+		return RecordingInfo{}, false
+	}
+	return r.info(), true
+}
+
+func (s *Service) isRecording(guildID string) bool {
+	_, ok := s.Recording(guildID)
+	return ok
+}
+
+// SetRecordingConsent records userID's answer to the consent prompt of recording id. Accepting starts
+// capturing the user from their next packet; declining (also after accepting) stops it.
+func (s *Service) SetRecordingConsent(guildID, id, userID string, accepted bool) (RecordingInfo, error) {
+	s.recordingsMu.Lock()
+	r, ok := s.recordings[guildID]
+	s.recordingsMu.Unlock()
+	if !ok || r.id != id {
+		return RecordingInfo{}, ErrNoRecording
+	}
+	r.mu.Lock()
+	r.consent[userID] = accepted
+	r.mu.Unlock()
+	return r.info(), nil
+}
+
+// StopRecording ends the guild's recording, closes its files and deafens the bot again.
+func (s *Service) StopRecording(guildID string) (*RecordingResult, error) {
+	s.recordingsMu.Lock()
+	r, ok := s.recordings[guildID]
+	if ok {
+		delete(s.recordings, guildID)
+	}
+	s.recordingsMu.Unlock()
+	if !ok {
+		return nil, ErrNoRecording
+	}
+	close(r.stop)
+	<-r.done
+
+	res := &RecordingResult{
+		RecordingInfo: r.info(),
+		Dir:           r.rec.Dir(),
+		MixFile:       filepath.Join(r.rec.Dir(), sink.RecordingMixFile),
+		UserFiles:     make(map[string]string),
+	}
+	for _, userID := range r.rec.Users() {
+		res.UserFiles[userID] = filepath.Join(r.rec.Dir(), userID+".ogg")
+	}
+	r.mu.Lock()
+	res.Duration, res.Interrupted = r.ended.Sub(r.started), r.interrupted
+	err := r.err
+	r.mu.Unlock()
+	s.log.Info().Str("guild_id", guildID).Str("dir", res.Dir).Dur("duration", res.Duration).Int("users", len(res.UserFiles)).Msg("recording_stopped")
+	return res, err
+}
@@ -1,6 +1,7 @@
 package voice
 
 import (
+	"math/rand/v2"
 	"sync"
 	"time"
 
@@ -10,7 +11,9 @@ import (
 	"github.com/keshon/melodix/pkg/music/resolve"
 	"github.com/keshon/melodix/pkg/music/sources"
 	"github.com/keshon/server-domme/internal/config"
+	"github.com/keshon/server-domme/internal/discord/voice/dsp"
 	"github.com/keshon/server-domme/internal/discord/voice/sink"
+	"github.com/keshon/server-domme/internal/domain"
 	"github.com/keshon/server-domme/internal/storage"
 	"github.com/rs/zerolog"
 )
@@ -37,14 +40,24 @@ type Service struct {
 	players       map[string]*player.Player
 	sinkProviders map[string]*sink.DiscordSinkProvider
 	resolver      *resolve.Resolver
+	streams       *sink.StreamServer // nil unless cfg.AudioStreamAddr is set
 
 	guildMusicStatus   map[string]guildMusicStatus
 	guildMusicStatusMu sync.RWMutex
+	panelRenderer      PanelRenderer // guarded by guildMusicStatusMu
+	panelStops         map[string]chan struct{}
+
+	votesMu   sync.Mutex
+	skipVotes map[string]*skipVote
+
+	// recordingsMu is held across a recording's start, so two starts in one guild cannot race.
+	recordingsMu sync.Mutex
+	recordings   map[string]*recording
 }
 
 // New creates a voice service for the given session getter and config.
 func NewVoiceService(getSession SessionGetter, cfg *config.Config, store *storage.Storage, log zerolog.Logger) *Service {
-	return &Service{
+	s := &Service{
 		getSession:       getSession,
 		cfg:              cfg,
 		store:            store,
@@ -53,6 +66,10 @@ func NewVoiceService(getSession SessionGetter, cfg *config.Config, store *storag
 		sinkProviders:    make(map[string]*sink.DiscordSinkProvider),
 		guildMusicStatus: make(map[string]guildMusicStatus),
 	}
+	if cfg != nil && cfg.AudioStreamAddr != "" {
+		s.streams = sink.NewStreamServer(log)
+	}
+	return s
 }
 
 type playbackRecorder struct {
@@ -60,15 +77,39 @@ type playbackRecorder struct {
 	log   zerolog.Logger
 }
 
-func (r playbackRecorder) Record(guildID string, playedAt time.Time, track parsers.TrackParse) {
+func (r playbackRecorder) Record(guildID string, play player.PlaybackRecord) {
 	if r.store == nil {
 		return
 	}
-	if _, err := r.store.AppendMusicPlayback(guildID, track, playedAt); err != nil {
+	end := storage.MusicPlaybackEnd{
+		ChannelID: play.ChannelID,
+		Listened:  play.Listened,
+		Reason:    string(play.EndReason),
+	}
+	if _, err := r.store.AppendMusicPlayback(guildID, play.Track, play.StartedAt, end); err != nil {
 		r.log.Warn().Str("guild_id", guildID).Err(err).Msg("playback_history_append_failed")
 	}
 }
 
+// historyAutoplay feeds player.LoopAutoplay from the guild's persisted playback history.
+type historyAutoplay struct {
+	store *storage.Storage
+	log   zerolog.Logger
+}
+
+func (a historyAutoplay) NextAutoplay(guildID string, last parsers.TrackParse) (sources.TrackInfo, bool) {
+	rows, err := a.store.ListMusicPlaybackTimeline(guildID)
+	if err != nil {
+		a.log.Warn().Str("guild_id", guildID).Err(err).Msg("autoplay_history_load_failed")
+		return sources.TrackInfo{}, false
+	}
+	row, ok := domain.PickAutoplay(rows, last.URL, domain.AutoplayRecentWindow, rand.IntN)
+	if !ok {
+		return sources.TrackInfo{}, false
+	}
+	return storage.TrackInfoFromMusicPlayback(row), true
+}
+
 // GetOrCreatePlayer returns an existing player for the guild or creates a new one.
 func (s *Service) GetOrCreatePlayer(guildID string) *player.Player {
 	s.mu.Lock()
@@ -85,36 +126,285 @@ func (s *Service) GetOrCreatePlayer(guildID string) *player.Player {
 		return p
 	}
 	if s.resolver == nil {
-		s.resolver = resolve.New()
+		s.resolver = s.newResolver()
 	}
 	provider, ok := s.sinkProviders[guildID]
 	if !ok {
 		voiceDelay := time.Duration(s.cfg.VoiceReadyDelayMs) * time.Millisecond
 		provider = sink.NewDiscordSinkProvider(s.getSession, guildID, voiceDelay, s.log)
+		s.applyStoredAudioLevels(guildID, provider)
+		if s.streams != nil {
+			provider.SetMonitor(s.streams.Publisher(guildID))
+		}
 		s.sinkProviders[guildID] = provider
 	}
 	p := player.NewWithOptions(provider, s.resolver, player.Options{
 		Logger:                s.log,
 		TransportRecoveryMode: s.cfg.PlayerTransportRecoveryMode,
 		TransportSoftAttempts: s.cfg.PlayerTransportSoftAttempts,
+		LocalRoot:             s.LibraryDir(guildID),
 	})
 	p.SetGuildID(guildID)
 	if s.store != nil {
 		p.SetRecorder(playbackRecorder{store: s.store, log: s.log})
+		p.SetAutoplaySource(historyAutoplay{store: s.store, log: s.log})
+		s.applyStoredLoopMode(guildID, p)
+		s.applyStoredTransitions(guildID, p)
 	}
 	s.players[guildID] = p
+	s.startPanelWatcher(guildID, p)
 	return p
 }
 
+func (s *Service) applyStoredLoopMode(guildID string, p *player.Player) {
+	stored, err := s.store.MusicLoopMode(guildID)
+	if err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("loop_mode_load_failed")
+		return
+	}
+	mode, err := player.ParseLoopMode(stored)
+	if err != nil {
+		s.log.Warn().Str("guild_id", guildID).Str("mode", stored).Err(err).Msg("loop_mode_invalid")
+		return
+	}
+	p.SetLoopMode(mode)
+}
+
+// SetLoopMode applies the loop mode to the guild's player and persists it in the guild record.
+func (s *Service) SetLoopMode(guildID string, mode player.LoopMode) error {
+	if p := s.GetOrCreatePlayer(guildID); p != nil {
+		p.SetLoopMode(mode)
+	}
+	if s.store == nil {
+		return nil
+	}
+	return s.store.SetMusicLoopMode(guildID, string(mode))
+}
+
+// LoopMode returns the guild's current loop mode.
+func (s *Service) LoopMode(guildID string) player.LoopMode {
+	return s.GetOrCreatePlayer(guildID).LoopMode()
+}
+
+func (s *Service) applyStoredAudioLevels(guildID string, provider *sink.DiscordSinkProvider) {
+	if s.store == nil {
+		return
+	}
+	if vol, err := s.store.MusicVolume(guildID); err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("volume_load_failed")
+	} else {
+		provider.SetVolume(vol)
+	}
+	if on, err := s.store.MusicNormalize(guildID); err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("normalize_load_failed")
+	} else {
+		provider.SetNormalize(on)
+	}
+	stored, err := s.store.MusicFilter(guildID)
+	if err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("filter_load_failed")
+		return
+	}
+	preset, err := dsp.ParsePreset(stored)
+	if err != nil {
+		s.log.Warn().Str("guild_id", guildID).Str("preset", stored).Err(err).Msg("filter_invalid")
+		return
+	}
+	provider.SetFilter(preset)
+}
+
+// sinkProvider returns the guild's sink provider, creating the player (and provider) if needed.
+func (s *Service) sinkProvider(guildID string) *sink.DiscordSinkProvider {
+	s.GetOrCreatePlayer(guildID)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sinkProviders[guildID]
+}
+
+// SetVolume applies the volume (percent, clamped to 0..domain.MaxMusicVolume) live and persists it as the guild default.
+// It returns the value actually applied.
+func (s *Service) SetVolume(guildID string, pct int) (int, error) {
+	pct = domain.ClampMusicVolume(pct)
+	if p := s.sinkProvider(guildID); p != nil {
+		p.SetVolume(pct)
+	}
+	if s.store == nil {
+		return pct, nil
+	}
+	return pct, s.store.SetMusicVolume(guildID, pct)
+}
+
+// Volume returns the guild's current volume in percent.
+func (s *Service) Volume(guildID string) int {
+	if p := s.sinkProvider(guildID); p != nil {
+		return p.Volume()
+	}
+	return domain.DefaultMusicVolume
+}
+
+// SetNormalize toggles loudness normalisation live and persists the choice.
+func (s *Service) SetNormalize(guildID string, on bool) error {
+	if p := s.sinkProvider(guildID); p != nil {
+		p.SetNormalize(on)
+	}
+	if s.store == nil {
+		return nil
+	}
+	return s.store.SetMusicNormalize(guildID, on)
+}
+
+// Normalize reports whether loudness normalisation is enabled for the guild.
+func (s *Service) Normalize(guildID string) bool {
+	if p := s.sinkProvider(guildID); p != nil {
+		return p.Normalize()
+	}
+	return false
+}
+
+// SetFilter switches the guild's audio filter preset live and persists it.
+func (s *Service) SetFilter(guildID string, preset dsp.Preset) error {
+	if p := s.sinkProvider(guildID); p != nil {
+		p.SetFilter(preset)
+	}
+	if s.store == nil {
+		return nil
+	}
+	return s.store.SetMusicFilter(guildID, string(preset))
+}
+
+// Filter returns the guild's current audio filter preset.
+func (s *Service) Filter(guildID string) dsp.Preset {
+	if p := s.sinkProvider(guildID); p != nil {
+		return p.Filter()
+	}
+	return dsp.PresetOff
+}
+
+func (s *Service) applyStoredTransitions(guildID string, p *player.Player) {
+	sec, err := s.store.MusicCrossfade(guildID)
+	if err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("crossfade_load_failed")
+	}
+	gapless, err := s.store.MusicGapless(guildID)
+	if err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("gapless_load_failed")
+	}
+	p.SetTransitions(time.Duration(sec)*time.Second, gapless)
+}
+
+// SetCrossfade applies the crossfade between tracks (seconds, clamped to 0..domain.MaxMusicCrossfadeSeconds) and
+// persists it. It returns the value actually applied.
+func (s *Service) SetCrossfade(guildID string, sec int) (int, error) {
+	sec = domain.ClampMusicCrossfadeSeconds(sec)
+	if p := s.GetOrCreatePlayer(guildID); p != nil {
+		_, gapless := p.Transitions()
+		p.SetTransitions(time.Duration(sec)*time.Second, gapless)
+	}
+	if s.store == nil {
+		return sec, nil
+	}
+	return sec, s.store.SetMusicCrossfade(guildID, sec)
+}
+
+// Crossfade returns the guild's crossfade between tracks in seconds (0 when off).
+func (s *Service) Crossfade(guildID string) int {
+	crossfade, _ := s.GetOrCreatePlayer(guildID).Transitions()
+	return int(crossfade / time.Second)
+}
+
+// SetGapless toggles gapless transitions live and persists the choice.
+func (s *Service) SetGapless(guildID string, on bool) error {
+	if p := s.GetOrCreatePlayer(guildID); p != nil {
+		crossfade, _ := p.Transitions()
+		p.SetTransitions(crossfade, on)
+	}
+	if s.store == nil {
+		return nil
+	}
+	return s.store.SetMusicGapless(guildID, on)
+}
+
+// Gapless reports whether gapless transitions are enabled for the guild.
+func (s *Service) Gapless(guildID string) bool {
+	_, gapless := s.GetOrCreatePlayer(guildID).Transitions()
+	return gapless
+}
+
 // ResolveTracks resolves input to tracks using the service's shared resolver.
 func (s *Service) ResolveTracks(guildID, input, source, parser string) ([]sources.TrackInfo, error) {
+	r := s.sharedResolver()
+	if err := s.checkLocalInput(guildID, input); err != nil {
+		return nil, err
+	}
+	return r.Resolve(input, source, parser)
+}
+
+// SearchTracks returns up to limit matches for a title query, best first, using the shared resolver.
+func (s *Service) SearchTracks(guildID, query, source, parser string, limit int) ([]sources.TrackInfo, error) {
+	r := s.sharedResolver()
+	if err := s.checkLocalInput(guildID, query); err != nil {
+		return nil, err
+	}
+	return r.Search(query, source, parser, limit)
+}
+
+func (s *Service) sharedResolver() *resolve.Resolver {
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.resolver == nil {
-		s.resolver = resolve.New()
+		s.resolver = s.newResolver()
+	}
+	return s.resolver
+}
+
+// ExistingPlayer returns the guild's player without creating one (nil if none).
+func (s *Service) ExistingPlayer(guildID string) *player.Player {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.players[guildID]
+}
+
+// Queue returns a copy of the guild's queue (empty when no player exists yet).
+func (s *Service) Queue(guildID string) []parsers.TrackParse {
+	p := s.ExistingPlayer(guildID)
+	if p == nil {
+		return nil
+	}
+	return p.Queue()
+}
+
+// QueueRemove removes the track at the 1-based queue position and returns it.
+func (s *Service) QueueRemove(guildID string, pos int) (parsers.TrackParse, error) {
+	p := s.ExistingPlayer(guildID)
+	if p == nil {
+		return parsers.TrackParse{}, player.ErrNoTracksInQueue
+	}
+	return p.RemoveFromQueue(pos - 1)
+}
+
+// QueueMove moves the track at 1-based position from to 1-based position to.
+func (s *Service) QueueMove(guildID string, from, to int) error {
+	p := s.ExistingPlayer(guildID)
+	if p == nil {
+		return player.ErrNoTracksInQueue
+	}
+	return p.MoveInQueue(from-1, to-1)
+}
+
+// QueueClear removes all queued tracks and returns how many were dropped.
+func (s *Service) QueueClear(guildID string) int {
+	p := s.ExistingPlayer(guildID)
+	if p == nil {
+		return 0
+	}
+	return p.ClearQueue()
+}
+
+// QueueShuffle randomizes the guild's queue order.
+func (s *Service) QueueShuffle(guildID string) {
+	if p := s.ExistingPlayer(guildID); p != nil {
+		p.ShuffleQueue()
 	}
-	r := s.resolver
-	s.mu.Unlock()
-	return r.Resolve(input, source, parser)
 }
 
 // UpdatePlaybackStatus creates or edits the guild's music status message.
@@ -157,8 +447,16 @@ func (s *Service) StopAllPlayers() {
 	}
 	s.players = make(map[string]*player.Player)
 	s.sinkProviders = nil // reinitialized on next GetOrCreatePlayer if needed
+	for _, stop := range s.panelStops {
+		close(stop)
+	}
+	s.panelStops = nil
 	s.mu.Unlock()
 
+	// Snapshot before stopping: Stop(true) clears the queue.
+	for guildID, p := range players {
+		s.snapshotSession(guildID, p)
+	}
 	for _, p := range players {
 		_ = p.Stop(true)
 	}
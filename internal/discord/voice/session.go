@@ -0,0 +1,154 @@
+package voice
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/discord/discordreply"
+	"github.com/keshon/server-domme/internal/domain"
+	"github.com/keshon/server-domme/internal/storage"
+)
+
+// Resume modes for sessions saved before a restart (config MUSIC_RESUME_MODE).
+const (
+	ResumeModeAuto  = "auto"
+	ResumeModeOffer = "offer"
+	ResumeModeOff   = "off"
+)
+
+var (
+	// ErrNoSavedSession is returned by RestoreSession when the guild has nothing to restore.
+	ErrNoSavedSession = errors.New("no saved playback session")
+	// ErrPlayerBusy is returned by RestoreSession when the guild's player is already playing.
+	ErrPlayerBusy = errors.New("player is already playing")
+)
+
+// snapshotSession persists the player's current track, position and queue, or clears the snapshot
+// once there is nothing left to restore.
+func (s *Service) snapshotSession(guildID string, p *player.Player) {
+	if s.store == nil {
+		return
+	}
+	current := p.CurrentTrack()
+	queue := p.Queue()
+	if current == nil && len(queue) == 0 {
+		if err := s.store.ClearMusicSession(guildID); err != nil {
+			s.log.Warn().Str("guild_id", guildID).Err(err).Msg("music_session_clear_failed")
+		}
+		return
+	}
+
+	s.guildMusicStatusMu.RLock()
+	statusChannelID := s.guildMusicStatus[guildID].ChannelID
+	s.guildMusicStatusMu.RUnlock()
+
+	sess := storage.NewMusicSession(p.ChannelID(), statusChannelID, current, p.Position(), queue, time.Now())
+	if err := s.store.SetMusicSession(guildID, sess); err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("music_session_save_failed")
+	}
+}
+
+// SavedSession returns the guild's playback snapshot, or nil when there is nothing to restore.
+func (s *Service) SavedSession(guildID string) *domain.MusicSession {
+	if s.store == nil {
+		return nil
+	}
+	sess, err := s.store.MusicSession(guildID)
+	if err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("music_session_load_failed")
+		return nil
+	}
+	if sess == nil || sess.TrackCount() == 0 || sess.VoiceChannelID == "" {
+		return nil
+	}
+	return sess
+}
+
+// RestoreSession re-queues the guild's saved tracks and continues the current one at its saved position
+// in the saved voice channel. It returns the number of tracks restored.
+func (s *Service) RestoreSession(guildID string) (int, error) {
+	sess := s.SavedSession(guildID)
+	if sess == nil {
+		return 0, ErrNoSavedSession
+	}
+	p := s.GetOrCreatePlayer(guildID)
+	if p.IsPlaying() {
+		return 0, ErrPlayerBusy
+	}
+
+	rows := sess.Queue
+	if sess.Current != nil {
+		rows = append([]domain.MusicPlayback{*sess.Current}, rows...)
+	}
+	restored := 0
+	for _, row := range rows {
+		if err := p.EnqueueTrackInfoFor(storage.TrackInfoFromMusicPlayback(row), row.RequestedBy); err != nil {
+			s.log.Warn().Str("guild_id", guildID).Str("url", row.URL).Err(err).Msg("music_session_track_skipped")
+			continue
+		}
+		restored++
+	}
+
+	offset := time.Duration(0)
+	if sess.Current != nil {
+		offset = sess.Position()
+	}
+	if err := p.PlayNextAt(sess.VoiceChannelID, offset); err != nil {
+		return restored, fmt.Errorf("restore playback: %w", err)
+	}
+	s.log.Info().Str("guild_id", guildID).Int("tracks", restored).Dur("offset", offset).Msg("music_session_restored")
+	return restored, nil
+}
+
+// ResumeSavedSessions handles the sessions left by the previous run: restored right away in ResumeModeAuto,
+// announced with a pointer to /resume in ResumeModeOffer, ignored in ResumeModeOff. Guilds that are
+// already playing (e.g. after a gateway reconnect) are skipped.
+func (s *Service) ResumeSavedSessions(guildIDs []string, mode string) {
+	if mode == ResumeModeOff {
+		return
+	}
+	for _, guildID := range guildIDs {
+		sess := s.SavedSession(guildID)
+		if sess == nil {
+			continue
+		}
+		if p := s.ExistingPlayer(guildID); p != nil && p.IsPlaying() {
+			continue
+		}
+
+		var notice string
+		if mode == ResumeModeAuto {
+			n, err := s.RestoreSession(guildID)
+			if err != nil {
+				s.log.Warn().Str("guild_id", guildID).Err(err).Msg("music_session_restore_failed")
+				continue
+			}
+			notice = fmt.Sprintf("▶️ Picked up where I left off before the restart: %d track(s) restored.", n)
+		} else {
+			notice = fmt.Sprintf("⏯ Playback was interrupted by a restart (%d track(s)). Use `/resume` to continue where it left off.", sess.TrackCount())
+		}
+		if sess.StatusChannelID != "" {
+			s.postChannelNotice(guildID, sess.StatusChannelID, nil, notice)
+		}
+	}
+}
+
+// postChannelNotice sends a short notice embed to channelID, as a reply to ref when set.
+func (s *Service) postChannelNotice(guildID, channelID string, ref *discordgo.MessageReference, notice string) {
+	session := s.getSession()
+	if session == nil {
+		return
+	}
+	if _, err := session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{{
+			Description: notice,
+			Color:       discordreply.EmbedColor,
+		}},
+		Reference: ref,
+	}); err != nil {
+		s.log.Warn().Str("guild_id", guildID).Err(err).Msg("status_notice_failed")
+	}
+}
@@ -0,0 +1,191 @@
+package sink
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/godeps/opus"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// clipDuckGain is the music level while a soundboard clip plays (about -9 dB).
+const clipDuckGain = 0.35
+
+// Ducking moves per 20 ms frame: down within 100 ms so the clip's attack stands out, back up over 300 ms.
+const (
+	clipDuckStep    = (1 - clipDuckGain) / 5
+	clipReleaseStep = (1 - clipDuckGain) / 15
+)
+
+// musicFlowGap is how recently a music sink must have mixed a frame to count as playing. A paused
+// player blocks its sink, so clips are then sent on their own.
+const musicFlowGap = 100 * time.Millisecond
+
+// clipMixer plays soundboard clips over one guild's voice connection. While music streams, its sink mixes
+// the clips into each frame and ducks the music under them; otherwise a pump sends the clips on their own.
+type clipMixer struct {
+	mu        sync.Mutex
+	clips     [][]int16 // remaining interleaved samples of each playing clip
+	ducked    float64   // how far the music is currently ducked, 0..1-clipDuckGain
+	lastMusic time.Time // last frame a music sink mixed
+	pumping   bool
+}
+
+// add starts playing pcm (48kHz stereo samples) with the next frame.
+func (m *clipMixer) add(pcm []int16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clips = append(m.clips, pcm)
+}
+
+func (m *clipMixer) musicFlowingLocked(now time.Time) bool {
+	return now.Sub(m.lastMusic) < musicFlowGap
+}
+
+// mixMusic ducks the music frame buf and mixes the playing clips into it at gain. Music sinks call it
+// for every frame they send.
+func (m *clipMixer) mixMusic(buf []int16, gain float64, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastMusic = now
+
+	from := 1 - m.ducked
+	if len(m.clips) > 0 {
+		m.ducked = min(m.ducked+clipDuckStep, 1-clipDuckGain)
+	} else {
+		m.ducked = max(m.ducked-clipReleaseStep, 0)
+	}
+	to := 1 - m.ducked
+	if from != 1 || to != 1 {
+		// Ramp across the frame so gain steps do not click.
+		frames := len(buf) / stream.Channels
+		for i := range buf {
+			g := from + (to-from)*float64(i/stream.Channels+1)/float64(frames)
+			buf[i] = clampSample(float64(buf[i]) * g)
+		}
+	}
+	m.mixClipsLocked(buf, gain)
+}
+
+// startPump claims the standalone pump. It returns false when music is playing (its sink mixes the clips)
+// or a pump already runs.
+func (m *clipMixer) startPump(now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.pumping || m.musicFlowingLocked(now) {
+		return false
+	}
+	m.pumping = true
+	return true
+}
+
+// pumpFrame mixes the playing clips into the silent frame buf at gain for the pump. It returns false (and
+// releases the pump) once no clip is left or music started, whose sink then mixes what remains.
+func (m *clipMixer) pumpFrame(buf []int16, gain float64, now time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.clips) == 0 || m.musicFlowingLocked(now) {
+		m.pumping = false
+		return false
+	}
+	m.mixClipsLocked(buf, gain)
+	return true
+}
+
+// abortPump releases the pump after a send failure and drops the clips it was playing.
+func (m *clipMixer) abortPump() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pumping = false
+	m.clips = nil
+}
+
+func (m *clipMixer) mixClipsLocked(buf []int16, gain float64) {
+	kept := m.clips[:0]
+	for _, clip := range m.clips {
+		n := min(len(clip), len(buf))
+		for i := range n {
+			buf[i] = clampSample(float64(buf[i]) + float64(clip[i])*gain)
+		}
+		if clip = clip[n:]; len(clip) > 0 {
+			kept = append(kept, clip)
+		}
+	}
+	clear(m.clips[len(kept):])
+	m.clips = kept
+}
+
+// clampSample rounds v to an int16 sample, saturating at the range.
+func clampSample(v float64) int16 {
+	v = math.Round(v)
+	switch {
+	case v > math.MaxInt16:
+		return math.MaxInt16
+	case v < math.MinInt16:
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+// PlayClip plays pcm (48kHz stereo samples) in the voice channel target. Over playing music the clip is
+// mixed in with the music ducked; otherwise the provider joins target if needed and sends the clip alone.
+func (p *DiscordSinkProvider) PlayClip(target string, pcm []int16) error {
+	p.clips.add(pcm)
+	if !p.clips.startPump(time.Now()) {
+		return nil
+	}
+	s, err := p.Sink(target)
+	if err != nil {
+		p.clips.abortPump()
+		return err
+	}
+	ds, ok := s.(*DiscordSink)
+	if !ok {
+		p.clips.abortPump()
+		return fmt.Errorf("unexpected sink type %T", s)
+	}
+	go p.pumpClips(ds.vc, ds.monitor)
+	return nil
+}
+
+// pumpClips sends the playing clips with nothing else on the connection. It has its own encoder, as the
+// shared one belongs to the music sinks.
+func (p *DiscordSinkProvider) pumpClips(vc *discordgo.VoiceConnection, monitor func([]byte)) {
+	enc, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
+	if err != nil {
+		p.log.Warn().Err(err).Msg("clip_encoder_failed")
+		p.clips.abortPump()
+		return
+	}
+	pcm := make([]int16, stream.FrameSize*stream.Channels)
+	opusBuf := make([]byte, 4096)
+	stats := newStreamStats()
+	defer p.metrics.addFrames(stats)
+	for {
+		clear(pcm)
+		if !p.clips.pumpFrame(pcm, p.levels.volumeGain(), time.Now()) {
+			return
+		}
+		n, err := enc.Encode(pcm, opusBuf)
+		if err != nil {
+			stats.encoderErrors++
+			p.log.Warn().Err(err).Msg("clip_encode_failed")
+			p.clips.abortPump()
+			return
+		}
+		packet := append([]byte(nil), opusBuf[:n]...)
+		start := time.Now()
+		if !safeOpusSend(vc, packet) {
+			p.log.Warn().Msg("clip_send_failed")
+			p.clips.abortPump()
+			return
+		}
+		stats.sent(time.Since(start))
+		if monitor != nil {
+			monitor(packet)
+		}
+	}
+}
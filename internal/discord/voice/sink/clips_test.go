@@ -0,0 +1,86 @@
+package sink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+func frameOf(v int16) []int16 {
+	buf := make([]int16, stream.FrameSize*stream.Channels)
+	for i := range buf {
+		buf[i] = v
+	}
+	return buf
+}
+
+func TestClipMixerDucksMusicUnderClip(t *testing.T) {
+	t.Parallel()
+	var m clipMixer
+	now := time.Now()
+	// A six-frame clip of constant level.
+	var clip []int16
+	for range 6 {
+		clip = append(clip, frameOf(1000)...)
+	}
+	m.add(clip)
+
+	var music []int16
+	for range 6 {
+		buf := frameOf(10000)
+		m.mixMusic(buf, 1, now)
+		music = append(music, buf[len(buf)-1]-1000)
+	}
+	if music[0] >= 10000 || music[4] != int16(10000*clipDuckGain) || music[5] != music[4] {
+		t.Fatalf("ducked music levels = %v, want a ramp down to %v", music, int16(10000*clipDuckGain))
+	}
+
+	// With the clip over the music comes back up gradually.
+	buf := frameOf(10000)
+	m.mixMusic(buf, 1, now)
+	if buf[len(buf)-1] <= music[5] || buf[len(buf)-1] >= 10000 {
+		t.Fatalf("release level = %d", buf[len(buf)-1])
+	}
+	for range 20 {
+		buf = frameOf(10000)
+		m.mixMusic(buf, 1, now)
+	}
+	if buf[0] != 10000 {
+		t.Fatalf("music still ducked after release: %d", buf[0])
+	}
+}
+
+func TestClipMixerPumpYieldsToMusic(t *testing.T) {
+	t.Parallel()
+	var m clipMixer
+	now := time.Now()
+	m.add(append(frameOf(500), frameOf(500)...))
+	if !m.startPump(now) {
+		t.Fatal("pump not started without music")
+	}
+	if m.startPump(now) {
+		t.Fatal("second pump started")
+	}
+
+	buf := make([]int16, stream.FrameSize*stream.Channels)
+	if !m.pumpFrame(buf, 0.5, now) || buf[0] != 250 {
+		t.Fatalf("pumped frame = %d, want the clip at half volume", buf[0])
+	}
+
+	// Music starts: its sink takes the remaining frame and the pump stops.
+	music := frameOf(0)
+	m.mixMusic(music, 1, now)
+	if music[0] != 500 {
+		t.Fatalf("music sink mixed %d, want the rest of the clip", music[0])
+	}
+	if m.pumpFrame(make([]int16, len(buf)), 1, now) {
+		t.Fatal("pump kept running while music plays")
+	}
+	if m.startPump(now) {
+		t.Fatal("pump started while music plays")
+	}
+	if !m.startPump(now.Add(time.Second)) {
+		t.Fatal("pump not started after music stopped")
+	}
+}
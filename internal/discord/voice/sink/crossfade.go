@@ -0,0 +1,61 @@
+package sink
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// crossfader mixes the next track into the tail of the current one. The fade is equal-power
+// (cos/sin gains), which keeps the perceived level steady for unrelated material.
+type crossfader struct {
+	next   io.Reader
+	frames int // fade length in frames
+	done   int // frames mixed so far
+	pcm    []byte
+	buf    []int16
+}
+
+func newCrossfader(next io.Reader, length time.Duration) *crossfader {
+	return &crossfader{
+		next:   next,
+		frames: max(int(length.Seconds()/frameSec), 1),
+		pcm:    make([]byte, stream.FrameSize*stream.Channels*2),
+		buf:    make([]int16, stream.FrameSize*stream.Channels),
+	}
+}
+
+// mix reads one frame of the next track and blends it into cur. It returns false when the next track
+// ran out (cur is then left as it was).
+func (c *crossfader) mix(cur []int16) bool {
+	if _, err := io.ReadFull(c.next, c.pcm); err != nil {
+		return false
+	}
+	for i := range c.buf {
+		c.buf[i] = int16(binary.LittleEndian.Uint16(c.pcm[i*2 : i*2+2]))
+	}
+	pos := min(float64(c.done)/float64(c.frames), 1)
+	mixFrames(cur, c.buf, pos)
+	c.done++
+	return true
+}
+
+// mixFrames writes the blend of cur (fading out) and next (fading in) into cur. pos runs from 0
+// (only cur) to 1 (only next).
+func mixFrames(cur, next []int16, pos float64) {
+	out := math.Cos(pos * math.Pi / 2)
+	in := math.Sin(pos * math.Pi / 2)
+	for i := range cur {
+		v := math.Round(float64(cur[i])*out + float64(next[i])*in)
+		switch {
+		case v > math.MaxInt16:
+			v = math.MaxInt16
+		case v < math.MinInt16:
+			v = math.MinInt16
+		}
+		cur[i] = int16(v)
+	}
+}
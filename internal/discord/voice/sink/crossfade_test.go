@@ -0,0 +1,50 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+func TestMixFramesEndpoints(t *testing.T) {
+	t.Parallel()
+	cur := []int16{1000, -1000}
+	mixFrames(cur, []int16{200, 300}, 0)
+	if cur[0] != 1000 || cur[1] != -1000 {
+		t.Fatalf("pos 0 changed the current frame: %v", cur)
+	}
+	mixFrames(cur, []int16{200, 300}, 1)
+	if cur[0] != 200 || cur[1] != 300 {
+		t.Fatalf("pos 1 = %v, want the next frame", cur)
+	}
+}
+
+func TestCrossfaderFadesInNextTrack(t *testing.T) {
+	t.Parallel()
+	const level = 10000
+	frame := make([]byte, stream.FrameSize*stream.Channels*2)
+	for i := 0; i < len(frame); i += 2 {
+		binary.LittleEndian.PutUint16(frame[i:], level)
+	}
+	// Four frames of the next track for a two-frame (40 ms) fade.
+	next := bytes.NewReader(bytes.Repeat(frame, 4))
+	c := newCrossfader(next, 2*time.Duration(frameSec*float64(time.Second)))
+
+	var got []int16
+	for range 4 {
+		cur := make([]int16, stream.FrameSize*stream.Channels)
+		if !c.mix(cur) {
+			t.Fatal("next track ended early")
+		}
+		got = append(got, cur[0])
+	}
+	if got[0] != 0 || got[1] <= 0 || got[1] >= level || got[2] != level || got[3] != level {
+		t.Fatalf("fade-in levels = %v", got)
+	}
+	if c.mix(make([]int16, stream.FrameSize*stream.Channels)) {
+		t.Fatal("mix succeeded after the next track ran out")
+	}
+}
@@ -0,0 +1,136 @@
+package sink
+
+import (
+	"math"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/keshon/server-domme/internal/discord/voice/dsp"
+)
+
+// Loudness normalisation loosely follows EBU R128 / ITU-R BS.1770: the signal is K-weighted,
+// its mean square is integrated into a gated running estimate, and a smoothed gain steers the
+// estimate towards a target level. It is a per-track running estimate, not a true integrated
+// measurement, so the first second or two of a track settles in.
+const (
+	// loudnessTargetLUFS is the level tracks are steered towards (typical streaming target).
+	loudnessTargetLUFS = -16.0
+	// loudnessAbsGateLUFS ignores near-silence when updating the estimate.
+	loudnessAbsGateLUFS = -70.0
+	// loudnessRelGateLU ignores frames this far below the current estimate (fades, breaks).
+	loudnessRelGateLU = 20.0
+	// loudnessWindowSec is the time constant of the running loudness estimate.
+	loudnessWindowSec = 3.0
+	// Gain limits so a silent intro or a brickwalled master is not pushed to extremes.
+	loudnessMaxBoostDB = 12.0
+	loudnessMaxCutDB   = -20.0
+	// Gain smoothing: cut quickly to avoid clipping, boost slowly to avoid pumping.
+	loudnessAttackSec  = 0.3
+	loudnessReleaseSec = 2.0
+
+	frameSec = float64(stream.FrameSize) / stream.SampleRate
+)
+
+// loudnessMeter computes the K-weighted mean square of interleaved stereo frames.
+type loudnessMeter struct {
+	shelf, highPass *dsp.Biquad
+	buf             []float64
+}
+
+func newLoudnessMeter() *loudnessMeter {
+	m := &loudnessMeter{}
+	m.shelf, m.highPass = dsp.NewKWeighting()
+	return m
+}
+
+// meanSquare returns the channel-summed K-weighted mean square of one interleaved int16 frame.
+func (m *loudnessMeter) meanSquare(buf []int16) float64 {
+	if cap(m.buf) < len(buf) {
+		m.buf = make([]float64, len(buf))
+	}
+	x := m.buf[:len(buf)]
+	for i, s := range buf {
+		x[i] = float64(s) / 32768
+	}
+	m.shelf.Process(x)
+	m.highPass.Process(x)
+	var sum [stream.Channels]float64
+	for i, v := range x {
+		sum[i%stream.Channels] += v * v
+	}
+	perChannel := float64(len(buf) / stream.Channels)
+	if perChannel == 0 {
+		return 0
+	}
+	var total float64
+	for _, v := range sum {
+		total += v / perChannel
+	}
+	return total
+}
+
+func lufs(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+// loudnessNormalizer tracks one stream's loudness and returns the gain to apply per frame.
+type loudnessNormalizer struct {
+	meter  *loudnessMeter
+	power  float64 // running K-weighted mean square
+	primed bool
+	gain   float64 // smoothed linear gain
+}
+
+func newLoudnessNormalizer() *loudnessNormalizer {
+	return &loudnessNormalizer{meter: newLoudnessMeter(), gain: 1}
+}
+
+// next measures the frame, updates the gated running estimate and returns the smoothed gain for it.
+func (n *loudnessNormalizer) next(buf []int16) float64 {
+	ms := n.meter.meanSquare(buf)
+	frame := lufs(ms)
+
+	if frame > loudnessAbsGateLUFS {
+		switch {
+		case !n.primed:
+			n.power = ms
+			n.primed = true
+		case frame > lufs(n.power)-loudnessRelGateLU:
+			a := math.Exp(-frameSec / loudnessWindowSec)
+			n.power = a*n.power + (1-a)*ms
+		}
+	}
+	if !n.primed {
+		return n.gain
+	}
+
+	db := loudnessTargetLUFS - lufs(n.power)
+	db = math.Max(loudnessMaxCutDB, math.Min(loudnessMaxBoostDB, db))
+	want := dsp.DBToGain(db)
+
+	tau := loudnessReleaseSec
+	if want < n.gain {
+		tau = loudnessAttackSec
+	}
+	n.gain += (want - n.gain) * (1 - math.Exp(-frameSec/tau))
+	return n.gain
+}
+
+// applyGain scales samples in place, saturating at the int16 range.
+func applyGain(buf []int16, gain float64) {
+	if gain == 1 {
+		return
+	}
+	for i, s := range buf {
+		v := math.Round(float64(s) * gain)
+		switch {
+		case v > math.MaxInt16:
+			v = math.MaxInt16
+		case v < math.MinInt16:
+			v = math.MinInt16
+		}
+		buf[i] = int16(v)
+	}
+}
@@ -0,0 +1,91 @@
+package sink
+
+import (
+	"math"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// sineFrames returns `seconds` of interleaved stereo 1 kHz sine frames at the given peak amplitude (0..1).
+func sineFrames(amplitude float64, seconds float64) [][]int16 {
+	frames := int(seconds / frameSec)
+	out := make([][]int16, frames)
+	n := 0
+	for f := range out {
+		buf := make([]int16, stream.FrameSize*stream.Channels)
+		for i := 0; i < stream.FrameSize; i++ {
+			v := int16(amplitude * 32767 * math.Sin(2*math.Pi*1000*float64(n)/stream.SampleRate))
+			buf[i*2], buf[i*2+1] = v, v
+			n++
+		}
+		out[f] = buf
+	}
+	return out
+}
+
+// normalizedLoudness runs frames through a normalizer and measures the tail of the output.
+func normalizedLoudness(frames [][]int16) (float64, float64) {
+	norm := newLoudnessNormalizer()
+	meter := newLoudnessMeter()
+	var sum float64
+	var counted int
+	var gain float64
+	for i, buf := range frames {
+		gain = norm.next(buf)
+		applyGain(buf, gain)
+		ms := meter.meanSquare(buf)
+		if i >= len(frames)/2 {
+			sum += ms
+			counted++
+		}
+	}
+	return lufs(sum / float64(counted)), gain
+}
+
+func TestLoudnessNormalizerConverges(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		amplitude float64
+		boost     bool
+	}{
+		{"quiet upload", 0.05, true},
+		{"loud master", 0.9, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, gain := normalizedLoudness(sineFrames(tt.amplitude, 12))
+			if math.Abs(got-loudnessTargetLUFS) > 1.5 {
+				t.Fatalf("output loudness %.2f LUFS, want %.1f ±1.5", got, loudnessTargetLUFS)
+			}
+			if tt.boost != (gain > 1) {
+				t.Fatalf("gain %.3f, boost expected=%v", gain, tt.boost)
+			}
+		})
+	}
+}
+
+func TestLoudnessNormalizerIgnoresSilence(t *testing.T) {
+	t.Parallel()
+	norm := newLoudnessNormalizer()
+	silent := make([]int16, stream.FrameSize*stream.Channels)
+	for i := 0; i < 100; i++ {
+		if g := norm.next(silent); g != 1 {
+			t.Fatalf("gain moved to %.3f on silence", g)
+		}
+	}
+}
+
+func TestApplyGainSaturates(t *testing.T) {
+	t.Parallel()
+	buf := []int16{1000, -1000, 30000, -30000}
+	applyGain(buf, 2)
+	want := []int16{2000, -2000, math.MaxInt16, math.MinInt16}
+	for i := range buf {
+		if buf[i] != want[i] {
+			t.Fatalf("buf[%d] = %d, want %d", i, buf[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package sink
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/rs/zerolog"
+)
+
+// sendStallThreshold is how long a single send may wait on the voice connection before it counts as a
+// stall. Sends normally wait about one frame (20 ms), as the connection paces the packets out.
+const sendStallThreshold = 100 * time.Millisecond
+
+// VoiceMetrics are the voice transport counters of one guild since the bot started.
+type VoiceMetrics struct {
+	FramesSent uint64
+	// SendWait is the total time spent waiting to hand frames to the voice connection, MaxSendWait the
+	// longest single wait and SendStalls the waits above sendStallThreshold.
+	SendWait    time.Duration
+	MaxSendWait time.Duration
+	SendStalls  uint64
+
+	EncoderErrors   uint64
+	TransportErrors uint64
+	// SoftRecoveries and HardRecoveries count how the player recovered from transport errors (see
+	// PlayerTransportRecoveryMode).
+	SoftRecoveries uint64
+	HardRecoveries uint64
+
+	// Joins counts voice channel joins; LastJoin and MaxJoin are join latencies, JoinTotal their sum.
+	Joins     uint64
+	LastJoin  time.Duration
+	MaxJoin   time.Duration
+	JoinTotal time.Duration
+
+	Streams       uint64
+	LastStreamEnd time.Time
+	// LastStreamErr is the error the last stream ended with ("" when it finished or was stopped).
+	LastStreamErr string
+}
+
+// AvgJoin returns the mean voice join latency.
+func (m VoiceMetrics) AvgJoin() time.Duration {
+	if m.Joins == 0 {
+		return 0
+	}
+	return m.JoinTotal / time.Duration(m.Joins)
+}
+
+// AvgSendWait returns the mean time a frame waited to be sent.
+func (m VoiceMetrics) AvgSendWait() time.Duration {
+	if m.FramesSent == 0 {
+		return 0
+	}
+	return m.SendWait / time.Duration(m.FramesSent)
+}
+
+// streamStats counts the frames of one stream; only its own goroutine touches it.
+type streamStats struct {
+	start         time.Time
+	frames        uint64
+	wait, maxWait time.Duration
+	stalls        uint64
+	encoderErrors uint64
+}
+
+func newStreamStats() *streamStats {
+	return &streamStats{start: time.Now()}
+}
+
+// sent records one frame handed to the connection after waiting wait.
+func (s *streamStats) sent(wait time.Duration) {
+	s.frames++
+	s.wait += wait
+	s.maxWait = max(s.maxWait, wait)
+	if wait > sendStallThreshold {
+		s.stalls++
+	}
+}
+
+// voiceMetrics accumulates VoiceMetrics for a provider.
+type voiceMetrics struct {
+	mu sync.Mutex
+	m  VoiceMetrics
+}
+
+func (v *voiceMetrics) snapshot() VoiceMetrics {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.m
+}
+
+func (v *voiceMetrics) joined(latency time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.m.Joins++
+	v.m.LastJoin = latency
+	v.m.MaxJoin = max(v.m.MaxJoin, latency)
+	v.m.JoinTotal += latency
+}
+
+func (v *voiceMetrics) recovered(mode string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if mode == "soft" {
+		v.m.SoftRecoveries++
+	} else {
+		v.m.HardRecoveries++
+	}
+}
+
+// addFrames adds frames sent outside a music stream (the clip pump).
+func (v *voiceMetrics) addFrames(s *streamStats) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.addFramesLocked(s)
+}
+
+func (v *voiceMetrics) addFramesLocked(s *streamStats) {
+	v.m.FramesSent += s.frames
+	v.m.SendWait += s.wait
+	v.m.MaxSendWait = max(v.m.MaxSendWait, s.maxWait)
+	v.m.SendStalls += s.stalls
+	v.m.EncoderErrors += s.encoderErrors
+}
+
+// endStream adds a finished stream that ended with err and logs its counters.
+func (v *voiceMetrics) endStream(log zerolog.Logger, s *streamStats, err error) {
+	now := time.Now()
+	v.mu.Lock()
+	v.addFramesLocked(s)
+	v.m.Streams++
+	v.m.LastStreamEnd = now
+	v.m.LastStreamErr = ""
+	if err != nil && !errors.Is(err, stream.ErrPlaybackStopped) {
+		v.m.LastStreamErr = err.Error()
+	}
+	if errors.Is(err, stream.ErrVoiceTransport) {
+		v.m.TransportErrors++
+	}
+	v.mu.Unlock()
+
+	var avgWait time.Duration
+	if s.frames > 0 {
+		avgWait = s.wait / time.Duration(s.frames)
+	}
+	log.Info().
+		Dur("duration", now.Sub(s.start)).
+		Uint64("frames", s.frames).
+		Dur("avg_send_wait", avgWait).
+		Dur("max_send_wait", s.maxWait).
+		Uint64("send_stalls", s.stalls).
+		Uint64("encoder_errors", s.encoderErrors).
+		AnErr("error", err).
+		Msg("sink_stream_ended")
+}
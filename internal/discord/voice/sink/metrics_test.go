@@ -0,0 +1,56 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/rs/zerolog"
+)
+
+func TestVoiceMetricsAccumulateStreams(t *testing.T) {
+	t.Parallel()
+	var v voiceMetrics
+
+	s := newStreamStats()
+	for _, wait := range []time.Duration{20 * time.Millisecond, 20 * time.Millisecond, 300 * time.Millisecond} {
+		s.sent(wait)
+	}
+	v.endStream(zerolog.Nop(), s, fmt.Errorf("send: %w", stream.ErrVoiceTransport))
+	v.recovered("soft")
+
+	s = newStreamStats()
+	s.sent(20 * time.Millisecond)
+	v.endStream(zerolog.Nop(), s, stream.ErrPlaybackStopped)
+	v.joined(200 * time.Millisecond)
+	v.joined(400 * time.Millisecond)
+	v.recovered("hard")
+
+	m := v.snapshot()
+	if m.FramesSent != 4 || m.SendStalls != 1 || m.MaxSendWait != 300*time.Millisecond {
+		t.Fatalf("frames %d stalls %d max wait %v", m.FramesSent, m.SendStalls, m.MaxSendWait)
+	}
+	if m.AvgSendWait() != 90*time.Millisecond {
+		t.Fatalf("avg send wait %v", m.AvgSendWait())
+	}
+	if m.Streams != 2 || m.TransportErrors != 1 || m.LastStreamErr != "" {
+		t.Fatalf("streams %d transport errors %d last error %q", m.Streams, m.TransportErrors, m.LastStreamErr)
+	}
+	if m.SoftRecoveries != 1 || m.HardRecoveries != 1 {
+		t.Fatalf("recoveries soft %d hard %d", m.SoftRecoveries, m.HardRecoveries)
+	}
+	if m.Joins != 2 || m.LastJoin != 400*time.Millisecond || m.AvgJoin() != 300*time.Millisecond {
+		t.Fatalf("joins %d last %v avg %v", m.Joins, m.LastJoin, m.AvgJoin())
+	}
+}
+
+func TestVoiceMetricsKeepsLastStreamError(t *testing.T) {
+	t.Parallel()
+	var v voiceMetrics
+	v.endStream(zerolog.Nop(), newStreamStats(), errors.New("encode error: boom"))
+	if m := v.snapshot(); m.LastStreamErr != "encode error: boom" || m.TransportErrors != 0 {
+		t.Fatalf("last error %q transport errors %d", m.LastStreamErr, m.TransportErrors)
+	}
+}
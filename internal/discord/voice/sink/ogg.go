@@ -0,0 +1,134 @@
+package sink
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// Ogg page header flags (RFC 3533).
+const (
+	oggFlagBOS = 0x02
+	oggFlagEOS = 0x04
+)
+
+// opusPreSkip is the encoder lookahead at 48kHz that players drop from the start (RFC 7845).
+const opusPreSkip = 312
+
+// oggCRCTable is the CRC-32 of the Ogg framing: polynomial 0x04c11db7, not reflected, zero init.
+var oggCRCTable = func() [256]uint32 {
+	var t [256]uint32
+	for i := range t {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		t[i] = r
+	}
+	return t
+}()
+
+func oggCRC(b []byte) uint32 {
+	var crc uint32
+	for _, v := range b {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^v]
+	}
+	return crc
+}
+
+// oggOpusWriter muxes 48kHz stereo opus packets into an Ogg Opus stream, one packet per page. The last
+// packet is held back so close can mark it as the end of the stream.
+type oggOpusWriter struct {
+	w       io.Writer
+	serial  uint32
+	seq     uint32
+	granule uint64
+	pending []byte
+}
+
+// newOggOpusWriter writes the OpusHead and OpusTags header pages of a new logical stream to w.
+func newOggOpusWriter(w io.Writer, serial uint32) (*oggOpusWriter, error) {
+	o := &oggOpusWriter{w: w, serial: serial}
+
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1 // version
+	head[9] = stream.Channels
+	binary.LittleEndian.PutUint16(head[10:], opusPreSkip)
+	binary.LittleEndian.PutUint32(head[12:], stream.SampleRate)
+	if err := o.writePage(head, 0, oggFlagBOS); err != nil {
+		return nil, err
+	}
+
+	const vendor = "server-domme"
+	tags := make([]byte, 8+4+len(vendor)+4)
+	copy(tags, "OpusTags")
+	binary.LittleEndian.PutUint32(tags[8:], uint32(len(vendor)))
+	copy(tags[12:], vendor)
+	if err := o.writePage(tags, 0, 0); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// writePacket adds one opus packet of a 20 ms frame.
+func (o *oggOpusWriter) writePacket(packet []byte) error {
+	if o.pending != nil {
+		if err := o.flush(0); err != nil {
+			return err
+		}
+	}
+	o.pending = append(o.pending[:0], packet...)
+	return nil
+}
+
+// close writes the held-back packet as the last page of the stream. It does not close w.
+func (o *oggOpusWriter) close() error {
+	if o.pending == nil {
+		// Nothing was encoded; an empty end page still terminates the stream cleanly.
+		return o.writePage(nil, o.granule, oggFlagEOS)
+	}
+	return o.flush(oggFlagEOS)
+}
+
+func (o *oggOpusWriter) flush(flags byte) error {
+	o.granule += stream.FrameSize
+	err := o.writePage(o.pending, o.granule, flags)
+	o.pending = o.pending[:0]
+	return err
+}
+
+func (o *oggOpusWriter) writePage(data []byte, granule uint64, flags byte) error {
+	// Lacing: a run of 255s and a final value below 255 (0 when the length is a multiple of 255).
+	segments := make([]byte, 0, len(data)/255+1)
+	for n := len(data); ; n -= 255 {
+		if n < 255 {
+			segments = append(segments, byte(n))
+			break
+		}
+		segments = append(segments, 255)
+	}
+	if len(data) == 0 {
+		segments = segments[:0]
+	}
+
+	page := make([]byte, 27+len(segments)+len(data))
+	copy(page, "OggS")
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], granule)
+	binary.LittleEndian.PutUint32(page[14:], o.serial)
+	binary.LittleEndian.PutUint32(page[18:], o.seq)
+	page[26] = byte(len(segments))
+	copy(page[27:], segments)
+	copy(page[27+len(segments):], data)
+	binary.LittleEndian.PutUint32(page[22:], oggCRC(page))
+
+	o.seq++
+	_, err := o.w.Write(page)
+	return err
+}
@@ -0,0 +1,98 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+type oggPage struct {
+	flags   byte
+	granule uint64
+	serial  uint32
+	seq     uint32
+	data    []byte
+}
+
+// readOggPages splits b into pages, checking the capture pattern and checksum of each.
+func readOggPages(t *testing.T, b []byte) []oggPage {
+	t.Helper()
+	var pages []oggPage
+	for len(b) > 0 {
+		if len(b) < 27 || string(b[:4]) != "OggS" {
+			t.Fatalf("page %d: bad capture pattern", len(pages))
+		}
+		nseg := int(b[26])
+		size := 0
+		for _, l := range b[27 : 27+nseg] {
+			size += int(l)
+		}
+		end := 27 + nseg + size
+		page := append([]byte(nil), b[:end]...)
+		want := binary.LittleEndian.Uint32(page[22:])
+		binary.LittleEndian.PutUint32(page[22:], 0)
+		if got := oggCRC(page); got != want {
+			t.Fatalf("page %d: crc %08x, header says %08x", len(pages), got, want)
+		}
+		pages = append(pages, oggPage{
+			flags:   b[5],
+			granule: binary.LittleEndian.Uint64(b[6:]),
+			serial:  binary.LittleEndian.Uint32(b[14:]),
+			seq:     binary.LittleEndian.Uint32(b[18:]),
+			data:    b[27+nseg : end],
+		})
+		b = b[end:]
+	}
+	return pages
+}
+
+func TestOggCRCKnownValue(t *testing.T) {
+	t.Parallel()
+	// Check value of the Ogg CRC (zero init, not reflected, no final xor) over "123456789".
+	if got := oggCRC([]byte("123456789")); got != 0x89a1897f {
+		t.Fatalf("crc = %08x", got)
+	}
+}
+
+func TestOggOpusWriterPages(t *testing.T) {
+	t.Parallel()
+	var out bytes.Buffer
+	w, err := newOggOpusWriter(&out, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The second packet needs two lacing values (255 + 45).
+	for _, size := range []int{10, 300, 20} {
+		if err := w.writePacket(bytes.Repeat([]byte{1}, size)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pages := readOggPages(t, out.Bytes())
+	if len(pages) != 5 {
+		t.Fatalf("got %d pages, want head, tags and 3 packets", len(pages))
+	}
+	if pages[0].flags != oggFlagBOS || !bytes.HasPrefix(pages[0].data, []byte("OpusHead")) || pages[0].data[9] != 2 {
+		t.Fatalf("head page = %+v", pages[0])
+	}
+	if !bytes.HasPrefix(pages[1].data, []byte("OpusTags")) {
+		t.Fatal("second page is not OpusTags")
+	}
+	for i, p := range pages {
+		if p.serial != 7 || p.seq != uint32(i) {
+			t.Fatalf("page %d: serial %d seq %d", i, p.serial, p.seq)
+		}
+	}
+	if len(pages[3].data) != 300 {
+		t.Fatalf("packet 2 has %d bytes after lacing", len(pages[3].data))
+	}
+	if pages[2].granule != 960 || pages[4].granule != 3*960 {
+		t.Fatalf("granules = %d, %d", pages[2].granule, pages[4].granule)
+	}
+	if pages[4].flags != oggFlagEOS || pages[3].flags != 0 {
+		t.Fatalf("flags = %x, %x; want EOS only on the last page", pages[3].flags, pages[4].flags)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	musicsink "github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/server-domme/internal/discord/voice/dsp"
+	"github.com/keshon/server-domme/internal/domain"
 	"github.com/rs/zerolog"
 )
 
@@ -23,6 +26,38 @@ type DiscordSinkProvider struct {
 	mu               sync.Mutex
 	vc               *discordgo.VoiceConnection
 	currentChannelID string
+	levels           audioLevels
+	// encoder is shared by the sinks of one voice connection (replaced on every join).
+	encoder *sharedEncoder
+	clips   clipMixer
+	// monitor receives a copy of every opus packet sent (e.g. StreamServer.Publisher); nil when unused.
+	monitor func(packet []byte)
+	// receiving keeps the bot undeafened (and its connection open past ReleaseSink) while recording.
+	receiving bool
+	metrics   voiceMetrics
+}
+
+// audioLevels holds per-guild output settings. Sinks read them every frame, so changes apply live.
+type audioLevels struct {
+	volume    atomic.Int32 // percent, 0..domain.MaxMusicVolume
+	normalize atomic.Bool
+	filter    atomic.Value // dsp.Preset
+}
+
+func (l *audioLevels) volumeGain() float64 {
+	return float64(l.volume.Load()) / 100
+}
+
+func (l *audioLevels) preset() dsp.Preset {
+	if p, ok := l.filter.Load().(dsp.Preset); ok {
+		return p
+	}
+	return dsp.PresetOff
+}
+
+// speed is the resampling ratio of the current filter preset.
+func (l *audioLevels) speed() float64 {
+	return l.preset().Speed()
 }
 
 // NewDiscordSinkProvider creates a sink provider for the given session getter and guild.
@@ -30,12 +65,52 @@ func NewDiscordSinkProvider(getSession SessionGetter, guildID string, voiceReady
 	if voiceReadyDelay <= 0 {
 		voiceReadyDelay = 500 * time.Millisecond
 	}
-	return &DiscordSinkProvider{
+	p := &DiscordSinkProvider{
 		getSession:      getSession,
 		guildID:         guildID,
 		voiceReadyDelay: voiceReadyDelay,
 		log:             log.With().Str("component", "sink").Logger(),
 	}
+	p.levels.volume.Store(domain.DefaultMusicVolume)
+	return p
+}
+
+// SetVolume sets the output volume in percent (clamped to 0..domain.MaxMusicVolume).
+func (p *DiscordSinkProvider) SetVolume(pct int) {
+	p.levels.volume.Store(int32(domain.ClampMusicVolume(pct)))
+}
+
+// Volume returns the output volume in percent.
+func (p *DiscordSinkProvider) Volume() int {
+	return int(p.levels.volume.Load())
+}
+
+// SetNormalize enables or disables loudness normalisation.
+func (p *DiscordSinkProvider) SetNormalize(on bool) {
+	p.levels.normalize.Store(on)
+}
+
+// Normalize reports whether loudness normalisation is enabled.
+func (p *DiscordSinkProvider) Normalize() bool {
+	return p.levels.normalize.Load()
+}
+
+// SetFilter selects the audio filter preset; playing sinks switch to it on the next frame.
+func (p *DiscordSinkProvider) SetFilter(preset dsp.Preset) {
+	p.levels.filter.Store(preset)
+}
+
+// Filter returns the current audio filter preset.
+func (p *DiscordSinkProvider) Filter() dsp.Preset {
+	return p.levels.preset()
+}
+
+// SetMonitor sets a function that receives a copy of every opus packet sent to the voice channel. Set it
+// before the first Sink call.
+func (p *DiscordSinkProvider) SetMonitor(monitor func(packet []byte)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.monitor = monitor
 }
 
 // voiceJoinTimeout limits how long we wait for voice connection to become ready (e.g. no permission = no event).
@@ -50,7 +125,7 @@ func (p *DiscordSinkProvider) Sink(target string) (musicsink.AudioSink, error) {
 	defer p.mu.Unlock()
 
 	if p.vc != nil && p.currentChannelID == target {
-		return &DiscordSink{vc: p.vc, log: p.log}, nil
+		return &DiscordSink{vc: p.vc, log: p.log, levels: &p.levels, encoder: p.encoder, clips: &p.clips, monitor: p.monitor, metrics: &p.metrics}, nil
 	}
 
 	if p.vc != nil {
@@ -67,27 +142,32 @@ func (p *DiscordSinkProvider) Sink(target string) (musicsink.AudioSink, error) {
 	}
 	joinCtx, cancel := context.WithTimeout(context.Background(), voiceJoinTimeout)
 	defer cancel()
-	vc, err := dg.ChannelVoiceJoin(joinCtx, p.guildID, target, false, true)
+	joinStart := time.Now()
+	vc, err := dg.ChannelVoiceJoin(joinCtx, p.guildID, target, false, !p.receiving)
 	if err != nil {
 		return nil, fmt.Errorf("failed to join voice channel: %w", err)
 	}
+	joinLatency := time.Since(joinStart)
+	p.metrics.joined(joinLatency)
 	p.vc = vc
 	p.currentChannelID = target
-	p.log.Info().Str("channel_id", target).Str("guild_id", p.guildID).Msg("voice_joined")
+	p.encoder = &sharedEncoder{}
+	p.log.Info().Str("channel_id", target).Str("guild_id", p.guildID).Dur("latency", joinLatency).Msg("voice_joined")
 
 	time.Sleep(p.voiceReadyDelay)
 
-	return &DiscordSink{vc: vc, log: p.log}, nil
+	return &DiscordSink{vc: vc, log: p.log, levels: &p.levels, encoder: p.encoder, clips: &p.clips, monitor: p.monitor, metrics: &p.metrics}, nil
 }
 
-// ReleaseSink disconnects from the voice channel for the given target.
+// ReleaseSink disconnects from the voice channel for the given target. While receiving, only an empty
+// target (leave whatever channel) disconnects, so stopping the music does not end a recording.
 func (p *DiscordSinkProvider) ReleaseSink(target string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	if p.vc == nil {
 		return
 	}
-	if target != "" && p.currentChannelID != target {
+	if target != "" && (p.currentChannelID != target || p.receiving) {
 		return
 	}
 	if err := p.vc.Disconnect(context.Background()); err != nil {
@@ -97,6 +177,16 @@ func (p *DiscordSinkProvider) ReleaseSink(target string) {
 	p.currentChannelID = ""
 }
 
+// ChannelID returns the voice channel the provider is connected to, or "" when not connected.
+func (p *DiscordSinkProvider) ChannelID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.vc == nil {
+		return ""
+	}
+	return p.currentChannelID
+}
+
 // InvalidateSink clears the cached VoiceConnection without requiring a target match.
 // The next Sink(target) will join again (e.g. after voice WebSocket loss while gateway reconnects).
 func (p *DiscordSinkProvider) InvalidateSink() {
@@ -111,3 +201,15 @@ func (p *DiscordSinkProvider) InvalidateSink() {
 	p.vc = nil
 	p.currentChannelID = ""
 }
+
+// TransportRecovery counts how the player recovered from a voice transport error (see
+// musicsink.RecoveryObserver).
+func (p *DiscordSinkProvider) TransportRecovery(target, mode string) {
+	p.metrics.recovered(mode)
+	p.log.Info().Str("guild_id", p.guildID).Str("channel_id", target).Str("mode", mode).Msg("voice_transport_recovered")
+}
+
+// Metrics returns the guild's voice transport counters.
+func (p *DiscordSinkProvider) Metrics() VoiceMetrics {
+	return p.metrics.snapshot()
+}
@@ -0,0 +1,58 @@
+package sink
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrVoiceDeafened is returned by Receive when the bot is deafened by the server and gets no audio.
+var ErrVoiceDeafened = errors.New("the bot is deafened in this server")
+
+// Receive joins target like Sink (or keeps the current connection) and undeafens the bot so the channel's
+// audio arrives on the returned connection's OpusRecv until StopReceive. The connection then stays open
+// when the player releases it; leaving or losing the channel closes OpusRecv.
+func (p *DiscordSinkProvider) Receive(target string) (*discordgo.VoiceConnection, error) {
+	if _, err := p.Sink(target); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.vc == nil || p.currentChannelID != target {
+		return nil, errors.New("voice connection was lost")
+	}
+	if p.vc.OpusRecv == nil {
+		return nil, ErrVoiceDeafened
+	}
+	dg := p.getSession()
+	if dg == nil {
+		return nil, fmt.Errorf("no Discord session")
+	}
+	// Same channel, new self-deaf flag: Discord updates the voice state without a reconnect.
+	if err := dg.VoiceStateUpdate(p.guildID, target, false, false); err != nil {
+		return nil, fmt.Errorf("failed to undeafen: %w", err)
+	}
+	p.receiving = true
+	p.log.Info().Str("channel_id", target).Str("guild_id", p.guildID).Msg("voice_receive_started")
+	return p.vc, nil
+}
+
+// StopReceive deafens the bot again. The connection stays open for the player.
+func (p *DiscordSinkProvider) StopReceive() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.receiving {
+		return
+	}
+	p.receiving = false
+	if p.vc == nil {
+		return
+	}
+	if dg := p.getSession(); dg != nil {
+		if err := dg.VoiceStateUpdate(p.guildID, p.currentChannelID, false, true); err != nil {
+			p.log.Warn().Err(err).Msg("voice_deafen_failed")
+		}
+	}
+	p.log.Info().Str("guild_id", p.guildID).Msg("voice_receive_stopped")
+}
@@ -0,0 +1,307 @@
+package sink
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/godeps/opus"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// RecordingMixFile is the name of the mixed track in a recording directory; each user's track is
+// <userID>.ogg next to it.
+const RecordingMixFile = "mixed.ogg"
+
+// opusSilence is a 20 ms opus frame of silence, used to fill the gaps between what a user said.
+var opusSilence = []byte{0xf8, 0xff, 0xfe}
+
+const (
+	// recordMixDelay is how many frames the mixed track lags behind real time, so packets arriving
+	// late still make it into the mix.
+	recordMixDelay = 10
+	// recordMaxDrift bounds how far (in frames) a packet's RTP timestamp may place it from its arrival;
+	// beyond that the track resyncs to the arrival time.
+	recordMaxDrift = 50
+)
+
+// Recorder writes the voice received on a connection to Ogg Opus files: one per user, holding the packets
+// as received, and a mixed track of everyone. All tracks start when the recording does, with silence
+// where a user said nothing, so they line up. Only users accepted by the allow function are recorded.
+type Recorder struct {
+	dir     string
+	allowed func(userID string) bool
+	start   time.Time
+
+	mu     sync.Mutex
+	tracks map[string]*recordTrack
+	mix    *recordMix
+	closed bool
+}
+
+// recordTrack is one user's file. Frames are counted in 20 ms steps from the start of the recording.
+type recordTrack struct {
+	out       *oggFile
+	dec       *opus.Decoder
+	next      int64 // next frame to write
+	lastFrame int64
+	lastTS    uint32
+	pcm       []int16
+}
+
+// recordMix sums decoded frames until they are old enough to encode into the mixed track.
+type recordMix struct {
+	out    *oggFile
+	enc    *opus.Encoder
+	next   int64 // next frame to encode
+	end    int64 // one past the last frame with audio
+	frames map[int64][]int32
+}
+
+// oggFile is an Ogg Opus stream written to a buffered file.
+type oggFile struct {
+	file *os.File
+	buf  *bufio.Writer
+	ogg  *oggOpusWriter
+}
+
+func createOggFile(path string) (*oggFile, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	buf := bufio.NewWriter(file)
+	ogg, err := newOggOpusWriter(buf, rand.Uint32())
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &oggFile{file: file, buf: buf, ogg: ogg}, nil
+}
+
+func (f *oggFile) close() error {
+	err := f.ogg.close()
+	if ferr := f.buf.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// NewRecorder creates dir and the mixed track in it. Recording starts now.
+func NewRecorder(dir string, allowed func(userID string) bool) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	enc, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
+	if err != nil {
+		return nil, fmt.Errorf("encoder error: %w", err)
+	}
+	out, err := createOggFile(filepath.Join(dir, RecordingMixFile))
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{
+		dir:     dir,
+		allowed: allowed,
+		start:   time.Now(),
+		tracks:  make(map[string]*recordTrack),
+		mix:     &recordMix{out: out, enc: enc, frames: make(map[int64][]int32)},
+	}, nil
+}
+
+// Dir returns the directory the recording is written to.
+func (r *Recorder) Dir() string {
+	return r.dir
+}
+
+// Run records packets from vc until stop is closed or the connection goes away, then closes the files.
+// It returns true when the connection ended the recording.
+func (r *Recorder) Run(vc *discordgo.VoiceConnection, stop <-chan struct{}) (bool, error) {
+	tick := time.NewTicker(time.Duration(frameSec * float64(time.Second)))
+	defer tick.Stop()
+	for {
+		select {
+		case <-stop:
+			return false, r.Close(time.Now())
+		case <-vc.DeadChannel():
+			return true, r.Close(time.Now())
+		case p, ok := <-vc.OpusRecv:
+			if !ok {
+				return true, r.Close(time.Now())
+			}
+			userID, known := vc.UserIDForSSRC(p.SSRC)
+			if !known {
+				continue
+			}
+			if err := r.WritePacket(userID, p.Timestamp, p.Opus, time.Now()); err != nil {
+				_ = r.Close(time.Now())
+				return false, err
+			}
+		case now := <-tick.C:
+			if err := r.Flush(now); err != nil {
+				_ = r.Close(now)
+				return false, err
+			}
+		}
+	}
+}
+
+func (r *Recorder) frameAt(t time.Time) int64 {
+	return int64(t.Sub(r.start) / (time.Duration(stream.FrameSize) * time.Second / stream.SampleRate))
+}
+
+// WritePacket records one opus packet from userID with its RTP timestamp, received at now. Packets of
+// users who are not allowed, duplicates and packets too late for their track are dropped.
+func (r *Recorder) WritePacket(userID string, timestamp uint32, packet []byte, now time.Time) error {
+	if !r.allowed(userID) {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+
+	t, err := r.trackLocked(userID)
+	if err != nil {
+		return err
+	}
+	arrival := r.frameAt(now)
+	frame := arrival
+	if t.next > 0 {
+		// Place the packet by its RTP timestamp relative to the previous one, so bursts and jitter keep
+		// their timing; after a long pause the timestamps may have jumped, so resync to the arrival.
+		frame = t.lastFrame + int64(int32(timestamp-t.lastTS))/stream.FrameSize
+		if frame < arrival-recordMaxDrift || frame > arrival+recordMaxDrift {
+			frame = arrival
+		}
+	}
+	if frame < t.next {
+		return nil
+	}
+	for ; t.next < frame; t.next++ {
+		if err := t.out.ogg.writePacket(opusSilence); err != nil {
+			return err
+		}
+	}
+	if err := t.out.ogg.writePacket(packet); err != nil {
+		return err
+	}
+	t.next, t.lastFrame, t.lastTS = frame+1, frame, timestamp
+
+	// A packet the decoder rejects is still kept in the user's track; it is only missing from the mix.
+	n, err := t.dec.Decode(packet, t.pcm)
+	if err == nil {
+		r.mix.add(frame, t.pcm[:min(n, stream.FrameSize)*stream.Channels])
+	}
+	return nil
+}
+
+func (r *Recorder) trackLocked(userID string) (*recordTrack, error) {
+	if t, ok := r.tracks[userID]; ok {
+		return t, nil
+	}
+	dec, err := opus.NewDecoder(stream.SampleRate, stream.Channels)
+	if err != nil {
+		return nil, fmt.Errorf("decoder error: %w", err)
+	}
+	out, err := createOggFile(filepath.Join(r.dir, userID+".ogg"))
+	if err != nil {
+		return nil, err
+	}
+	// 120 ms is the longest opus packet.
+	t := &recordTrack{out: out, dec: dec, pcm: make([]int16, 6*stream.FrameSize*stream.Channels)}
+	r.tracks[userID] = t
+	return t, nil
+}
+
+// Flush encodes the mixed frames that are old enough at now.
+func (r *Recorder) Flush(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	return r.mix.flush(r.frameAt(now) - recordMixDelay)
+}
+
+// Close mixes what is left and closes all files. It is safe to call more than once.
+func (r *Recorder) Close(now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	err := r.mix.flush(max(r.frameAt(now), r.mix.end))
+	if cerr := r.mix.out.close(); err == nil {
+		err = cerr
+	}
+	for _, t := range r.tracks {
+		if cerr := t.out.close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Users returns the IDs of the users with a track, sorted.
+func (r *Recorder) Users() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	users := make([]string, 0, len(r.tracks))
+	for id := range r.tracks {
+		users = append(users, id)
+	}
+	sort.Strings(users)
+	return users
+}
+
+func (m *recordMix) add(frame int64, pcm []int16) {
+	if frame < m.next {
+		return
+	}
+	acc, ok := m.frames[frame]
+	if !ok {
+		acc = make([]int32, stream.FrameSize*stream.Channels)
+		m.frames[frame] = acc
+	}
+	for i, v := range pcm {
+		acc[i] += int32(v)
+	}
+	m.end = max(m.end, frame+1)
+}
+
+// flush encodes the frames before upTo, with silence where nobody spoke.
+func (m *recordMix) flush(upTo int64) error {
+	pcm := make([]int16, stream.FrameSize*stream.Channels)
+	opusBuf := make([]byte, 4096)
+	for ; m.next < upTo; m.next++ {
+		acc := m.frames[m.next]
+		delete(m.frames, m.next)
+		for i := range pcm {
+			if acc == nil {
+				pcm[i] = 0
+			} else {
+				pcm[i] = clampSample(float64(acc[i]))
+			}
+		}
+		n, err := m.enc.Encode(pcm, opusBuf)
+		if err != nil {
+			return fmt.Errorf("encode error: %w", err)
+		}
+		if err := m.out.ogg.writePacket(opusBuf[:n]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package sink
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/godeps/opus"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// toneFrames encodes n frames of a tone into opus packets.
+func toneFrames(t *testing.T, n int) [][]byte {
+	t.Helper()
+	enc, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := sinePCM(time.Duration(n) * 20 * time.Millisecond)
+	pcm := make([]int16, stream.FrameSize*stream.Channels)
+	buf := make([]byte, 4096)
+	packets := make([][]byte, n)
+	for f := range n {
+		for i := range pcm {
+			pcm[i] = int16(binary.LittleEndian.Uint16(raw[(f*len(pcm)+i)*2:]))
+		}
+		size, err := enc.Encode(pcm, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		packets[f] = append([]byte(nil), buf[:size]...)
+	}
+	return packets
+}
+
+func readTrack(t *testing.T, path string) []oggPage {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return readOggPages(t, b)[2:]
+}
+
+func TestRecorderWritesAlignedTracks(t *testing.T) {
+	t.Parallel()
+	dir := filepath.Join(t.TempDir(), "rec")
+	r, err := NewRecorder(dir, func(userID string) bool { return userID != "denied" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(frame int) time.Time { return r.start.Add(time.Duration(frame) * 20 * time.Millisecond) }
+	tone := toneFrames(t, 50)
+
+	// alice speaks for frames 0-49, her packets arriving up to two frames late, one of them twice.
+	for f, packet := range tone {
+		if err := r.WritePacket("alice", uint32(1000+f*stream.FrameSize), packet, at(f+f%3)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.WritePacket("alice", uint32(1000+20*stream.FrameSize), tone[20], at(30)); err != nil {
+		t.Fatal(err)
+	}
+	// bob speaks for frames 100-124; denied is never recorded.
+	for f, packet := range tone[:25] {
+		if err := r.WritePacket("bob", uint32(f*stream.FrameSize), packet, at(100+f)); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.WritePacket("denied", uint32(f*stream.FrameSize), packet, at(100+f)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Flush(at(90)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(at(125)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := r.Users(); len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Fatalf("users = %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "denied.ogg")); !os.IsNotExist(err) {
+		t.Fatalf("denied user has a track: %v", err)
+	}
+	if got := len(readTrack(t, filepath.Join(dir, "alice.ogg"))); got != 50 {
+		t.Fatalf("alice: %d packets, want 50", got)
+	}
+	bob := readTrack(t, filepath.Join(dir, "bob.ogg"))
+	if len(bob) != 125 || string(bob[99].data) != string(opusSilence) {
+		t.Fatalf("bob: %d packets, want 100 of silence and 25 spoken", len(bob))
+	}
+
+	mix := readTrack(t, filepath.Join(dir, RecordingMixFile))
+	if len(mix) != 125 {
+		t.Fatalf("mix: %d packets, want 125", len(mix))
+	}
+	if last := mix[len(mix)-1]; last.flags != oggFlagEOS || last.granule != 125*stream.FrameSize {
+		t.Fatalf("mix: last page flags %x granule %d", last.flags, last.granule)
+	}
+	// Speech takes far more bits than the silence between it.
+	if len(mix[110].data) < 4*len(mix[75].data) {
+		t.Fatalf("mix: frame 110 has %d bytes, silent frame 75 has %d", len(mix[110].data), len(mix[75].data))
+	}
+}
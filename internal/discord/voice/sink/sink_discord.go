@@ -4,32 +4,89 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/godeps/opus"
+	musicsink "github.com/keshon/melodix/pkg/music/sink"
 	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/keshon/server-domme/internal/discord/voice/dsp"
 	"github.com/rs/zerolog"
 )
 
-// DiscordSink implements musicsink.AudioSink by encoding PCM to opus and sending to a voice connection.
+// DiscordSink implements musicsink.TransitionSink by encoding PCM to opus and sending to a voice connection.
 type DiscordSink struct {
-	vc  *discordgo.VoiceConnection
-	log zerolog.Logger
+	vc      *discordgo.VoiceConnection
+	log     zerolog.Logger
+	levels  *audioLevels
+	encoder *sharedEncoder
+	clips   *clipMixer
+	monitor func(packet []byte) // may be nil
+	metrics *voiceMetrics
 }
 
 func (d *DiscordSink) Stream(src io.ReadCloser, stop <-chan struct{}) error {
-	return streamToDiscord(d.log, src, stop, d.vc)
+	return d.StreamTransition(src, stop, musicsink.Transition{})
+}
+
+// StreamTransition streams src like Stream. A continued stream keeps the encoder state of the previous
+// track and is sent from its first frame; with a crossfade the next track is mixed into the tail.
+func (d *DiscordSink) StreamTransition(src io.ReadCloser, stop <-chan struct{}, t musicsink.Transition) error {
+	stats := newStreamStats()
+	encoder, err := d.encoder.get(t.Continued)
+	if err != nil {
+		stats.encoderErrors++
+		err = fmt.Errorf("encoder error: %w", err)
+	} else {
+		err = streamToDiscord(d.log, src, stop, d.vc, d.levels, encoder, d.clips, d.monitor, stats, t)
+	}
+	d.metrics.endStream(d.log, stats, err)
+	return err
+}
+
+// sharedEncoder keeps one opus encoder per voice connection, so consecutive tracks can continue the
+// same encoder state instead of starting cold.
+type sharedEncoder struct {
+	mu  sync.Mutex
+	enc *opus.Encoder
+}
+
+// get returns the encoder, reset to a fresh state unless continued.
+func (e *sharedEncoder) get(continued bool) (*opus.Encoder, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.enc == nil {
+		enc, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
+		if err != nil {
+			return nil, err
+		}
+		e.enc = enc
+		return enc, nil
+	}
+	if !continued {
+		if err := e.enc.Reset(); err != nil {
+			return nil, err
+		}
+	}
+	return e.enc, nil
 }
 
 // streamToDiscord streams PCM audio from a reader to a Discord voice connection.
 // Uses stream package constants (SampleRate, Channels, FrameSize) for format.
 // The caller owns the read closer and must close it when done; streamToDiscord does not close it.
-func streamToDiscord(appLog zerolog.Logger, src io.ReadCloser, stop <-chan struct{}, vc *discordgo.VoiceConnection) error {
-	encoder, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
-	if err != nil {
-		return fmt.Errorf("encoder error: %w", err)
+// levels (may be nil) supplies the filter preset, volume and loudness normalisation, read per frame before
+// encoding; speed changes of the preset resample src before it is cut into frames.
+// Unless t.Continued, the first frames are dropped as warm-up and leading silence is trimmed; a reader
+// arriving on t.Next is crossfaded into the tail (see crossfader). Soundboard clips from clips (may be nil)
+// are mixed into every frame sent, with the music ducked under them. monitor (may be nil) gets a copy of
+// every packet sent. stats counts the frames sent, how long each waited on the connection, and encoder errors.
+func streamToDiscord(appLog zerolog.Logger, src io.ReadCloser, stop <-chan struct{}, vc *discordgo.VoiceConnection, levels *audioLevels, encoder *opus.Encoder, clips *clipMixer, monitor func([]byte), stats *streamStats, t musicsink.Transition) error {
+
+	in := io.Reader(src)
+	if levels != nil {
+		in = dsp.NewResampler(src, levels.speed)
 	}
-	defer encoder.Reset()
 
 	pcmBuf := make([]byte, stream.FrameSize*stream.Channels*2)
 	intBuf := make([]int16, stream.FrameSize*stream.Channels)
@@ -37,87 +94,136 @@ func streamToDiscord(appLog zerolog.Logger, src io.ReadCloser, stop <-chan struc
 	const debugPacketCount = 5
 	packetNum := 0
 
-	const warmUpFrames = 10
-	for i := 0; i < warmUpFrames; i++ {
-		select {
-		case <-stop:
-			return stream.ErrPlaybackStopped
-		default:
+	// The normaliser measures every frame so it is already settled if enabled mid-track.
+	// It runs after the filters, so it also evens out their level changes.
+	norm := newLoudnessNormalizer()
+	preset := dsp.PresetOff
+	filters := preset.NewChain()
+	adjust := func(buf []int16) {
+		if levels == nil {
+			return
 		}
-		_, err := io.ReadFull(src, pcmBuf)
-		if err != nil {
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return nil
-			}
-			return fmt.Errorf("warm-up read error: %w", err)
+		if p := levels.preset(); p != preset {
+			preset, filters = p, p.NewChain()
+			appLog.Debug().Str("preset", string(p)).Msg("sink_filter_changed")
+		}
+		filters.Process(buf)
+		gain := norm.next(buf)
+		if !levels.normalize.Load() {
+			gain = 1
 		}
+		applyGain(buf, gain*levels.volumeGain())
 	}
-	appLog.Debug().Int("frames", warmUpFrames).Msg("sink_warmup_done")
 
-	const silenceThreshold = 100
-	const maxSilenceFrames = 150
-	frameMaxAbs := func(buf []int16) int16 {
-		var max int16
-		for _, s := range buf {
-			if s < 0 {
-				s = -s
-			}
-			if s > max {
-				max = s
+	send := func(buf []int16) error {
+		if clips != nil {
+			clipGain := 1.0
+			if levels != nil {
+				clipGain = levels.volumeGain()
 			}
+			clips.mixMusic(buf, clipGain, time.Now())
 		}
-		return max
-	}
-
-	for skipCount := 0; skipCount < maxSilenceFrames; skipCount++ {
+		n, err := encoder.Encode(buf, opusBuf)
+		if err != nil {
+			stats.encoderErrors++
+			return fmt.Errorf("encode error: %w", err)
+		}
+		if packetNum < debugPacketCount {
+			appLog.Debug().Int("packet", packetNum+1).Int("bytes", n).Msg("sink_opus_packet")
+			packetNum++
+		}
+		packet := append([]byte(nil), opusBuf[:n]...)
 		select {
 		case <-stop:
 			return stream.ErrPlaybackStopped
 		default:
-		}
-		_, err := io.ReadFull(src, pcmBuf)
-		if err != nil {
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				return nil
+			start := time.Now()
+			if !safeOpusSend(vc, packet) {
+				return stream.ErrVoiceTransport
+			}
+			stats.sent(time.Since(start))
+			if monitor != nil {
+				monitor(packet)
 			}
-			return fmt.Errorf("skip-silence read error: %w", err)
 		}
-		for i := range intBuf {
-			intBuf[i] = int16(binary.LittleEndian.Uint16(pcmBuf[i*2 : i*2+2]))
+		return nil
+	}
+
+	if !t.Continued {
+		const warmUpFrames = 10
+		for i := 0; i < warmUpFrames; i++ {
+			select {
+			case <-stop:
+				return stream.ErrPlaybackStopped
+			default:
+			}
+			_, err := io.ReadFull(in, pcmBuf)
+			if err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return nil
+				}
+				return fmt.Errorf("warm-up read error: %w", err)
+			}
 		}
-		if frameMaxAbs(intBuf) >= silenceThreshold {
-			appLog.Debug().Int("frame", skipCount+1).Int("threshold", silenceThreshold).Msg("sink_first_audible")
-			break
+		appLog.Debug().Int("frames", warmUpFrames).Msg("sink_warmup_done")
+
+		const silenceThreshold = 100
+		const maxSilenceFrames = 150
+		frameMaxAbs := func(buf []int16) int16 {
+			var max int16
+			for _, s := range buf {
+				if s < 0 {
+					s = -s
+				}
+				if s > max {
+					max = s
+				}
+			}
+			return max
 		}
-		if skipCount == maxSilenceFrames-1 {
-			appLog.Debug().Int("frames", maxSilenceFrames).Msg("sink_silence_timeout")
+
+		for skipCount := 0; skipCount < maxSilenceFrames; skipCount++ {
+			select {
+			case <-stop:
+				return stream.ErrPlaybackStopped
+			default:
+			}
+			_, err := io.ReadFull(in, pcmBuf)
+			if err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return nil
+				}
+				return fmt.Errorf("skip-silence read error: %w", err)
+			}
+			for i := range intBuf {
+				intBuf[i] = int16(binary.LittleEndian.Uint16(pcmBuf[i*2 : i*2+2]))
+			}
+			if frameMaxAbs(intBuf) >= silenceThreshold {
+				appLog.Debug().Int("frame", skipCount+1).Int("threshold", silenceThreshold).Msg("sink_first_audible")
+				break
+			}
+			if skipCount == maxSilenceFrames-1 {
+				appLog.Debug().Int("frames", maxSilenceFrames).Msg("sink_silence_timeout")
+			}
 		}
-	}
 
-	appLog.Debug().Int("max_amplitude", int(frameMaxAbs(intBuf))).Msg("sink_first_amplitude")
-	n, err := encoder.Encode(intBuf, opusBuf)
-	if err != nil {
-		return fmt.Errorf("encode error: %w", err)
-	}
-	if packetNum < debugPacketCount {
-		appLog.Debug().Int("packet", packetNum+1).Int("bytes", n).Msg("sink_opus_packet")
-		packetNum++
-	}
-	select {
-	case <-stop:
-		return stream.ErrPlaybackStopped
-		default:
-		if !safeOpusSend(vc, append([]byte(nil), opusBuf[:n]...)) {
-			return stream.ErrVoiceTransport
+		appLog.Debug().Int("max_amplitude", int(frameMaxAbs(intBuf))).Msg("sink_first_amplitude")
+		adjust(intBuf)
+		if err := send(intBuf); err != nil {
+			return err
 		}
+	} else {
+		appLog.Debug().Msg("sink_continued")
 	}
 
+	var fade *crossfader
+	next := t.Next
 	for {
 		select {
 		case <-stop:
 			return stream.ErrPlaybackStopped
 		default:
-			_, err := io.ReadFull(src, pcmBuf)
+			_, err := io.ReadFull(in, pcmBuf)
 			if err != nil {
 				if err == io.EOF || err == io.ErrUnexpectedEOF {
 					return nil
@@ -129,24 +235,29 @@ func streamToDiscord(appLog zerolog.Logger, src io.ReadCloser, stop <-chan struc
 				intBuf[i] = int16(binary.LittleEndian.Uint16(pcmBuf[i*2 : i*2+2]))
 			}
 
-			n, err := encoder.Encode(intBuf, opusBuf)
-			if err != nil {
-				return fmt.Errorf("encode error: %w", err)
+			if fade == nil && next != nil {
+				select {
+				case r := <-next:
+					// The next track goes through the same resampling as src, and the fade covers
+					// t.Crossfade of source audio, which plays faster or slower at other speeds.
+					length := t.Crossfade
+					if levels != nil {
+						r = dsp.NewResampler(r, levels.speed)
+						length = time.Duration(float64(length) / levels.speed())
+					}
+					fade = newCrossfader(r, length)
+					appLog.Debug().Dur("crossfade", t.Crossfade).Msg("sink_crossfade_start")
+				default:
+				}
 			}
-
-			if packetNum < debugPacketCount {
-				appLog.Debug().Int("packet", packetNum+1).Int("bytes", n).Msg("sink_opus_packet")
-				packetNum++
+			if fade != nil && !fade.mix(intBuf) {
+				appLog.Debug().Msg("sink_crossfade_next_ended")
+				fade, next = nil, nil
 			}
+			adjust(intBuf)
 
-			packet := append([]byte(nil), opusBuf[:n]...)
-			select {
-			case <-stop:
-				return stream.ErrPlaybackStopped
-			default:
-				if !safeOpusSend(vc, packet) {
-					return stream.ErrVoiceTransport
-				}
+			if err := send(intBuf); err != nil {
+				return err
 			}
 		}
 	}
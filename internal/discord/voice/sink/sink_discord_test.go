@@ -0,0 +1,84 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/godeps/opus"
+	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/rs/zerolog"
+
+	musicsink "github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/server-domme/internal/discord/voice/dsp"
+)
+
+// rampPCM returns pairs stereo pairs whose samples count up from 1, so a pair's value is its position.
+func rampPCM(pairs int) []byte {
+	pcm := make([]byte, 0, pairs*stream.Channels*2)
+	for i := range pairs {
+		for range stream.Channels {
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(int16(1+i%30000)))
+		}
+	}
+	return pcm
+}
+
+// countingReader counts the bytes read through it, like the player's preload does.
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestCrossfadeHandoverLosesNoSamples(t *testing.T) {
+	t.Parallel()
+	const framePairs = stream.FrameSize
+	for _, preset := range []dsp.Preset{dsp.PresetOff, dsp.PresetNightcore} {
+		levels := &audioLevels{}
+		levels.volume.Store(100)
+		levels.filter.Store(preset)
+		enc, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vc := &discordgo.VoiceConnection{OpusSend: make(chan []byte, 256)}
+
+		cur := io.NopCloser(bytes.NewReader(sinePCM(600 * time.Millisecond)))
+		next := &countingReader{r: bytes.NewReader(rampPCM(100 * framePairs))}
+		nextCh := make(chan io.Reader, 1)
+		nextCh <- next
+		tr := musicsink.Transition{Crossfade: 100 * time.Millisecond, Next: nextCh}
+		if err := streamToDiscord(zerolog.Nop(), cur, make(chan struct{}), vc, levels, enc, nil, nil, newStreamStats(), tr); err != nil {
+			t.Fatal(err)
+		}
+
+		// Every frame after the first audible one had the next track mixed in; the player continues
+		// from next.n, so that has to be exactly the input those frames used.
+		mixed := len(vc.OpusSend) - 1
+		used := int(math.Ceil(float64(mixed*framePairs) * preset.Speed()))
+		if got := next.n / 4; got < used || got > used+1 {
+			t.Fatalf("%s: fade read %d pairs of the next track for %d mixed frames, want %d", preset, got, mixed, used)
+		}
+
+		// The player continues the next track from the byte the fade stopped at.
+		rest := io.NopCloser(bytes.NewReader(rampPCM(100 * framePairs)[next.n:]))
+		sent := len(vc.OpusSend)
+		if err := streamToDiscord(zerolog.Nop(), rest, make(chan struct{}), vc, levels, enc, nil, nil, newStreamStats(), musicsink.Transition{Continued: true}); err != nil {
+			t.Fatal(err)
+		}
+		total := float64(mixed*framePairs+(len(vc.OpusSend)-sent)*framePairs) * preset.Speed()
+		if want := float64(100 * framePairs); total < want-framePairs*preset.Speed()-1 || total > want {
+			t.Fatalf("%s: the two streams played %.0f pairs of the next track, want %.0f", preset, total, want)
+		}
+	}
+}
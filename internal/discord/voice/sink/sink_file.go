@@ -0,0 +1,140 @@
+package sink
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"sync"
+
+	"github.com/godeps/opus"
+	musicsink "github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// FileSink implements musicsink.AudioSink by encoding PCM to opus and writing it to an Ogg Opus file. It
+// writes as fast as the stream is read, so a whole track takes as long as decoding it. Consecutive Stream
+// calls append to the same logical stream; Close finishes the file.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	buf  *bufio.Writer
+	enc  *opus.Encoder
+	ogg  *oggOpusWriter
+}
+
+// NewFileSink returns a sink writing to path. The file is created (or truncated) on the first Stream.
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (f *FileSink) open() error {
+	if f.ogg != nil {
+		return nil
+	}
+	enc, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
+	if err != nil {
+		return fmt.Errorf("encoder error: %w", err)
+	}
+	file, err := os.Create(f.path)
+	if err != nil {
+		return err
+	}
+	buf := bufio.NewWriter(file)
+	ogg, err := newOggOpusWriter(buf, rand.Uint32())
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.buf, f.enc, f.ogg = file, buf, enc, ogg
+	return nil
+}
+
+func (f *FileSink) Stream(src io.ReadCloser, stop <-chan struct{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	pcmBuf := make([]byte, stream.FrameSize*stream.Channels*2)
+	intBuf := make([]int16, stream.FrameSize*stream.Channels)
+	opusBuf := make([]byte, 4096)
+	for {
+		select {
+		case <-stop:
+			return stream.ErrPlaybackStopped
+		default:
+		}
+		n, err := io.ReadFull(src, pcmBuf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("read error: %w", err)
+		}
+		// A short last frame is padded with silence.
+		clear(pcmBuf[n:])
+		for i := range intBuf {
+			intBuf[i] = int16(binary.LittleEndian.Uint16(pcmBuf[i*2 : i*2+2]))
+		}
+		size, encErr := f.enc.Encode(intBuf, opusBuf)
+		if encErr != nil {
+			return fmt.Errorf("encode error: %w", encErr)
+		}
+		if werr := f.ogg.writePacket(opusBuf[:size]); werr != nil {
+			return fmt.Errorf("write error: %w", werr)
+		}
+		if err == io.ErrUnexpectedEOF {
+			return nil
+		}
+	}
+}
+
+// Close ends the Ogg stream and closes the file. A sink that never streamed has nothing to close.
+func (f *FileSink) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ogg == nil {
+		return nil
+	}
+	err := f.ogg.close()
+	if ferr := f.buf.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := f.file.Close(); err == nil {
+		err = cerr
+	}
+	f.ogg = nil
+	return err
+}
+
+// FileSinkProvider is a musicsink.Provider that returns the same FileSink for every target, so a player can
+// run end to end without a voice connection.
+type FileSinkProvider struct {
+	sink *FileSink
+}
+
+// NewFileSinkProvider creates a provider writing all playback to the Ogg Opus file at path.
+func NewFileSinkProvider(path string) *FileSinkProvider {
+	return &FileSinkProvider{sink: NewFileSink(path)}
+}
+
+// Sink returns the shared file sink. target is ignored.
+func (p *FileSinkProvider) Sink(target string) (musicsink.AudioSink, error) {
+	return p.sink, nil
+}
+
+// ReleaseSink is a no-op: the file stays open until Close.
+func (p *FileSinkProvider) ReleaseSink(target string) {}
+
+// InvalidateSink is a no-op for files.
+func (p *FileSinkProvider) InvalidateSink() {}
+
+// Close finishes the file.
+func (p *FileSinkProvider) Close() error {
+	return p.sink.Close()
+}
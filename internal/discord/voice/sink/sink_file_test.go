@@ -0,0 +1,138 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// sinePCM returns d of a 440 Hz stereo tone as 48kHz s16le PCM.
+func sinePCM(d time.Duration) []byte {
+	n := int(d.Seconds() * stream.SampleRate)
+	pcm := make([]byte, 0, n*stream.Channels*2)
+	for i := range n {
+		v := int16(8000 * math.Sin(2*math.Pi*440*float64(i)/stream.SampleRate))
+		for range stream.Channels {
+			pcm = binary.LittleEndian.AppendUint16(pcm, uint16(v))
+		}
+	}
+	return pcm
+}
+
+func TestFileSinkWritesOggOpus(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "out.ogg")
+	f := NewFileSink(path)
+
+	// Two tracks continue one logical stream: 1s + 0.5s is 75 frames.
+	for _, d := range []time.Duration{time.Second, 500 * time.Millisecond} {
+		if err := f.Stream(io.NopCloser(bytes.NewReader(sinePCM(d))), make(chan struct{})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := readOggPages(t, b)
+	if len(pages) != 2+75 {
+		t.Fatalf("got %d pages, want headers and 75 packets", len(pages))
+	}
+	last := pages[len(pages)-1]
+	if last.flags != oggFlagEOS || last.granule != 75*stream.FrameSize {
+		t.Fatalf("last page flags %x granule %d", last.flags, last.granule)
+	}
+}
+
+func TestFileSinkStopsOnStop(t *testing.T) {
+	t.Parallel()
+	f := NewFileSink(filepath.Join(t.TempDir(), "out.ogg"))
+	stop := make(chan struct{})
+	close(stop)
+	if err := f.Stream(io.NopCloser(bytes.NewReader(sinePCM(time.Second))), stop); err != stream.ErrPlaybackStopped {
+		t.Fatalf("err = %v, want ErrPlaybackStopped", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeWAV writes pcm (48kHz stereo s16le) as a WAV file.
+func writeWAV(t *testing.T, path string, pcm []byte) {
+	t.Helper()
+	var h bytes.Buffer
+	h.WriteString("RIFF")
+	_ = binary.Write(&h, binary.LittleEndian, uint32(36+len(pcm)))
+	h.WriteString("WAVEfmt ")
+	_ = binary.Write(&h, binary.LittleEndian, []any{
+		uint32(16), uint16(1), uint16(stream.Channels), uint32(stream.SampleRate),
+		uint32(stream.SampleRate * stream.Channels * 2), uint16(stream.Channels * 2), uint16(16),
+	})
+	h.WriteString("data")
+	_ = binary.Write(&h, binary.LittleEndian, uint32(len(pcm)))
+	if err := os.WriteFile(path, append(h.Bytes(), pcm...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestPlayerToFileSink runs a local file through the player into a FileSink: the full playback path
+// without a voice connection. It needs ffmpeg to decode the file.
+func TestPlayerToFileSink(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not installed")
+	}
+	dir := t.TempDir()
+	wav := filepath.Join(dir, "tone.wav")
+	writeWAV(t, wav, sinePCM(2*time.Second))
+
+	out := filepath.Join(dir, "out.ogg")
+	provider := NewFileSinkProvider(out)
+	p := player.New(provider, nil)
+	err := p.EnqueueTrackInfo(sources.TrackInfo{
+		URL:              local.URI(wav),
+		Title:            "tone",
+		SourceName:       local.Name,
+		AvailableParsers: []string{"ffmpeg-file"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.PlayNext(""); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(20 * time.Second)
+	for p.IsPlaying() {
+		if time.Now().After(deadline) {
+			t.Fatal("playback did not finish")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err := provider.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pages := readOggPages(t, b)
+	got := time.Duration(pages[len(pages)-1].granule) * time.Second / stream.SampleRate
+	if got < 1900*time.Millisecond || got > 2100*time.Millisecond {
+		t.Fatalf("file holds %v of audio, want about 2s", got)
+	}
+}
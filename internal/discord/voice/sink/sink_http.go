@@ -0,0 +1,252 @@
+package sink
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godeps/opus"
+	musicsink "github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/rs/zerolog"
+)
+
+// listenerBuffer is how many packets (20 ms each) a slow HTTP listener may lag before packets are dropped.
+const listenerBuffer = 100
+
+// StreamServer serves live Ogg Opus streams over HTTP at /<name>.ogg, one per name (the guild ID). Audio
+// comes from Publisher (packets already encoded for a voice connection) or from the server's own sinks
+// (see Sink), so a guild's playback can be heard in a browser with or without Discord.
+type StreamServer struct {
+	log     zerolog.Logger
+	mu      sync.Mutex
+	streams map[string]*broadcaster
+}
+
+// NewStreamServer creates a stream server; call Run to serve it.
+func NewStreamServer(log zerolog.Logger) *StreamServer {
+	return &StreamServer{
+		log:     log.With().Str("component", "audio_stream").Logger(),
+		streams: make(map[string]*broadcaster),
+	}
+}
+
+// stream returns the broadcaster of name, creating it for a publisher.
+func (s *StreamServer) stream(name string) *broadcaster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.streams[name]
+	if !ok {
+		b = &broadcaster{listeners: make(map[chan []byte]struct{})}
+		s.streams[name] = b
+	}
+	return b
+}
+
+// lookup returns the broadcaster of name if something publishes to it.
+func (s *StreamServer) lookup(name string) *broadcaster {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams[name]
+}
+
+// listen subscribes to the stream name if something publishes to it.
+func (s *StreamServer) listen(name string) (*broadcaster, chan []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.streams[name]
+	if !ok {
+		return nil, nil
+	}
+	return b, b.subscribe()
+}
+
+// dropIdle forgets the stream name once it has no publisher and no listener left.
+func (s *StreamServer) dropIdle(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.streams[name]; ok && b.idle() {
+		delete(s.streams, name)
+	}
+}
+
+// Publisher returns a function that sends one opus packet (a 20 ms stereo frame) to the listeners of name.
+// It never blocks. The stream stays served for as long as the server runs; name should be a known guild ID.
+func (s *StreamServer) Publisher(name string) func(packet []byte) {
+	b := s.stream(name)
+	b.mu.Lock()
+	b.publishers++
+	b.mu.Unlock()
+	return b.publish
+}
+
+// Sink returns an AudioSink that encodes PCM in real time and publishes it as the stream target, for
+// players that have no voice connection. StreamServer is a musicsink.Provider with it.
+func (s *StreamServer) Sink(target string) (musicsink.AudioSink, error) {
+	if target == "" {
+		return nil, errors.New("stream name is required")
+	}
+	b := s.stream(target)
+	b.mu.Lock()
+	b.sink = true
+	b.mu.Unlock()
+	return &HTTPSink{publish: b.publish}, nil
+}
+
+// ReleaseSink ends the sink's publishing; the stream is dropped once its last listener leaves.
+func (s *StreamServer) ReleaseSink(target string) {
+	if b := s.lookup(target); b != nil {
+		b.mu.Lock()
+		b.sink = false
+		b.mu.Unlock()
+	}
+	s.dropIdle(target)
+}
+
+// InvalidateSink is a no-op for HTTP streams.
+func (s *StreamServer) InvalidateSink() {}
+
+// ServeHTTP streams /<name>.ogg until the client goes away. Only streams with a publisher are served.
+func (s *StreamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".ogg")
+	if r.Method != http.MethodGet || !ok || name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, packets := s.listen(name)
+	if b == nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer s.dropIdle(name)
+	defer b.unsubscribe(packets)
+	s.log.Info().Str("stream", name).Str("remote", r.RemoteAddr).Msg("audio_stream_listener_joined")
+	defer s.log.Info().Str("stream", name).Str("remote", r.RemoteAddr).Msg("audio_stream_listener_left")
+
+	w.Header().Set("Content-Type", "audio/ogg")
+	w.Header().Set("Cache-Control", "no-store")
+	flusher, _ := w.(http.Flusher)
+	ogg, err := newOggOpusWriter(w, rand.Uint32())
+	if err != nil {
+		return
+	}
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case packet := <-packets:
+			if err := ogg.writePacket(packet); err != nil {
+				return
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+// Run serves the streams on addr until ctx is cancelled.
+func (s *StreamServer) Run(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: s}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Shutdown(context.Background())
+	}()
+	s.log.Info().Str("addr", addr).Msg("audio_stream_listening")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// broadcaster fans packets out to the listeners of one stream.
+type broadcaster struct {
+	mu         sync.Mutex
+	listeners  map[chan []byte]struct{}
+	publishers int  // Publisher calls
+	sink       bool // a Sink is publishing until ReleaseSink
+}
+
+func (b *broadcaster) idle() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.publishers == 0 && !b.sink && len(b.listeners) == 0
+}
+
+func (b *broadcaster) subscribe() chan []byte {
+	ch := make(chan []byte, listenerBuffer)
+	b.mu.Lock()
+	b.listeners[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+func (b *broadcaster) unsubscribe(ch chan []byte) {
+	b.mu.Lock()
+	delete(b.listeners, ch)
+	b.mu.Unlock()
+}
+
+// publish hands packet to every listener; a listener whose buffer is full misses it.
+func (b *broadcaster) publish(packet []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.listeners {
+		select {
+		case ch <- packet:
+		default:
+		}
+	}
+}
+
+// HTTPSink implements musicsink.AudioSink by encoding PCM to opus at playback speed and publishing the
+// packets to a StreamServer stream.
+type HTTPSink struct {
+	publish func(packet []byte)
+}
+
+func (h *HTTPSink) Stream(src io.ReadCloser, stop <-chan struct{}) error {
+	enc, err := opus.NewEncoder(stream.SampleRate, stream.Channels, opus.AppAudio)
+	if err != nil {
+		return fmt.Errorf("encoder error: %w", err)
+	}
+	pcmBuf := make([]byte, stream.FrameSize*stream.Channels*2)
+	intBuf := make([]int16, stream.FrameSize*stream.Channels)
+	opusBuf := make([]byte, 4096)
+
+	// Pace to real time like a voice connection would, so listeners hear the track at its speed.
+	tick := time.NewTicker(time.Duration(frameSec * float64(time.Second)))
+	defer tick.Stop()
+	for {
+		select {
+		case <-stop:
+			return stream.ErrPlaybackStopped
+		case <-tick.C:
+		}
+		if _, err := io.ReadFull(src, pcmBuf); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return nil
+			}
+			return fmt.Errorf("read error: %w", err)
+		}
+		for i := range intBuf {
+			intBuf[i] = int16(binary.LittleEndian.Uint16(pcmBuf[i*2 : i*2+2]))
+		}
+		n, err := enc.Encode(intBuf, opusBuf)
+		if err != nil {
+			return fmt.Errorf("encode error: %w", err)
+		}
+		h.publish(append([]byte(nil), opusBuf[:n]...))
+	}
+}
@@ -0,0 +1,91 @@
+package sink
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestStreamServerServesPublishedPackets(t *testing.T) {
+	t.Parallel()
+	s := NewStreamServer(zerolog.Nop())
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	publish := s.Publisher("g1")
+
+	resp, err := http.Get(srv.URL + "/g1.ogg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "audio/ogg" {
+		t.Fatalf("content type %q", ct)
+	}
+
+	// The listener subscribes before the headers arrive; keep publishing until its pages show up.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(5 * time.Millisecond):
+				publish([]byte{0xfc, 0xff, 0xfe})
+			}
+		}
+	}()
+
+	// Head, tags and one packet page (the newest packet is held back until the next arrives).
+	want := 2*28 + 19 + 8 + 4 + len("server-domme") + 4 + 28 + 3
+	b := make([]byte, want)
+	if _, err := io.ReadFull(resp.Body, b); err != nil {
+		t.Fatal(err)
+	}
+	pages := readOggPages(t, b)
+	if !bytes.HasPrefix(pages[0].data, []byte("OpusHead")) || !bytes.Equal(pages[2].data, []byte{0xfc, 0xff, 0xfe}) {
+		t.Fatalf("pages = %+v", pages)
+	}
+}
+
+func TestStreamServerRejectsOtherPaths(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(NewStreamServer(zerolog.Nop()))
+	defer srv.Close()
+	// g1.ogg has no publisher, so it is not created on demand either.
+	for _, path := range []string{"/", "/g1", "/a/b.ogg", "/g1.ogg"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("%s: status %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestStreamServerDropsReleasedSinkStream(t *testing.T) {
+	t.Parallel()
+	s := NewStreamServer(zerolog.Nop())
+	if _, err := s.Sink("g1"); err != nil {
+		t.Fatal(err)
+	}
+	s.Publisher("g2")
+	if len(s.streams) != 2 {
+		t.Fatalf("streams = %d, want 2", len(s.streams))
+	}
+	s.ReleaseSink("g1")
+	s.ReleaseSink("g2")
+	if _, ok := s.streams["g1"]; ok {
+		t.Fatal("released sink stream kept")
+	}
+	if _, ok := s.streams["g2"]; !ok {
+		t.Fatal("publisher stream dropped")
+	}
+}
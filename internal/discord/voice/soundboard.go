@@ -0,0 +1,99 @@
+package voice
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// SoundboardClipMaxDuration bounds how much of a soundboard clip is played; longer files are cut off.
+const SoundboardClipMaxDuration = 10 * time.Second
+
+// ErrSoundboardOtherChannel is returned by PlaySoundboardClip when the bot is connected to another voice
+// channel of the guild.
+var ErrSoundboardOtherChannel = errors.New("the bot is in another voice channel")
+
+// SoundboardDir returns the guild's soundboard directory ("" when the soundboard is disabled).
+func (s *Service) SoundboardDir(guildID string) string {
+	if s.cfg == nil || s.cfg.SoundboardDir == "" || guildID == "" {
+		return ""
+	}
+	return filepath.Join(s.cfg.SoundboardDir, guildID)
+}
+
+// PlaySoundboardClip plays file from the guild's soundboard directory in channelID. Over playing music the
+// clip is mixed in with the music ducked under it; the queue and current track are left alone.
+func (s *Service) PlaySoundboardClip(guildID, channelID, file string) error {
+	dir := s.SoundboardDir(guildID)
+	if dir == "" {
+		return errors.New("the soundboard is disabled")
+	}
+	path := filepath.Join(dir, file)
+	if !local.Within(dir, path) {
+		return errors.New("clip file is outside the soundboard directory")
+	}
+
+	provider := s.sinkProvider(guildID)
+	if provider == nil {
+		return errors.New("voice service not available")
+	}
+	if ch := provider.ChannelID(); ch != "" && ch != channelID {
+		return ErrSoundboardOtherChannel
+	}
+
+	pcm, err := decodeClip(dir, path, SoundboardClipMaxDuration)
+	if err != nil {
+		return err
+	}
+	s.log.Info().Str("guild_id", guildID).Str("file", file).Dur("length", clipLength(pcm)).Msg("soundboard_clip")
+	return provider.PlayClip(channelID, pcm)
+}
+
+// decodeClip decodes up to limit of the audio file at path, inside dir, into 48kHz stereo samples.
+func decodeClip(dir, path string, limit time.Duration) ([]int16, error) {
+	track := &parsers.TrackParse{
+		URL:        local.URI(path),
+		Title:      local.Title(path),
+		SourceInfo: sources.TrackInfo{AvailableParsers: []string{"ffmpeg-file"}},
+		LocalRoot:  dir,
+	}
+	ts, cleanup, _, err := stream.OpenTrack(track, 0)
+	if err != nil {
+		if cleanup != nil {
+			cleanup()
+		}
+		return nil, fmt.Errorf("failed to open clip: %w", err)
+	}
+	defer func() {
+		_ = ts.Close()
+		if cleanup != nil {
+			cleanup()
+		}
+	}()
+
+	maxBytes := int64(limit.Seconds()*stream.SampleRate) * stream.Channels * 2
+	raw, err := io.ReadAll(io.LimitReader(ts, maxBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode clip: %w", err)
+	}
+	if len(raw) < 2*stream.Channels {
+		return nil, errors.New("clip has no audio")
+	}
+	pcm := make([]int16, len(raw)/2)
+	for i := range pcm {
+		pcm[i] = int16(binary.LittleEndian.Uint16(raw[i*2:]))
+	}
+	return pcm, nil
+}
+
+func clipLength(pcm []int16) time.Duration {
+	return time.Duration(len(pcm)/stream.Channels) * time.Second / stream.SampleRate
+}
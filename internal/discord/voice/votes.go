@@ -0,0 +1,86 @@
+package voice
+
+import (
+	"errors"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/player"
+)
+
+// ErrAlreadyVoted is returned by VoteSkip when the user has already voted to skip the current track.
+var ErrAlreadyVoted = errors.New("already voted to skip this track")
+
+// skipVote is the tally for one play of a track; it is dropped as soon as another play starts.
+type skipVote struct {
+	play   playKey
+	voters map[string]struct{}
+	needed int
+}
+
+// playKey identifies one play of a track. Seeks and stream restarts replace the player's track value
+// but keep its URL and start time, so they do not reset the tally.
+type playKey struct {
+	url     string
+	started time.Time
+}
+
+// currentPlay returns the key of the guild's current play; ok is false when nothing plays.
+func (s *Service) currentPlay(guildID string) (key playKey, ok bool) {
+	p := s.ExistingPlayer(guildID)
+	if p == nil {
+		return playKey{}, false
+	}
+	track := p.CurrentTrack()
+	if track == nil {
+		return playKey{}, false
+	}
+	return playKey{url: track.URL, started: p.PlayStartedAt()}, true
+}
+
+// VoteSkip adds userID's vote to skip the guild's current track. needed is the threshold for the current
+// listeners and replaces the previous one, so the bar follows people joining and leaving. passed reports
+// that votes reached needed; the tally is then cleared and the caller performs the skip.
+func (s *Service) VoteSkip(guildID, userID string, needed int) (votes int, passed bool, err error) {
+	play, ok := s.currentPlay(guildID)
+	if !ok {
+		return 0, false, player.ErrNoTrackPlaying
+	}
+
+	s.votesMu.Lock()
+	defer s.votesMu.Unlock()
+	if s.skipVotes == nil {
+		s.skipVotes = make(map[string]*skipVote)
+	}
+	v, ok := s.skipVotes[guildID]
+	if !ok || !v.play.equal(play) {
+		v = &skipVote{play: play, voters: make(map[string]struct{})}
+		s.skipVotes[guildID] = v
+	}
+	if _, dup := v.voters[userID]; dup {
+		return len(v.voters), false, ErrAlreadyVoted
+	}
+	v.voters[userID] = struct{}{}
+	v.needed = needed
+	if len(v.voters) >= needed {
+		delete(s.skipVotes, guildID)
+		return len(v.voters), true, nil
+	}
+	return len(v.voters), false, nil
+}
+
+// PanelState returns the service-side state shown on the guild's panel.
+func (s *Service) PanelState(guildID string) PanelState {
+	play, playing := s.currentPlay(guildID)
+
+	s.votesMu.Lock()
+	defer s.votesMu.Unlock()
+	v, ok := s.skipVotes[guildID]
+	if !ok || !playing || !v.play.equal(play) {
+		return PanelState{}
+	}
+	return PanelState{SkipVotes: len(v.voters), SkipVotesNeeded: v.needed}
+}
+
+func (k playKey) equal(o playKey) bool {
+	return k.url == o.url && k.started.Equal(o.started)
+}
@@ -0,0 +1,176 @@
+package discord
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// voiceWatchInterval is how often connected guilds are re-checked between voice state events
+// (the idle timer has no event of its own).
+const voiceWatchInterval = 15 * time.Second
+
+type disconnectReason string
+
+const (
+	disconnectEmpty disconnectReason = "empty"
+	disconnectIdle  disconnectReason = "idle"
+)
+
+func (r disconnectReason) notice() string {
+	if r == disconnectIdle {
+		return "👋 Left the voice channel: nothing has been playing for a while."
+	}
+	return "👋 Left the voice channel: everyone else has left."
+}
+
+// voiceActivity is when a guild's channel became empty / its player became idle (zero while not).
+type voiceActivity struct {
+	emptySince time.Time
+	idleSince  time.Time
+}
+
+// voiceWatcher decides when the bot should leave a voice channel: after emptyAfter with no listeners,
+// or after idleAfter with nothing playing and an empty queue. A zero duration disables that rule.
+type voiceWatcher struct {
+	emptyAfter time.Duration
+	idleAfter  time.Duration
+
+	mu     sync.Mutex
+	guilds map[string]*voiceActivity
+}
+
+func newVoiceWatcher(emptyAfter, idleAfter time.Duration) *voiceWatcher {
+	return &voiceWatcher{
+		emptyAfter: emptyAfter,
+		idleAfter:  idleAfter,
+		guilds:     make(map[string]*voiceActivity),
+	}
+}
+
+// observe records the guild's current state and reports whether (and why) the bot should disconnect.
+// The guild is forgotten once a disconnect is reported.
+func (w *voiceWatcher) observe(guildID string, now time.Time, listeners int, idle bool) (disconnectReason, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	a, ok := w.guilds[guildID]
+	if !ok {
+		a = &voiceActivity{}
+		w.guilds[guildID] = a
+	}
+	a.emptySince = sinceOrZero(a.emptySince, now, listeners == 0)
+	a.idleSince = sinceOrZero(a.idleSince, now, idle)
+
+	switch {
+	case w.emptyAfter > 0 && !a.emptySince.IsZero() && now.Sub(a.emptySince) >= w.emptyAfter:
+		delete(w.guilds, guildID)
+		return disconnectEmpty, true
+	case w.idleAfter > 0 && !a.idleSince.IsZero() && now.Sub(a.idleSince) >= w.idleAfter:
+		delete(w.guilds, guildID)
+		return disconnectIdle, true
+	}
+	return "", false
+}
+
+// forget drops the guild's timers (the bot is no longer connected there).
+func (w *voiceWatcher) forget(guildID string) {
+	w.mu.Lock()
+	delete(w.guilds, guildID)
+	w.mu.Unlock()
+}
+
+func sinceOrZero(since, now time.Time, active bool) time.Time {
+	switch {
+	case !active:
+		return time.Time{}
+	case since.IsZero():
+		return now
+	default:
+		return since
+	}
+}
+
+// countListeners counts the users in channelID, excluding the bot itself and other bots.
+func countListeners(s *discordgo.Session, guild *discordgo.Guild, channelID string) int {
+	selfID := ""
+	if s.State != nil && s.State.User != nil {
+		selfID = s.State.User.ID
+	}
+	n := 0
+	for _, vs := range guild.VoiceStates {
+		if vs.ChannelID != channelID || vs.UserID == selfID {
+			continue
+		}
+		if isBotUser(s, guild.ID, vs) {
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+func isBotUser(s *discordgo.Session, guildID string, vs *discordgo.VoiceState) bool {
+	if vs.Member != nil && vs.Member.User != nil {
+		return vs.Member.User.Bot
+	}
+	if m, err := s.State.Member(guildID, vs.UserID); err == nil && m.User != nil {
+		return m.User.Bot
+	}
+	return false
+}
+
+// onVoiceStateUpdate re-checks the guild right away so the empty-channel timer starts when the last
+// listener leaves, not on the next sweep.
+func (b *Bot) onVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceStateUpdate) {
+	if b.voice == nil || v.VoiceState == nil {
+		return
+	}
+	channelID, ok := b.voice.ConnectedChannels()[v.GuildID]
+	if !ok {
+		b.voiceWatch.forget(v.GuildID)
+		return
+	}
+	b.checkVoiceGuild(s, v.GuildID, channelID, time.Now())
+}
+
+// runVoiceWatcher sweeps all connected guilds until ctx is cancelled.
+func (b *Bot) runVoiceWatcher(ctx context.Context) {
+	if b.voice == nil {
+		return
+	}
+	ticker := time.NewTicker(voiceWatchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			b.mu.RLock()
+			s := b.dg
+			b.mu.RUnlock()
+			if s == nil {
+				continue
+			}
+			for guildID, channelID := range b.voice.ConnectedChannels() {
+				b.checkVoiceGuild(s, guildID, channelID, now)
+			}
+		}
+	}
+}
+
+func (b *Bot) checkVoiceGuild(s *discordgo.Session, guildID, channelID string, now time.Time) {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return
+	}
+	reason, ok := b.voiceWatch.observe(guildID, now, countListeners(s, guild, channelID), b.voice.IsIdle(guildID))
+	if !ok {
+		return
+	}
+	b.log.Info().Str("guild_id", guildID).Str("channel_id", channelID).Str("reason", string(reason)).Msg("voice_auto_disconnect")
+	// Disconnect waits for the playback goroutine; keep it off the gateway event handler.
+	go b.voice.Disconnect(guildID, reason.notice())
+}
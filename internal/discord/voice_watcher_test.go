@@ -0,0 +1,48 @@
+package discord
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVoiceWatcherEmptyChannel(t *testing.T) {
+	w := newVoiceWatcher(2*time.Minute, 0)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if _, ok := w.observe("g", start, 0, false); ok {
+		t.Fatal("disconnect reported as soon as the channel emptied")
+	}
+	if _, ok := w.observe("g", start.Add(time.Minute), 0, false); ok {
+		t.Fatal("disconnect reported before the grace period")
+	}
+	reason, ok := w.observe("g", start.Add(2*time.Minute), 0, false)
+	if !ok || reason != disconnectEmpty {
+		t.Fatalf("observe = %q, %v; want %q, true", reason, ok, disconnectEmpty)
+	}
+}
+
+func TestVoiceWatcherListenerReturnResetsTimer(t *testing.T) {
+	w := newVoiceWatcher(2*time.Minute, 0)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	w.observe("g", start, 0, false)
+	w.observe("g", start.Add(90*time.Second), 1, false)
+	if _, ok := w.observe("g", start.Add(3*time.Minute), 0, false); ok {
+		t.Fatal("timer was not reset when a listener came back")
+	}
+}
+
+func TestVoiceWatcherIdle(t *testing.T) {
+	w := newVoiceWatcher(0, 5*time.Minute)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	w.observe("g", start, 0, true)
+	if _, ok := w.observe("g", start.Add(time.Hour), 0, false); ok {
+		t.Fatal("disconnect reported while playing with empty-channel rule disabled")
+	}
+	w.observe("g", start.Add(time.Hour), 1, true)
+	reason, ok := w.observe("g", start.Add(time.Hour+5*time.Minute), 1, true)
+	if !ok || reason != disconnectIdle {
+		t.Fatalf("observe = %q, %v; want %q, true", reason, ok, disconnectIdle)
+	}
+}
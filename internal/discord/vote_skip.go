@@ -0,0 +1,43 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/keshon/melodix/pkg/music/player"
+	"github.com/keshon/server-domme/internal/discord/voice"
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+// VoteSkip records userID's vote to skip the guild's current track. The threshold is the guild's vote
+// percentage of the listeners (bots excluded) in the bot's voice channel at the time of the vote.
+func (b *Bot) VoteSkip(guildID, userID string) (votes, needed int, passed bool, err error) {
+	if b.voice == nil {
+		return 0, 0, false, fmt.Errorf("voice service not available")
+	}
+	channelID, ok := b.voice.ConnectedChannels()[guildID]
+	if !ok {
+		return 0, 0, false, player.ErrNoTrackPlaying
+	}
+	guild, err := b.dg.State.Guild(guildID)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("error retrieving guild: %w", err)
+	}
+
+	pct := domain.DefaultMusicSkipVotePercent
+	if b.storage != nil {
+		if v, err := b.storage.MusicSkipVotePercent(guildID); err == nil {
+			pct = v
+		}
+	}
+	needed = domain.SkipVotesNeeded(countListeners(b.dg, guild, channelID), pct)
+	votes, passed, err = b.voice.VoteSkip(guildID, userID, needed)
+	return votes, needed, passed, err
+}
+
+// PanelState returns the guild's panel state kept by the voice service (delegates to voice service).
+func (b *Bot) PanelState(guildID string) voice.PanelState {
+	if b.voice == nil {
+		return voice.PanelState{}
+	}
+	return b.voice.PanelState(guildID)
+}
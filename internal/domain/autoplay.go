@@ -0,0 +1,62 @@
+package domain
+
+// AutoplayRecentWindow is how many of the latest history rows autoplay avoids repeating.
+const AutoplayRecentWindow = 10
+
+// PickAutoplay chooses a track to play next from the guild history (oldest-first, as stored).
+// Candidates are distinct URLs from AggregatePlaybackCounts, weighted by play count, excluding
+// lastURL and anything among the latest `recent` rows. If that leaves nothing, any URL other than
+// lastURL is allowed. intn must return a value in [0, n) (e.g. rand.IntN).
+// The returned row is the URL's representative (latest) playback so it carries parser metadata.
+func PickAutoplay(history []MusicPlayback, lastURL string, recent int, intn func(n int) int) (MusicPlayback, bool) {
+	if len(history) == 0 {
+		return MusicPlayback{}, false
+	}
+
+	skip := map[string]bool{lastURL: true}
+	if recent > len(history) {
+		recent = len(history)
+	}
+	for _, row := range history[len(history)-recent:] {
+		skip[row.URL] = true
+	}
+
+	counts := AggregatePlaybackCounts(history)
+	candidates := make([]PlaybackCountRow, 0, len(counts))
+	for _, c := range counts {
+		if c.URL != "" && !skip[c.URL] {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		for _, c := range counts {
+			if c.URL != "" && c.URL != lastURL {
+				candidates = append(candidates, c)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return MusicPlayback{}, false
+	}
+
+	total := 0
+	for _, c := range candidates {
+		total += c.Count
+	}
+	n := intn(total)
+	chosen := candidates[len(candidates)-1]
+	for _, c := range candidates {
+		if n < c.Count {
+			chosen = c
+			break
+		}
+		n -= c.Count
+	}
+
+	for _, row := range history {
+		if row.ID == chosen.RepresentativeID {
+			return row, true
+		}
+	}
+	return MusicPlayback{}, false
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPickAutoplay(t *testing.T) {
+	t.Parallel()
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := []MusicPlayback{
+		{ID: 1, PlayedAt: t0, URL: "https://a.com", Title: "A"},
+		{ID: 2, PlayedAt: t0.Add(time.Hour), URL: "https://a.com", Title: "A"},
+		{ID: 3, PlayedAt: t0.Add(2 * time.Hour), URL: "https://b.com", Title: "B"},
+		{ID: 4, PlayedAt: t0.Add(3 * time.Hour), URL: "https://c.com", Title: "C"},
+	}
+	first := func(int) int { return 0 }
+	last := func(n int) int { return n - 1 }
+
+	// c.com was just played and b.com is in the recent window; only a.com is left.
+	got, ok := PickAutoplay(h, "https://c.com", 2, last)
+	if !ok || got.ID != 2 {
+		t.Fatalf("recent window: got %+v ok=%v, want id 2", got, ok)
+	}
+
+	// No recent window: weights a=2, b=1; the tail of the range lands on b.com.
+	got, ok = PickAutoplay(h, "https://c.com", 0, last)
+	if !ok || got.URL != "https://b.com" {
+		t.Fatalf("weighted tail: got %+v", got)
+	}
+	got, ok = PickAutoplay(h, "https://c.com", 0, first)
+	if !ok || got.URL != "https://a.com" {
+		t.Fatalf("weighted head: got %+v", got)
+	}
+
+	// Everything is recent: fall back to any URL except the last one.
+	got, ok = PickAutoplay(h, "https://a.com", len(h), last)
+	if !ok || got.URL == "https://a.com" {
+		t.Fatalf("fallback: got %+v ok=%v", got, ok)
+	}
+
+	if _, ok := PickAutoplay(h[:1], "https://a.com", 0, first); ok {
+		t.Fatal("single URL equal to last should yield nothing")
+	}
+	if _, ok := PickAutoplay(nil, "", 0, first); ok {
+		t.Fatal("empty history should yield nothing")
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+// MaxMusicCrossfadeSeconds bounds the crossfade between tracks (0 disables it).
+const MaxMusicCrossfadeSeconds = 12
+
+// ClampMusicCrossfadeSeconds limits a crossfade length to 0..MaxMusicCrossfadeSeconds.
+func ClampMusicCrossfadeSeconds(sec int) int {
+	if sec < 0 {
+		return 0
+	}
+	if sec > MaxMusicCrossfadeSeconds {
+		return MaxMusicCrossfadeSeconds
+	}
+	return sec
+}
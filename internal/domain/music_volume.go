@@ -0,0 +1,18 @@
+package domain
+
+// Volume bounds for guild playback, in percent of the source level.
+const (
+	DefaultMusicVolume = 100
+	MaxMusicVolume     = 200
+)
+
+// ClampMusicVolume limits a volume percentage to 0..MaxMusicVolume.
+func ClampMusicVolume(pct int) int {
+	if pct < 0 {
+		return 0
+	}
+	if pct > MaxMusicVolume {
+		return MaxMusicVolume
+	}
+	return pct
+}
@@ -0,0 +1,29 @@
+package domain
+
+// Vote-skip threshold bounds, in percent of the listeners in the bot's voice channel.
+const (
+	DefaultMusicSkipVotePercent = 50
+	MinMusicSkipVotePercent     = 1
+	MaxMusicSkipVotePercent     = 100
+)
+
+// ClampMusicSkipVotePercent limits a vote-skip threshold to MinMusicSkipVotePercent..MaxMusicSkipVotePercent.
+func ClampMusicSkipVotePercent(pct int) int {
+	if pct < MinMusicSkipVotePercent {
+		return MinMusicSkipVotePercent
+	}
+	if pct > MaxMusicSkipVotePercent {
+		return MaxMusicSkipVotePercent
+	}
+	return pct
+}
+
+// SkipVotesNeeded returns the votes that pass a skip among listeners at pct percent: rounded up, at least one.
+func SkipVotesNeeded(listeners, pct int) int {
+	pct = ClampMusicSkipVotePercent(pct)
+	n := (listeners*pct + 99) / 100
+	if n < 1 {
+		return 1
+	}
+	return n
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "testing"
+
+func TestSkipVotesNeeded(t *testing.T) {
+	t.Parallel()
+	cases := []struct{ listeners, pct, want int }{
+		{0, 50, 1},
+		{1, 50, 1},
+		{3, 50, 2},
+		{4, 50, 2},
+		{5, 100, 5},
+		{10, 0, 1}, // clamped to 1%
+		{3, 250, 3},
+	}
+	for _, c := range cases {
+		if got := SkipVotesNeeded(c.listeners, c.pct); got != c.want {
+			t.Fatalf("SkipVotesNeeded(%d, %d) = %d, want %d", c.listeners, c.pct, got, c.want)
+		}
+	}
+}
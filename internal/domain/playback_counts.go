@@ -11,6 +11,7 @@ type PlaybackCountRow struct {
 	URL              string
 	Title            string
 	Count            int
+	Skips            int // plays that ended with MusicEndSkipped
 	LastPlayed       time.Time
 }
 
@@ -22,11 +23,16 @@ func AggregatePlaybackCounts(history []MusicPlayback) []PlaybackCountRow {
 		url      string
 		title    string
 		count    int
+		skips    int
 		last     time.Time
 	}
 	byURL := make(map[string]*agg)
 	for _, row := range history {
 		u := row.URL
+		skipped := 0
+		if row.EndReason == MusicEndSkipped {
+			skipped = 1
+		}
 		a, ok := byURL[u]
 		if !ok {
 			byURL[u] = &agg{
@@ -34,11 +40,13 @@ func AggregatePlaybackCounts(history []MusicPlayback) []PlaybackCountRow {
 				url:      row.URL,
 				title:    row.Title,
 				count:    1,
+				skips:    skipped,
 				last:     row.PlayedAt,
 			}
 			continue
 		}
 		a.count++
+		a.skips += skipped
 		if row.PlayedAt.After(a.last) {
 			a.last = row.PlayedAt
 			a.latestID = row.ID
@@ -60,6 +68,7 @@ func AggregatePlaybackCounts(history []MusicPlayback) []PlaybackCountRow {
 			URL:              a.url,
 			Title:            a.title,
 			Count:            a.count,
+			Skips:            a.skips,
 			LastPlayed:       a.last,
 		})
 	}
@@ -71,3 +80,36 @@ func AggregatePlaybackCounts(history []MusicPlayback) []PlaybackCountRow {
 	})
 	return out
 }
+
+// MostSkipped keeps the rows with at least one skip, sorted by skips desc, then skip share of plays desc,
+// then last played desc.
+func MostSkipped(counts []PlaybackCountRow) []PlaybackCountRow {
+	out := make([]PlaybackCountRow, 0, len(counts))
+	for _, r := range counts {
+		if r.Skips > 0 {
+			out = append(out, r)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Skips != out[j].Skips {
+			return out[i].Skips > out[j].Skips
+		}
+		// Compare Skips/Count without division.
+		if li, lj := out[i].Skips*out[j].Count, out[j].Skips*out[i].Count; li != lj {
+			return li > lj
+		}
+		return out[i].LastPlayed.After(out[j].LastPlayed)
+	})
+	return out
+}
+
+// FilterPlaybackByRequester keeps the rows requested by userID, in order.
+func FilterPlaybackByRequester(history []MusicPlayback, userID string) []MusicPlayback {
+	out := make([]MusicPlayback, 0, len(history))
+	for _, row := range history {
+		if row.RequestedBy == userID {
+			out = append(out, row)
+		}
+	}
+	return out
+}
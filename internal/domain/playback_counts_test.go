@@ -26,3 +26,31 @@ func TestAggregatePlaybackCounts(t *testing.T) {
 		t.Fatalf("second row: %+v", rows[1])
 	}
 }
+
+func TestMostSkipped(t *testing.T) {
+	t.Parallel()
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := []MusicPlayback{
+		{ID: 1, PlayedAt: t1, URL: "https://a.com", EndReason: MusicEndSkipped},
+		{ID: 2, PlayedAt: t1, URL: "https://a.com", EndReason: MusicEndFinished},
+		{ID: 3, PlayedAt: t1, URL: "https://b.com", EndReason: MusicEndSkipped},
+		{ID: 4, PlayedAt: t1, URL: "https://c.com", EndReason: MusicEndFinished},
+	}
+	rows := MostSkipped(AggregatePlaybackCounts(h))
+	if len(rows) != 2 {
+		t.Fatalf("want 2 skipped urls, got %+v", rows)
+	}
+	// Both skipped once; b.com was skipped on every play, a.com on half.
+	if rows[0].URL != "https://b.com" || rows[1].URL != "https://a.com" || rows[1].Skips != 1 || rows[1].Count != 2 {
+		t.Fatalf("rows: %+v", rows)
+	}
+}
+
+func TestFilterPlaybackByRequester(t *testing.T) {
+	t.Parallel()
+	h := []MusicPlayback{{ID: 1, RequestedBy: "u1"}, {ID: 2, RequestedBy: "u2"}, {ID: 3, RequestedBy: "u1"}}
+	got := FilterPlaybackByRequester(h, "u1")
+	if len(got) != 2 || got[0].ID != 1 || got[1].ID != 3 {
+		t.Fatalf("filtered: %+v", got)
+	}
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// PlaybackBucketSize is the width of a PlaybackBucket.
+type PlaybackBucketSize string
+
+const (
+	BucketDay  PlaybackBucketSize = "day"
+	BucketWeek PlaybackBucketSize = "week" // ISO weeks, starting Monday
+)
+
+// PlaybackBucket is the plays and listened time within one day or week (UTC).
+type PlaybackBucket struct {
+	Start    time.Time
+	Plays    int
+	Listened time.Duration
+}
+
+// PlaybackRequesterRow is one member's share of the history.
+type PlaybackRequesterRow struct {
+	UserID   string
+	Plays    int
+	Listened time.Duration
+}
+
+// PlaybackBetween keeps rows played in [from, to). A zero from or to leaves that side open.
+func PlaybackBetween(history []MusicPlayback, from, to time.Time) []MusicPlayback {
+	out := make([]MusicPlayback, 0, len(history))
+	for _, row := range history {
+		if !from.IsZero() && row.PlayedAt.Before(from) {
+			continue
+		}
+		if !to.IsZero() && !row.PlayedAt.Before(to) {
+			continue
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+// TotalListened sums recorded listened time. Rows recorded before listened time was tracked count as zero.
+func TotalListened(history []MusicPlayback) time.Duration {
+	var total time.Duration
+	for _, row := range history {
+		total += row.Listened()
+	}
+	return total
+}
+
+// AggregateRequesters groups history by requester, skipping rows without one.
+// Sort: plays desc, then listened desc, then user id.
+func AggregateRequesters(history []MusicPlayback) []PlaybackRequesterRow {
+	byUser := make(map[string]*PlaybackRequesterRow)
+	for _, row := range history {
+		if row.RequestedBy == "" {
+			continue
+		}
+		r, ok := byUser[row.RequestedBy]
+		if !ok {
+			r = &PlaybackRequesterRow{UserID: row.RequestedBy}
+			byUser[row.RequestedBy] = r
+		}
+		r.Plays++
+		r.Listened += row.Listened()
+	}
+
+	out := make([]PlaybackRequesterRow, 0, len(byUser))
+	for _, r := range byUser {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Plays != out[j].Plays {
+			return out[i].Plays > out[j].Plays
+		}
+		if out[i].Listened != out[j].Listened {
+			return out[i].Listened > out[j].Listened
+		}
+		return out[i].UserID < out[j].UserID
+	})
+	return out
+}
+
+// BucketStart returns the start of the UTC day or ISO week containing t.
+func BucketStart(t time.Time, size PlaybackBucketSize) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if size != BucketWeek {
+		return day
+	}
+	// Weekday counts from Sunday; shift so Monday is 0.
+	back := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -back)
+}
+
+// BucketPlayback groups history into day or week buckets, oldest first. Empty buckets between the first and
+// last play are included so the series has no gaps.
+func BucketPlayback(history []MusicPlayback, size PlaybackBucketSize) []PlaybackBucket {
+	if len(history) == 0 {
+		return nil
+	}
+	byStart := make(map[time.Time]*PlaybackBucket)
+	var first, last time.Time
+	for _, row := range history {
+		start := BucketStart(row.PlayedAt, size)
+		b, ok := byStart[start]
+		if !ok {
+			b = &PlaybackBucket{Start: start}
+			byStart[start] = b
+		}
+		b.Plays++
+		b.Listened += row.Listened()
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+
+	step := 1
+	if size == BucketWeek {
+		step = 7
+	}
+	var out []PlaybackBucket
+	for start := first; !start.After(last); start = start.AddDate(0, 0, step) {
+		if b, ok := byStart[start]; ok {
+			out = append(out, *b)
+		} else {
+			out = append(out, PlaybackBucket{Start: start})
+		}
+	}
+	return out
+}
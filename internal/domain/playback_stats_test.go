@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlaybackBetween(t *testing.T) {
+	t.Parallel()
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	t3 := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	h := []MusicPlayback{{ID: 1, PlayedAt: t1}, {ID: 2, PlayedAt: t2}, {ID: 3, PlayedAt: t3}}
+	got := PlaybackBetween(h, t2, t3)
+	if len(got) != 1 || got[0].ID != 2 {
+		t.Fatalf("bounded: %+v", got)
+	}
+	if got := PlaybackBetween(h, time.Time{}, t2); len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("open from: %+v", got)
+	}
+	if got := PlaybackBetween(h, t2, time.Time{}); len(got) != 2 {
+		t.Fatalf("open to: %+v", got)
+	}
+}
+
+func TestAggregateRequesters(t *testing.T) {
+	t.Parallel()
+	h := []MusicPlayback{
+		{ID: 1, RequestedBy: "u1", ListenedMs: 1000},
+		{ID: 2, RequestedBy: "u2", ListenedMs: 5000},
+		{ID: 3, RequestedBy: "u1", ListenedMs: 1000},
+		{ID: 4},
+	}
+	rows := AggregateRequesters(h)
+	if len(rows) != 2 {
+		t.Fatalf("want 2 requesters, got %+v", rows)
+	}
+	if rows[0].UserID != "u1" || rows[0].Plays != 2 || rows[0].Listened != 2*time.Second {
+		t.Fatalf("first row: %+v", rows[0])
+	}
+	if TotalListened(h) != 7*time.Second {
+		t.Fatalf("total listened: %v", TotalListened(h))
+	}
+}
+
+func TestBucketStartWeekIsMonday(t *testing.T) {
+	t.Parallel()
+	// 2026-03-15 is a Sunday; its ISO week starts Monday 2026-03-09.
+	got := BucketStart(time.Date(2026, 3, 15, 22, 0, 0, 0, time.UTC), BucketWeek)
+	if want := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+func TestBucketPlaybackFillsGaps(t *testing.T) {
+	t.Parallel()
+	h := []MusicPlayback{
+		{ID: 1, PlayedAt: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC), ListenedMs: 60000},
+		{ID: 2, PlayedAt: time.Date(2026, 1, 1, 20, 0, 0, 0, time.UTC)},
+		{ID: 3, PlayedAt: time.Date(2026, 1, 3, 8, 0, 0, 0, time.UTC)},
+	}
+	b := BucketPlayback(h, BucketDay)
+	if len(b) != 3 {
+		t.Fatalf("want 3 day buckets, got %+v", b)
+	}
+	if b[0].Plays != 2 || b[0].Listened != time.Minute || b[1].Plays != 0 || b[2].Plays != 1 {
+		t.Fatalf("buckets: %+v", b)
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+// Target returns the channel or category the rule covers.
+func (j PurgeJob) Target() string {
+	if j.CategoryID != "" {
+		return j.CategoryID
+	}
+	return j.ChannelID
+}
+
+// Key returns the rule's key in Record.PurgeJobs.
+func (j PurgeJob) Key() string {
+	return PurgeJobKey(j.Target(), j.Name)
+}
+
+// PurgeJobKey is the Record.PurgeJobs key of the rule name on a channel or category.
+func PurgeJobKey(targetID, name string) string {
+	return targetID + "/" + name
+}
+
+// Names of the rules /purge now and /purge auto create when none is given.
+const (
+	PurgeRuleDelayed = "now"
+	PurgeRuleDefault = "default"
+)
+
+// NormalizePurgeJobs names and rekeys jobs stored before rules had names, when they were keyed by
+// channel ID alone. It reports whether it changed anything.
+func NormalizePurgeJobs(jobs map[string]PurgeJob) bool {
+	changed := false
+	for key, job := range jobs {
+		if job.Name == "" {
+			job.Name = PurgeRuleDefault
+			if job.Mode == "delayed" {
+				job.Name = PurgeRuleDelayed
+			}
+		}
+		if key != job.Key() {
+			delete(jobs, key)
+			jobs[job.Key()] = job
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "testing"
+
+func TestNormalizePurgeJobs(t *testing.T) {
+	jobs := map[string]PurgeJob{
+		"c1":        {ChannelID: "c1", Mode: "recurring", OlderThan: "1d"},
+		"c2":        {ChannelID: "c2", Mode: "delayed"},
+		"cat/bots":  {CategoryID: "cat", Name: "bots", Mode: "recurring"},
+		"c1/weekly": {ChannelID: "c1", Name: "weekly", Mode: "recurring"},
+	}
+	if !NormalizePurgeJobs(jobs) {
+		t.Fatal("legacy jobs were not rekeyed")
+	}
+	for _, key := range []string{"c1/default", "c2/now", "cat/bots", "c1/weekly"} {
+		if _, ok := jobs[key]; !ok {
+			t.Fatalf("missing %s in %v", key, jobs)
+		}
+	}
+	if len(jobs) != 4 || jobs["c1/default"].OlderThan != "1d" {
+		t.Fatalf("jobs = %v", jobs)
+	}
+	if NormalizePurgeJobs(jobs) {
+		t.Fatal("normalized jobs changed again")
+	}
+}
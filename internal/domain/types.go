@@ -14,14 +14,32 @@ type CommandHistory struct {
 	Datetime    time.Time `json:"datetime"`
 }
 
+// PurgeJob is a purge rule of a guild. Several rules may share a channel or category; Name tells them
+// apart (see PurgeJobKey).
 type PurgeJob struct {
-	ChannelID  string    `json:"channel_id"`
-	GuildID    string    `json:"guild_id"`
-	Mode       string    `json:"mode"`        // "delayed" or "recurring"
-	DelayUntil time.Time `json:"delay_until"` // relevant only for "delayed"
-	OlderThan  string    `json:"older_than"`  // relevant only for "recurring"
-	StartedAt  time.Time `json:"started_at"`
-	Silent     bool      `json:"silent"`
+	ChannelID  string      `json:"channel_id"`            // empty for category rules
+	CategoryID string      `json:"category_id,omitempty"` // covers every text channel in the category
+	GuildID    string      `json:"guild_id"`
+	Name       string      `json:"name,omitempty"`
+	Mode       string      `json:"mode"`        // "delayed" or "recurring"
+	DelayUntil time.Time   `json:"delay_until"` // relevant only for "delayed"
+	OlderThan  string      `json:"older_than"`  // relevant only for "recurring"
+	StartedAt  time.Time   `json:"started_at"`
+	Silent     bool        `json:"silent"`
+	Filter     PurgeFilter `json:"filter,omitzero"`
+	Archive    string      `json:"archive,omitempty"` // transcript format: "html", "jsonl" or "" for none
+}
+
+// PurgeFilter narrows a purge to matching messages; the zero value matches every message. Set criteria
+// must all match, except Attachments and Links: with both set, a message with either matches.
+type PurgeFilter struct {
+	UserID      string `json:"user_id,omitempty"`     // only messages by this user
+	RoleID      string `json:"role_id,omitempty"`     // only messages by members with this role
+	BotsOnly    bool   `json:"bots_only,omitempty"`   // only messages by bots and webhooks
+	Attachments bool   `json:"attachments,omitempty"` // only messages with attachments
+	Links       bool   `json:"links,omitempty"`       // only messages with links
+	Pattern     string `json:"pattern,omitempty"`     // regexp the content must match
+	SkipPinned  bool   `json:"skip_pinned,omitempty"` // leave pinned messages alone
 }
 
 type ShortLink struct {
@@ -41,22 +59,35 @@ type Task struct {
 }
 
 type Record struct {
-	AnnounceChannel      string               `json:"announce_channel"`
-	ConfessChannel       string               `json:"confess_channel"`
-	CommandsDisabled     []string             `json:"commands_disabled"`
-	CommandsHistory      []CommandHistory     `json:"commands_history"`
-	CommandHashes        map[string]string    `json:"command_hashes,omitempty"` // slash command name -> hash for sync
-	DisciplineRoles      map[string]string    `json:"discipline_roles"`
-	MediaCategories      []string             `json:"media_categories"`
-	MediaDefault         string               `json:"media_default"`
-	PurgeJobs            map[string]PurgeJob  `json:"purge_jobs"` // key = channelID
-	ShortLinks           []ShortLink          `json:"short_links"`
-	TaskCooldowns        map[string]time.Time `json:"task_cooldowns"`
-	TaskList             map[string]Task      `json:"task_list"`
-	TaskRole             string               `json:"task_role"`
-	TranslateChannels    []string             `json:"translate_channels"`
-	MusicPlaybackHistory []MusicPlayback      `json:"music_playback_history,omitempty"`
-	NextMusicHistoryID   uint64               `json:"next_music_history_id"`
+	AnnounceChannel       string                       `json:"announce_channel"`
+	ConfessChannel        string                       `json:"confess_channel"`
+	CommandsDisabled      []string                     `json:"commands_disabled"`
+	CommandsHistory       []CommandHistory             `json:"commands_history"`
+	CommandHashes         map[string]string            `json:"command_hashes,omitempty"` // slash command name -> hash for sync
+	DisciplineRoles       map[string]string            `json:"discipline_roles"`
+	MediaCategories       []string                     `json:"media_categories"`
+	MediaDefault          string                       `json:"media_default"`
+	PurgeJobs             map[string]PurgeJob          `json:"purge_jobs"` // key = PurgeJobKey
+	PurgeLogChannel       string                       `json:"purge_log_channel,omitempty"`
+	ShortLinks            []ShortLink                  `json:"short_links"`
+	TaskCooldowns         map[string]time.Time         `json:"task_cooldowns"`
+	TaskList              map[string]Task              `json:"task_list"`
+	TaskRole              string                       `json:"task_role"`
+	TranslateChannels     []string                     `json:"translate_channels"`
+	MusicPlaybackHistory  []MusicPlayback              `json:"music_playback_history,omitempty"`
+	NextMusicHistoryID    uint64                       `json:"next_music_history_id"`
+	MusicLoopMode         string                       `json:"music_loop_mode,omitempty"` // "off", "track", "queue" or "autoplay"
+	MusicVolume           *int                         `json:"music_volume,omitempty"`    // percent; nil = DefaultMusicVolume
+	MusicNormalize        bool                         `json:"music_normalize,omitempty"`
+	MusicSession          *MusicSession                `json:"music_session,omitempty"`           // last playback snapshot, restored after a restart
+	MusicPlaylists        map[string]MusicPlaylist     `json:"music_playlists,omitempty"`         // key = lowercased playlist name
+	MusicDJRole           string                       `json:"music_dj_role,omitempty"`           // role allowed to skip/stop without a vote; "" = everyone
+	MusicRadioStations    map[string]MusicRadioStation `json:"music_radio_stations,omitempty"`    // key = lowercased station name
+	MusicSkipVotePercent  *int                         `json:"music_skip_vote_percent,omitempty"` // nil = DefaultMusicSkipVotePercent
+	MusicCrossfadeSeconds int                          `json:"music_crossfade_seconds,omitempty"` // 0 = off
+	MusicGapless          bool                         `json:"music_gapless,omitempty"`
+	MusicFilter           string                       `json:"music_filter,omitempty"`     // audio filter preset; "" = off
+	SoundboardClips       map[string]SoundboardClip    `json:"soundboard_clips,omitempty"` // key = lowercased clip name
 }
 
 type MusicPlayback struct {
@@ -67,4 +98,71 @@ type MusicPlayback struct {
 	CurrentParser    string    `json:"current_parser"`
 	AvailableParsers []string  `json:"available_parsers"`
 	SourceName       string    `json:"source_name"`
+	RequestedBy      string    `json:"requested_by,omitempty"` // user id; empty for autoplay and older rows
+	ChannelID        string    `json:"channel_id,omitempty"`   // voice channel
+	ListenedMs       int64     `json:"listened_ms,omitempty"`
+	EndReason        string    `json:"end_reason,omitempty"` // MusicEndFinished, MusicEndSkipped or MusicEndError
+}
+
+// End reasons stored in MusicPlayback.EndReason (same values as the player's EndReason).
+const (
+	MusicEndFinished = "finished"
+	MusicEndSkipped  = "skipped"
+	MusicEndError    = "error"
+)
+
+// Listened is how long the track actually played.
+func (m MusicPlayback) Listened() time.Duration {
+	return time.Duration(m.ListenedMs) * time.Millisecond
+}
+
+// MusicPlaylist is a named, ordered list of tracks saved by a guild. Tracks use the MusicPlayback shape
+// (ID and PlayedAt unset) and are kept independently of the trimmed playback history.
+type MusicPlaylist struct {
+	Name      string          `json:"name"`
+	CreatedBy string          `json:"created_by,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Tracks    []MusicPlayback `json:"tracks"`
+}
+
+// MusicRadioStation is a named stream URL saved by a guild's admins for /radio play.
+type MusicRadioStation struct {
+	Name    string    `json:"name"`
+	URL     string    `json:"url"`
+	AddedBy string    `json:"added_by,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// SoundboardClip is a short sound uploaded by a guild's admins for /sfx. File is the clip's file name
+// inside the guild's soundboard directory.
+type SoundboardClip struct {
+	Name    string    `json:"name"`
+	File    string    `json:"file"`
+	AddedBy string    `json:"added_by,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+// MusicSession is a snapshot of a guild's player: the current track with its position and the pending queue.
+// Tracks use the MusicPlayback shape (ID and PlayedAt unset) so they re-resolve like history entries.
+type MusicSession struct {
+	SavedAt         time.Time       `json:"saved_at"`
+	VoiceChannelID  string          `json:"voice_channel_id"`
+	StatusChannelID string          `json:"status_channel_id,omitempty"` // channel of the now-playing panel
+	Current         *MusicPlayback  `json:"current,omitempty"`
+	PositionMs      int64           `json:"position_ms,omitempty"`
+	Queue           []MusicPlayback `json:"queue,omitempty"`
+}
+
+// Position is the saved playback position of the current track.
+func (m MusicSession) Position() time.Duration {
+	return time.Duration(m.PositionMs) * time.Millisecond
+}
+
+// TrackCount is the number of tracks the session would restore.
+func (m MusicSession) TrackCount() int {
+	n := len(m.Queue)
+	if m.Current != nil {
+		n++
+	}
+	return n
 }
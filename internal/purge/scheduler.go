@@ -5,8 +5,6 @@ import (
 	"encoding/json"
 	"log"
 
-	"time"
-
 	"github.com/keshon/server-domme/internal/command/purge"
 	st "github.com/keshon/server-domme/internal/domain"
 	"github.com/keshon/server-domme/internal/storage"
@@ -15,7 +13,7 @@ import (
 )
 
 // RunScheduler starts scheduled purge jobs (delayed and recurring). Call from the Discord lifecycle.
-func RunScheduler(ctx context.Context, store *storage.Storage, session *discordgo.Session) {
+func RunScheduler(ctx context.Context, store *storage.Storage, session *discordgo.Session, archivesDir string) {
 	log.Printf("[INFO] Starting purge scheduler...")
 	records := store.Records()
 
@@ -29,79 +27,22 @@ func RunScheduler(ctx context.Context, store *storage.Storage, session *discordg
 		}
 
 		for _, job := range record.PurgeJobs {
-			log.Printf("[INFO] Found purge job — Mode: %s | Guild: %s | Channel: %s", job.Mode, job.GuildID, job.ChannelID)
+			log.Printf("[INFO] Found purge job — Mode: %s | Guild: %s | Rule: %s", job.Mode, job.GuildID, job.Key())
 
 			switch job.Mode {
 			case "delayed":
-				dur := time.Until(job.DelayUntil)
-
-				if dur <= 0 {
-					log.Printf("[INFO] DelayUntil is in the past — executing delayed purge immediately for channel %s", job.ChannelID)
-					purge.DeleteMessages(session, job.ChannelID, nil, nil, nil)
-
-					err := store.ClearDeletionJob(job.GuildID, job.ChannelID)
-					if err != nil {
-						log.Printf("[ERR] Failed to delete purge job for channel %s: %v", job.ChannelID, err)
-					}
-				} else {
-					log.Printf("[INFO] Scheduling delayed purge in %v for channel %s", dur, job.ChannelID)
-					go func(job st.PurgeJob) {
-						timer := time.NewTimer(dur)
-						defer timer.Stop()
-						select {
-						case <-ctx.Done():
-							return
-						case <-timer.C:
-						}
-						log.Printf("[INFO] Executing delayed purge for channel %s", job.ChannelID)
-						purge.DeleteMessages(session, job.ChannelID, nil, nil, nil)
-
-						err := store.ClearDeletionJob(job.GuildID, job.ChannelID)
-						if err != nil {
-							log.Printf("[ERR] Failed to delete purge job for channel %s: %v", job.ChannelID, err)
-						} else {
-							log.Printf("[INFO] Delayed purge complete and removed for channel %s", job.ChannelID)
-						}
-					}(job)
-				}
+				log.Printf("[INFO] Scheduling delayed purge %s at %v", job.Key(), job.DelayUntil)
+				purge.StartDelayed(ctx, session, store, archivesDir, job)
 
 			case "recurring":
-				dur, err := time.ParseDuration(job.OlderThan)
-				if err != nil {
-					log.Printf("[ERR] Failed to parse OlderThan duration '%s' for channel %s: %v", job.OlderThan, job.ChannelID, err)
+				if err := purge.StartRecurring(ctx, session, store, archivesDir, job); err != nil {
+					log.Printf("[ERR] Failed to start recurring purge %s: %v", job.Key(), err)
 					continue
 				}
-
-				stopChan := make(chan struct{})
-				purge.ActiveDeletionsMu.Lock()
-				purge.ActiveDeletions[job.ChannelID] = stopChan
-				purge.ActiveDeletionsMu.Unlock()
-
-				log.Printf("[INFO] Starting recurring purge for channel %s every 30s (older than %v)", job.ChannelID, dur)
-
-				go func(job st.PurgeJob, d time.Duration) {
-					ticker := time.NewTicker(30 * time.Second)
-					defer ticker.Stop()
-
-					for {
-						select {
-						case <-stopChan:
-							log.Printf("[INFO] Stopping recurring purge for channel %s", job.ChannelID)
-							return
-						case <-ctx.Done():
-							log.Printf("[INFO] Stopping recurring purge for channel %s (shutdown)", job.ChannelID)
-							return
-						case <-ticker.C:
-							start := time.Now().Add(-d)
-							now := time.Now()
-							log.Printf("[INFO] Recurring purge triggered for channel %s", job.ChannelID)
-							purge.DeleteMessages(session, job.ChannelID, &start, &now, stopChan)
-						}
-					}
-				}(job, dur)
+				log.Printf("[INFO] Started recurring purge %s (older than %s)", job.Key(), job.OlderThan)
 
 			default:
-				log.Printf("[ERR] Unknown purge mode '%s' for channel %s", job.Mode, job.ChannelID)
+				log.Printf("[ERR] Unknown purge mode '%s' for rule %s", job.Mode, job.Key())
 			}
 		}
 	}
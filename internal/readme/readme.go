@@ -155,16 +155,25 @@ func renderDiscordCommand(buf *bytes.Buffer, c commandkit.Command) {
 	}
 
 	for _, opt := range def.Options {
-		if opt.Type != discordgo.ApplicationCommandOptionSubCommand {
-			continue
+		switch opt.Type {
+		case discordgo.ApplicationCommandOptionSubCommand:
+			buf.WriteString(fmt.Sprintf(
+				"  - **/%s %s** — %s\n",
+				def.Name,
+				opt.Name,
+				opt.Description,
+			))
+		case discordgo.ApplicationCommandOptionSubCommandGroup:
+			for _, sub := range opt.Options {
+				buf.WriteString(fmt.Sprintf(
+					"  - **/%s %s %s** — %s\n",
+					def.Name,
+					opt.Name,
+					sub.Name,
+					sub.Description,
+				))
+			}
 		}
-
-		buf.WriteString(fmt.Sprintf(
-			"  - **/%s %s** — %s\n",
-			def.Name,
-			opt.Name,
-			opt.Description,
-		))
 	}
 }
 
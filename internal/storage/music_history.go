@@ -26,9 +26,17 @@ func musicPlaybackFromTrackParse(id uint64, at time.Time, tp parsers.TrackParse)
 		CurrentParser:    tp.CurrentParser,
 		AvailableParsers: slices.Clone(tp.SourceInfo.AvailableParsers),
 		SourceName:       tp.SourceInfo.SourceName,
+		RequestedBy:      tp.RequestedBy,
 	}
 }
 
+// MusicPlaybackEnd is how a play ended, recorded alongside the track in history.
+type MusicPlaybackEnd struct {
+	ChannelID string
+	Listened  time.Duration
+	Reason    string // domain.MusicEndFinished, MusicEndSkipped or MusicEndError
+}
+
 // TrackInfoFromMusicPlayback rebuilds resolver metadata for enqueue. Current parser is first in AvailableParsers when possible.
 func TrackInfoFromMusicPlayback(m domain.MusicPlayback) sources.TrackInfo {
 	parsersList := slices.Clone(m.AvailableParsers)
@@ -48,7 +56,8 @@ func TrackInfoFromMusicPlayback(m domain.MusicPlayback) sources.TrackInfo {
 }
 
 // AppendMusicPlayback assigns a monotonic id, appends, trims oldest rows, and persists.
-func (s *Storage) AppendMusicPlayback(guildID string, track parsers.TrackParse, at time.Time) (uint64, error) {
+// at is when the play started; the requester comes from track.RequestedBy.
+func (s *Storage) AppendMusicPlayback(guildID string, track parsers.TrackParse, at time.Time, end MusicPlaybackEnd) (uint64, error) {
 	record, err := s.getOrCreateGuildRecord(guildID)
 	if err != nil {
 		return 0, err
@@ -57,6 +66,9 @@ func (s *Storage) AppendMusicPlayback(guildID string, track parsers.TrackParse,
 	record.NextMusicHistoryID++
 	id := record.NextMusicHistoryID
 	row := musicPlaybackFromTrackParse(id, at, track)
+	row.ChannelID = end.ChannelID
+	row.ListenedMs = end.Listened.Milliseconds()
+	row.EndReason = end.Reason
 	record.MusicPlaybackHistory = append(record.MusicPlaybackHistory, row)
 
 	if len(record.MusicPlaybackHistory) > musicPlaybackHistoryLimit {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/keshon/melodix/pkg/music/parsers"
 	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/server-domme/internal/domain"
 	"github.com/rs/zerolog"
 )
 
@@ -32,6 +33,7 @@ func TestAppendGetListMusicPlayback(t *testing.T) {
 		URL:           "https://example.com/a",
 		Title:         "Song A",
 		CurrentParser: "p1",
+		RequestedBy:   "u1",
 		SourceInfo: sources.TrackInfo{
 			URL:              "https://example.com/a",
 			Title:            "Song A",
@@ -41,7 +43,7 @@ func TestAppendGetListMusicPlayback(t *testing.T) {
 	}
 	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
 
-	id, err := s.AppendMusicPlayback(guild, tp, at)
+	id, err := s.AppendMusicPlayback(guild, tp, at, MusicPlaybackEnd{ChannelID: "vc1", Listened: 95 * time.Second, Reason: domain.MusicEndSkipped})
 	if err != nil || id != 1 {
 		t.Fatalf("append: id=%d err=%v", id, err)
 	}
@@ -53,6 +55,9 @@ func TestAppendGetListMusicPlayback(t *testing.T) {
 	if got.ID != 1 || got.URL != tp.URL || got.Title != tp.Title || got.CurrentParser != tp.CurrentParser {
 		t.Fatalf("get: %+v", got)
 	}
+	if got.RequestedBy != "u1" || got.ChannelID != "vc1" || got.Listened() != 95*time.Second || got.EndReason != domain.MusicEndSkipped {
+		t.Fatalf("play details: %+v", got)
+	}
 	if len(got.AvailableParsers) != 2 {
 		t.Fatalf("available parsers: %v", got.AvailableParsers)
 	}
@@ -91,7 +96,7 @@ func TestMusicPlaybackTrimKeepsRecent(t *testing.T) {
 		},
 	}
 	for i := 0; i < 4; i++ {
-		_, err := s.AppendMusicPlayback(guild, base, time.Unix(int64(i), 0))
+		_, err := s.AppendMusicPlayback(guild, base, time.Unix(int64(i), 0), MusicPlaybackEnd{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -0,0 +1,311 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+// Caps for saved playlists per guild; they live in the guild record, so keep them bounded.
+var (
+	musicPlaylistLimit      = 50
+	musicPlaylistTrackLimit = 200
+)
+
+// MusicPlaylistNameMaxRunes bounds playlist names (they are shown in embeds and used as slash option values).
+const MusicPlaylistNameMaxRunes = 50
+
+var (
+	ErrMusicPlaylistNotFound    = errors.New("playlist not found")
+	ErrMusicPlaylistExists      = errors.New("a playlist with that name already exists")
+	ErrMusicPlaylistName        = fmt.Errorf("playlist name must be 1-%d characters", MusicPlaylistNameMaxRunes)
+	ErrMusicPlaylistLimit       = errors.New("too many playlists in this server")
+	ErrMusicPlaylistFull        = errors.New("playlist is full")
+	ErrMusicPlaylistTrackRange  = errors.New("playlist position out of range")
+	ErrMusicPlaylistImportShape = errors.New("not a playlist export")
+	ErrMusicPlaylistImportTrack = errors.New("playlist has a track that cannot be imported")
+)
+
+func musicPlaylistKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func validMusicPlaylistName(name string) bool {
+	n := utf8.RuneCountInString(strings.TrimSpace(name))
+	return n > 0 && n <= MusicPlaylistNameMaxRunes
+}
+
+// PlaylistTrackFromTrackParse converts a queued or playing track into a playlist entry.
+func PlaylistTrackFromTrackParse(tp parsers.TrackParse) domain.MusicPlayback {
+	tp.RequestedBy = ""
+	return musicPlaybackFromTrackParse(0, time.Time{}, tp)
+}
+
+// PlaylistTrackFromTrackInfo converts a resolver result into a playlist entry.
+func PlaylistTrackFromTrackInfo(ti sources.TrackInfo) domain.MusicPlayback {
+	m := domain.MusicPlayback{
+		URL:              ti.URL,
+		Title:            ti.Title,
+		AvailableParsers: slices.Clone(ti.AvailableParsers),
+		SourceName:       ti.SourceName,
+	}
+	if len(ti.AvailableParsers) > 0 {
+		m.CurrentParser = ti.AvailableParsers[0]
+	}
+	return m
+}
+
+// PlaylistTrackFromHistory copies a history row into a playlist entry, keeping only the track itself.
+func PlaylistTrackFromHistory(row domain.MusicPlayback) domain.MusicPlayback {
+	return domain.MusicPlayback{
+		URL:              row.URL,
+		Title:            row.Title,
+		CurrentParser:    row.CurrentParser,
+		AvailableParsers: slices.Clone(row.AvailableParsers),
+		SourceName:       row.SourceName,
+	}
+}
+
+// CreateMusicPlaylist adds an empty playlist. Names are case-insensitive.
+func (s *Storage) CreateMusicPlaylist(guildID, name, createdBy string, at time.Time) error {
+	if !validMusicPlaylistName(name) {
+		return ErrMusicPlaylistName
+	}
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	key := musicPlaylistKey(name)
+	if _, ok := record.MusicPlaylists[key]; ok {
+		return ErrMusicPlaylistExists
+	}
+	if len(record.MusicPlaylists) >= musicPlaylistLimit {
+		return ErrMusicPlaylistLimit
+	}
+	if record.MusicPlaylists == nil {
+		record.MusicPlaylists = make(map[string]domain.MusicPlaylist)
+	}
+	record.MusicPlaylists[key] = domain.MusicPlaylist{
+		Name:      strings.TrimSpace(name),
+		CreatedBy: createdBy,
+		CreatedAt: at,
+	}
+	return s.ds.Set(guildID, record)
+}
+
+// AddMusicPlaylistTracks appends tracks to a playlist and returns its new length. Nothing is added when the
+// tracks would not all fit.
+func (s *Storage) AddMusicPlaylistTracks(guildID, name string, tracks []domain.MusicPlayback) (int, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return 0, err
+	}
+
+	key := musicPlaylistKey(name)
+	pl, ok := record.MusicPlaylists[key]
+	if !ok {
+		return 0, ErrMusicPlaylistNotFound
+	}
+	if len(pl.Tracks)+len(tracks) > musicPlaylistTrackLimit {
+		return len(pl.Tracks), ErrMusicPlaylistFull
+	}
+	pl.Tracks = append(pl.Tracks, tracks...)
+	record.MusicPlaylists[key] = pl
+	return len(pl.Tracks), s.ds.Set(guildID, record)
+}
+
+// RemoveMusicPlaylistTrack removes the track at 1-based pos and returns it.
+func (s *Storage) RemoveMusicPlaylistTrack(guildID, name string, pos int) (domain.MusicPlayback, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.MusicPlayback{}, err
+	}
+
+	key := musicPlaylistKey(name)
+	pl, ok := record.MusicPlaylists[key]
+	if !ok {
+		return domain.MusicPlayback{}, ErrMusicPlaylistNotFound
+	}
+	if pos < 1 || pos > len(pl.Tracks) {
+		return domain.MusicPlayback{}, ErrMusicPlaylistTrackRange
+	}
+	removed := pl.Tracks[pos-1]
+	pl.Tracks = slices.Delete(pl.Tracks, pos-1, pos)
+	record.MusicPlaylists[key] = pl
+	return removed, s.ds.Set(guildID, record)
+}
+
+// DeleteMusicPlaylist removes a playlist.
+func (s *Storage) DeleteMusicPlaylist(guildID, name string) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	key := musicPlaylistKey(name)
+	if _, ok := record.MusicPlaylists[key]; !ok {
+		return ErrMusicPlaylistNotFound
+	}
+	delete(record.MusicPlaylists, key)
+	return s.ds.Set(guildID, record)
+}
+
+// MusicPlaylist returns one playlist by (case-insensitive) name.
+func (s *Storage) MusicPlaylist(guildID, name string) (domain.MusicPlaylist, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.MusicPlaylist{}, err
+	}
+	pl, ok := record.MusicPlaylists[musicPlaylistKey(name)]
+	if !ok {
+		return domain.MusicPlaylist{}, ErrMusicPlaylistNotFound
+	}
+	return pl, nil
+}
+
+// ListMusicPlaylists returns the guild's playlists sorted by name.
+func (s *Storage) ListMusicPlaylists(guildID string) ([]domain.MusicPlaylist, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.MusicPlaylist, 0, len(record.MusicPlaylists))
+	for _, pl := range record.MusicPlaylists {
+		out = append(out, pl)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return musicPlaylistKey(out[i].Name) < musicPlaylistKey(out[j].Name)
+	})
+	return out, nil
+}
+
+// ImportMusicPlaylist stores pl under its name. An existing playlist of that name is replaced only when
+// replace is true.
+func (s *Storage) ImportMusicPlaylist(guildID string, pl domain.MusicPlaylist, replace bool) error {
+	if !validMusicPlaylistName(pl.Name) {
+		return ErrMusicPlaylistName
+	}
+	if len(pl.Tracks) > musicPlaylistTrackLimit {
+		return ErrMusicPlaylistFull
+	}
+	for _, t := range pl.Tracks {
+		if _, ok := importableTrack(t); !ok {
+			return ErrMusicPlaylistImportTrack
+		}
+	}
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	key := musicPlaylistKey(pl.Name)
+	_, exists := record.MusicPlaylists[key]
+	if exists && !replace {
+		return ErrMusicPlaylistExists
+	}
+	if !exists && len(record.MusicPlaylists) >= musicPlaylistLimit {
+		return ErrMusicPlaylistLimit
+	}
+	if record.MusicPlaylists == nil {
+		record.MusicPlaylists = make(map[string]domain.MusicPlaylist)
+	}
+	pl.Name = strings.TrimSpace(pl.Name)
+	record.MusicPlaylists[key] = pl
+	return s.ds.Set(guildID, record)
+}
+
+// musicPlaylistExport is the JSON file format of /playlist export and import.
+type musicPlaylistExport struct {
+	Format string                     `json:"format"`
+	Name   string                     `json:"name"`
+	Tracks []musicPlaylistExportTrack `json:"tracks"`
+}
+
+type musicPlaylistExportTrack struct {
+	URL              string   `json:"url"`
+	Title            string   `json:"title,omitempty"`
+	SourceName       string   `json:"source_name,omitempty"`
+	CurrentParser    string   `json:"current_parser,omitempty"`
+	AvailableParsers []string `json:"available_parsers,omitempty"`
+}
+
+const musicPlaylistExportFormat = "server-domme/playlist/v1"
+
+// MarshalMusicPlaylist encodes a playlist for export.
+func MarshalMusicPlaylist(pl domain.MusicPlaylist) ([]byte, error) {
+	out := musicPlaylistExport{Format: musicPlaylistExportFormat, Name: pl.Name}
+	for _, t := range pl.Tracks {
+		out.Tracks = append(out.Tracks, musicPlaylistExportTrack{
+			URL:              t.URL,
+			Title:            t.Title,
+			SourceName:       t.SourceName,
+			CurrentParser:    t.CurrentParser,
+			AvailableParsers: t.AvailableParsers,
+		})
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// UnmarshalMusicPlaylist decodes an export. Tracks that importableTrack refuses are dropped.
+func UnmarshalMusicPlaylist(data []byte) (domain.MusicPlaylist, error) {
+	var in musicPlaylistExport
+	if err := json.Unmarshal(data, &in); err != nil {
+		return domain.MusicPlaylist{}, fmt.Errorf("%w: %v", ErrMusicPlaylistImportShape, err)
+	}
+	if in.Format != musicPlaylistExportFormat {
+		return domain.MusicPlaylist{}, ErrMusicPlaylistImportShape
+	}
+	pl := domain.MusicPlaylist{Name: strings.TrimSpace(in.Name)}
+	for _, t := range in.Tracks {
+		track, ok := importableTrack(domain.MusicPlayback{
+			URL:              strings.TrimSpace(t.URL),
+			Title:            t.Title,
+			SourceName:       t.SourceName,
+			CurrentParser:    t.CurrentParser,
+			AvailableParsers: t.AvailableParsers,
+		})
+		if ok {
+			pl.Tracks = append(pl.Tracks, track)
+		}
+	}
+	return pl, nil
+}
+
+// importableTrack keeps only what an imported file may ask the player to open: an http(s) URL, so
+// files on the host stay out of reach, and parsers that are registered. Unknown parsers are removed;
+// ok is false when the URL is refused or no parser is left.
+func importableTrack(t domain.MusicPlayback) (domain.MusicPlayback, bool) {
+	u, err := url.Parse(t.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return t, false
+	}
+	var available []string
+	for _, name := range t.AvailableParsers {
+		if _, ok := stream.Registry[name]; ok && !slices.Contains(available, name) {
+			available = append(available, name)
+		}
+	}
+	if _, ok := stream.Registry[t.CurrentParser]; !ok {
+		t.CurrentParser = ""
+	}
+	if t.CurrentParser == "" && len(available) > 0 {
+		t.CurrentParser = available[0]
+	}
+	if t.CurrentParser == "" {
+		return t, false
+	}
+	t.AvailableParsers = available
+	return t, true
+}
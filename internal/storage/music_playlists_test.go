@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/server-domme/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+func TestMusicPlaylistLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Intentionally omit s.Close(): datastore Close can block on autosave wait in tests.
+
+	const guild = "g1"
+	if err := s.CreateMusicPlaylist(guild, "Road Trip", "u1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateMusicPlaylist(guild, "road trip", "u2", time.Now()); !errors.Is(err, ErrMusicPlaylistExists) {
+		t.Fatalf("duplicate create err = %v", err)
+	}
+	if err := s.CreateMusicPlaylist(guild, "  ", "u1", time.Now()); !errors.Is(err, ErrMusicPlaylistName) {
+		t.Fatalf("blank name err = %v", err)
+	}
+
+	tracks := []domain.MusicPlayback{
+		{URL: "https://example.com/a", Title: "A", CurrentParser: "p1", AvailableParsers: []string{"p1"}},
+		{URL: "https://example.com/b", Title: "B", CurrentParser: "p1", AvailableParsers: []string{"p1"}},
+	}
+	if n, err := s.AddMusicPlaylistTracks(guild, "ROAD TRIP", tracks); err != nil || n != 2 {
+		t.Fatalf("add = %d, %v", n, err)
+	}
+	removed, err := s.RemoveMusicPlaylistTrack(guild, "road trip", 1)
+	if err != nil || removed.Title != "A" {
+		t.Fatalf("remove = %+v, %v", removed, err)
+	}
+	if _, err := s.RemoveMusicPlaylistTrack(guild, "road trip", 5); !errors.Is(err, ErrMusicPlaylistTrackRange) {
+		t.Fatalf("remove out of range err = %v", err)
+	}
+
+	pl, err := s.MusicPlaylist(guild, "road trip")
+	if err != nil || pl.Name != "Road Trip" || len(pl.Tracks) != 1 || pl.Tracks[0].Title != "B" {
+		t.Fatalf("playlist = %+v, %v", pl, err)
+	}
+
+	if err := s.DeleteMusicPlaylist(guild, "Road Trip"); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := s.ListMusicPlaylists(guild); len(list) != 0 {
+		t.Fatalf("list after delete = %+v", list)
+	}
+}
+
+func TestMusicPlaylistSurvivesHistoryTrim(t *testing.T) {
+	oldLim := musicPlaybackHistoryLimit
+	musicPlaybackHistoryLimit = 1
+	t.Cleanup(func() { musicPlaybackHistoryLimit = oldLim })
+
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const guild = "g1"
+	if err := s.CreateMusicPlaylist(guild, "keep", "", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	row := domain.MusicPlayback{ID: 7, PlayedAt: time.Now(), URL: "https://example.com/a", CurrentParser: "p1"}
+	if _, err := s.AddMusicPlaylistTracks(guild, "keep", []domain.MusicPlayback{PlaylistTrackFromHistory(row)}); err != nil {
+		t.Fatal(err)
+	}
+	played := parsers.TrackParse{URL: "https://example.com/x", CurrentParser: "p1"}
+	for i := 0; i < 3; i++ {
+		if _, err := s.AppendMusicPlayback(guild, played, time.Now(), MusicPlaybackEnd{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pl, err := s.MusicPlaylist(guild, "keep")
+	if err != nil || len(pl.Tracks) != 1 || pl.Tracks[0].ID != 0 {
+		t.Fatalf("playlist after trim = %+v, %v", pl, err)
+	}
+}
+
+func TestMusicPlaylistExportRoundTrip(t *testing.T) {
+	t.Parallel()
+	pl := domain.MusicPlaylist{Name: "mix", Tracks: []domain.MusicPlayback{
+		{URL: "https://example.com/a", Title: "A", CurrentParser: "ytdlp-link", AvailableParsers: []string{"ytdlp-link", "kkdai-link"}, SourceName: "YouTube"},
+	}}
+	data, err := MarshalMusicPlaylist(pl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalMusicPlaylist(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "mix" || len(got.Tracks) != 1 || got.Tracks[0].URL != pl.Tracks[0].URL || got.Tracks[0].AvailableParsers[1] != "kkdai-link" {
+		t.Fatalf("round trip = %+v", got)
+	}
+
+	if _, err := UnmarshalMusicPlaylist([]byte(`{"name":"x","tracks":[]}`)); !errors.Is(err, ErrMusicPlaylistImportShape) {
+		t.Fatalf("missing format err = %v", err)
+	}
+	if _, err := UnmarshalMusicPlaylist([]byte(`not json`)); !errors.Is(err, ErrMusicPlaylistImportShape) {
+		t.Fatalf("bad json err = %v", err)
+	}
+}
+
+func TestMusicPlaylistImportRefusesUnsafeTracks(t *testing.T) {
+	t.Parallel()
+	data := []byte(`{"format":"server-domme/playlist/v1","name":"x","tracks":[
+		{"url":"file:///etc/passwd.ogg","current_parser":"ffmpeg-file","available_parsers":["ffmpeg-file"]},
+		{"url":"/var/data/song.ogg","current_parser":"ffmpeg-link"},
+		{"url":"https://example.com/a","current_parser":"evil","available_parsers":["evil"]},
+		{"url":"https://example.com/b","current_parser":"evil","available_parsers":["evil","ytdlp-link"]}
+	]}`)
+	pl, err := UnmarshalMusicPlaylist(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pl.Tracks) != 1 || pl.Tracks[0].URL != "https://example.com/b" || pl.Tracks[0].CurrentParser != "ytdlp-link" || len(pl.Tracks[0].AvailableParsers) != 1 {
+		t.Fatalf("imported tracks = %+v", pl.Tracks)
+	}
+
+	s, err := NewStorage(context.Background(), filepath.Join(t.TempDir(), "ds.json"), zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl.Tracks = append(pl.Tracks, domain.MusicPlayback{URL: "file:///etc/passwd.ogg", CurrentParser: "ffmpeg-file"})
+	if err := s.ImportMusicPlaylist("g", pl, false); !errors.Is(err, ErrMusicPlaylistImportTrack) {
+		t.Fatalf("import err = %v", err)
+	}
+}
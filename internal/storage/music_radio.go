@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+// musicRadioStationLimit caps saved stations per guild; autocomplete shows at most 25 at a time anyway.
+var musicRadioStationLimit = 100
+
+// MusicRadioStationNameMaxRunes bounds station names (they are autocomplete choice values).
+const MusicRadioStationNameMaxRunes = 50
+
+var (
+	ErrMusicRadioStationNotFound = errors.New("radio station not found")
+	ErrMusicRadioStationExists   = errors.New("a radio station with that name already exists")
+	ErrMusicRadioStationName     = fmt.Errorf("station name must be 1-%d characters", MusicRadioStationNameMaxRunes)
+	ErrMusicRadioStationLimit    = errors.New("too many radio stations in this server")
+)
+
+func musicRadioStationKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// AddMusicRadioStation saves a named stream URL. Names are case-insensitive.
+func (s *Storage) AddMusicRadioStation(guildID, name, url, addedBy string, at time.Time) error {
+	n := utf8.RuneCountInString(strings.TrimSpace(name))
+	if n == 0 || n > MusicRadioStationNameMaxRunes {
+		return ErrMusicRadioStationName
+	}
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	key := musicRadioStationKey(name)
+	if _, ok := record.MusicRadioStations[key]; ok {
+		return ErrMusicRadioStationExists
+	}
+	if len(record.MusicRadioStations) >= musicRadioStationLimit {
+		return ErrMusicRadioStationLimit
+	}
+	if record.MusicRadioStations == nil {
+		record.MusicRadioStations = make(map[string]domain.MusicRadioStation)
+	}
+	record.MusicRadioStations[key] = domain.MusicRadioStation{
+		Name:    strings.TrimSpace(name),
+		URL:     url,
+		AddedBy: addedBy,
+		AddedAt: at,
+	}
+	return s.ds.Set(guildID, record)
+}
+
+// RemoveMusicRadioStation deletes a station and returns it.
+func (s *Storage) RemoveMusicRadioStation(guildID, name string) (domain.MusicRadioStation, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.MusicRadioStation{}, err
+	}
+
+	key := musicRadioStationKey(name)
+	st, ok := record.MusicRadioStations[key]
+	if !ok {
+		return domain.MusicRadioStation{}, ErrMusicRadioStationNotFound
+	}
+	delete(record.MusicRadioStations, key)
+	return st, s.ds.Set(guildID, record)
+}
+
+// MusicRadioStation returns one station by (case-insensitive) name.
+func (s *Storage) MusicRadioStation(guildID, name string) (domain.MusicRadioStation, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.MusicRadioStation{}, err
+	}
+	st, ok := record.MusicRadioStations[musicRadioStationKey(name)]
+	if !ok {
+		return domain.MusicRadioStation{}, ErrMusicRadioStationNotFound
+	}
+	return st, nil
+}
+
+// ListMusicRadioStations returns the guild's stations sorted by name.
+func (s *Storage) ListMusicRadioStations(guildID string) ([]domain.MusicRadioStation, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.MusicRadioStation, 0, len(record.MusicRadioStations))
+	for _, st := range record.MusicRadioStations {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return musicRadioStationKey(out[i].Name) < musicRadioStationKey(out[j].Name)
+	})
+	return out, nil
+}
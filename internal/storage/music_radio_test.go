@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestMusicRadioStationLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Intentionally omit s.Close(): datastore Close can block on autosave wait in tests.
+
+	const guild = "g1"
+	if err := s.AddMusicRadioStation(guild, "Lofi Beats", "https://radio.test/lofi", "u1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddMusicRadioStation(guild, "Ambient", "https://radio.test/ambient", "u1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddMusicRadioStation(guild, "lofi beats", "https://radio.test/other", "u2", time.Now()); !errors.Is(err, ErrMusicRadioStationExists) {
+		t.Fatalf("duplicate add err = %v", err)
+	}
+	if err := s.AddMusicRadioStation(guild, " ", "https://radio.test/x", "u1", time.Now()); !errors.Is(err, ErrMusicRadioStationName) {
+		t.Fatalf("blank name err = %v", err)
+	}
+
+	st, err := s.MusicRadioStation(guild, "LOFI BEATS")
+	if err != nil || st.Name != "Lofi Beats" || st.URL != "https://radio.test/lofi" {
+		t.Fatalf("station = %+v, %v", st, err)
+	}
+	list, _ := s.ListMusicRadioStations(guild)
+	if len(list) != 2 || list[0].Name != "Ambient" {
+		t.Fatalf("list = %+v", list)
+	}
+
+	if _, err := s.RemoveMusicRadioStation(guild, "ambient"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.RemoveMusicRadioStation(guild, "ambient"); !errors.Is(err, ErrMusicRadioStationNotFound) {
+		t.Fatalf("second remove err = %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+// NewMusicSession builds a session snapshot from player state. current may be nil (nothing playing).
+func NewMusicSession(voiceChannelID, statusChannelID string, current *parsers.TrackParse, position time.Duration, queue []parsers.TrackParse, at time.Time) domain.MusicSession {
+	sess := domain.MusicSession{
+		SavedAt:         at,
+		VoiceChannelID:  voiceChannelID,
+		StatusChannelID: statusChannelID,
+	}
+	if current != nil {
+		row := musicPlaybackFromTrackParse(0, time.Time{}, *current)
+		sess.Current = &row
+		sess.PositionMs = position.Milliseconds()
+	}
+	for _, t := range queue {
+		sess.Queue = append(sess.Queue, musicPlaybackFromTrackParse(0, time.Time{}, t))
+	}
+	return sess
+}
+
+// SetMusicSession persists the guild's playback snapshot, replacing any previous one.
+func (s *Storage) SetMusicSession(guildID string, sess domain.MusicSession) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	record.MusicSession = &sess
+	return s.ds.Set(guildID, record)
+}
+
+// MusicSession returns the guild's playback snapshot, or nil when there is none.
+func (s *Storage) MusicSession(guildID string) (*domain.MusicSession, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return nil, err
+	}
+	return record.MusicSession, nil
+}
+
+// ClearMusicSession removes the guild's playback snapshot. It is a no-op when there is none.
+func (s *Storage) ClearMusicSession(guildID string) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+	if record.MusicSession == nil {
+		return nil
+	}
+
+	record.MusicSession = nil
+	return s.ds.Set(guildID, record)
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/rs/zerolog"
+)
+
+func TestMusicSessionRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Intentionally omit s.Close(): datastore Close can block on autosave wait in tests.
+
+	if sess, err := s.MusicSession("g"); err != nil || sess != nil {
+		t.Fatalf("default session = %+v, %v", sess, err)
+	}
+
+	current := parsers.TrackParse{
+		URL:           "https://example.com/a",
+		Title:         "A",
+		CurrentParser: "p2",
+		SourceInfo:    sources.TrackInfo{SourceName: "YouTube", AvailableParsers: []string{"p1", "p2"}},
+	}
+	queue := []parsers.TrackParse{{URL: "https://example.com/b", Title: "B", CurrentParser: "p1", SourceInfo: sources.TrackInfo{AvailableParsers: []string{"p1"}}}}
+	sess := NewMusicSession("vc", "text", &current, 95*time.Second, queue, time.Now())
+	if err := s.SetMusicSession("g", sess); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.MusicSession("g")
+	if err != nil || got == nil {
+		t.Fatalf("session = %+v, %v", got, err)
+	}
+	if got.VoiceChannelID != "vc" || got.Position() != 95*time.Second || got.TrackCount() != 2 {
+		t.Fatalf("session = %+v", got)
+	}
+	if ti := TrackInfoFromMusicPlayback(*got.Current); ti.URL != current.URL || ti.AvailableParsers[0] != "p2" {
+		t.Fatalf("current trackinfo = %+v", ti)
+	}
+
+	if err := s.ClearMusicSession("g"); err != nil {
+		t.Fatal(err)
+	}
+	if sess, _ := s.MusicSession("g"); sess != nil {
+		t.Fatalf("session after clear = %+v", sess)
+	}
+}
@@ -0,0 +1,173 @@
+package storage
+
+import "github.com/keshon/server-domme/internal/domain"
+
+// SetMusicLoopMode persists the guild's player loop mode ("off", "track", "queue" or "autoplay").
+func (s *Storage) SetMusicLoopMode(guildID, mode string) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	record.MusicLoopMode = mode
+	return s.ds.Set(guildID, record)
+}
+
+// MusicLoopMode returns the stored loop mode ("" when never set).
+func (s *Storage) MusicLoopMode(guildID string) (string, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return "", err
+	}
+	return record.MusicLoopMode, nil
+}
+
+// SetMusicVolume persists the guild's default playback volume in percent (clamped to 0..domain.MaxMusicVolume).
+func (s *Storage) SetMusicVolume(guildID string, pct int) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	pct = domain.ClampMusicVolume(pct)
+	record.MusicVolume = &pct
+	return s.ds.Set(guildID, record)
+}
+
+// MusicVolume returns the guild's playback volume in percent (domain.DefaultMusicVolume when never set).
+func (s *Storage) MusicVolume(guildID string) (int, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.DefaultMusicVolume, err
+	}
+	if record.MusicVolume == nil {
+		return domain.DefaultMusicVolume, nil
+	}
+	return *record.MusicVolume, nil
+}
+
+// SetMusicNormalize persists whether loudness normalisation is enabled for the guild.
+func (s *Storage) SetMusicNormalize(guildID string, on bool) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	record.MusicNormalize = on
+	return s.ds.Set(guildID, record)
+}
+
+// MusicNormalize reports whether loudness normalisation is enabled for the guild.
+func (s *Storage) MusicNormalize(guildID string) (bool, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return false, err
+	}
+	return record.MusicNormalize, nil
+}
+
+// SetMusicDJRole persists the guild's DJ role ("" clears it, letting everyone skip and stop).
+func (s *Storage) SetMusicDJRole(guildID, roleID string) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	record.MusicDJRole = roleID
+	return s.ds.Set(guildID, record)
+}
+
+// MusicDJRole returns the guild's DJ role ("" when not set).
+func (s *Storage) MusicDJRole(guildID string) (string, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return "", err
+	}
+	return record.MusicDJRole, nil
+}
+
+// SetMusicSkipVotePercent persists the share of listeners needed to pass a vote-skip
+// (clamped to domain.MinMusicSkipVotePercent..domain.MaxMusicSkipVotePercent).
+func (s *Storage) SetMusicSkipVotePercent(guildID string, pct int) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	pct = domain.ClampMusicSkipVotePercent(pct)
+	record.MusicSkipVotePercent = &pct
+	return s.ds.Set(guildID, record)
+}
+
+// MusicSkipVotePercent returns the guild's vote-skip threshold in percent (domain.DefaultMusicSkipVotePercent when never set).
+func (s *Storage) MusicSkipVotePercent(guildID string) (int, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.DefaultMusicSkipVotePercent, err
+	}
+	if record.MusicSkipVotePercent == nil {
+		return domain.DefaultMusicSkipVotePercent, nil
+	}
+	return *record.MusicSkipVotePercent, nil
+}
+
+// SetMusicCrossfade persists the guild's crossfade between tracks in seconds
+// (clamped to 0..domain.MaxMusicCrossfadeSeconds; 0 turns it off).
+func (s *Storage) SetMusicCrossfade(guildID string, sec int) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	record.MusicCrossfadeSeconds = domain.ClampMusicCrossfadeSeconds(sec)
+	return s.ds.Set(guildID, record)
+}
+
+// MusicCrossfade returns the guild's crossfade in seconds (0 when off).
+func (s *Storage) MusicCrossfade(guildID string) (int, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return 0, err
+	}
+	return record.MusicCrossfadeSeconds, nil
+}
+
+// SetMusicGapless persists whether tracks are joined without a gap.
+func (s *Storage) SetMusicGapless(guildID string, on bool) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	record.MusicGapless = on
+	return s.ds.Set(guildID, record)
+}
+
+// MusicGapless reports whether gapless playback is enabled for the guild.
+func (s *Storage) MusicGapless(guildID string) (bool, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return false, err
+	}
+	return record.MusicGapless, nil
+}
+
+// SetMusicFilter persists the guild's audio filter preset ("" or "off" disables filtering).
+func (s *Storage) SetMusicFilter(guildID, preset string) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	record.MusicFilter = preset
+	return s.ds.Set(guildID, record)
+}
+
+// MusicFilter returns the stored audio filter preset ("" when never set).
+func (s *Storage) MusicFilter(guildID string) (string, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return "", err
+	}
+	return record.MusicFilter, nil
+}
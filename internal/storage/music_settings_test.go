@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/keshon/server-domme/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+func TestMusicLoopModeRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Intentionally omit s.Close(): datastore Close can block on autosave wait in tests.
+
+	if mode, err := s.MusicLoopMode("g"); err != nil || mode != "" {
+		t.Fatalf("default mode = %q, %v", mode, err)
+	}
+	if err := s.SetMusicLoopMode("g", "queue"); err != nil {
+		t.Fatal(err)
+	}
+	if mode, err := s.MusicLoopMode("g"); err != nil || mode != "queue" {
+		t.Fatalf("mode = %q, %v; want queue", mode, err)
+	}
+}
+
+func TestMusicVolumeDefaultAndClamp(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v, err := s.MusicVolume("g"); err != nil || v != domain.DefaultMusicVolume {
+		t.Fatalf("default volume = %d, %v", v, err)
+	}
+	if err := s.SetMusicVolume("g", 0); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.MusicVolume("g"); v != 0 {
+		t.Fatalf("volume = %d, want 0 (explicit mute must not fall back to default)", v)
+	}
+	if err := s.SetMusicVolume("g", 500); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.MusicVolume("g"); v != domain.MaxMusicVolume {
+		t.Fatalf("volume = %d, want clamp to %d", v, domain.MaxMusicVolume)
+	}
+}
+
+func TestMusicDJRoleAndSkipVotePercent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if role, err := s.MusicDJRole("g"); err != nil || role != "" {
+		t.Fatalf("default DJ role = %q, %v", role, err)
+	}
+	if err := s.SetMusicDJRole("g", "r1"); err != nil {
+		t.Fatal(err)
+	}
+	if role, _ := s.MusicDJRole("g"); role != "r1" {
+		t.Fatalf("DJ role = %q, want r1", role)
+	}
+
+	if pct, err := s.MusicSkipVotePercent("g"); err != nil || pct != domain.DefaultMusicSkipVotePercent {
+		t.Fatalf("default vote percent = %d, %v", pct, err)
+	}
+	if err := s.SetMusicSkipVotePercent("g", 0); err != nil {
+		t.Fatal(err)
+	}
+	if pct, _ := s.MusicSkipVotePercent("g"); pct != domain.MinMusicSkipVotePercent {
+		t.Fatalf("vote percent = %d, want clamped to %d", pct, domain.MinMusicSkipVotePercent)
+	}
+}
+
+func TestMusicCrossfadeAndGapless(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sec, err := s.MusicCrossfade("g"); err != nil || sec != 0 {
+		t.Fatalf("default crossfade = %d, %v", sec, err)
+	}
+	if err := s.SetMusicCrossfade("g", 60); err != nil {
+		t.Fatal(err)
+	}
+	if sec, _ := s.MusicCrossfade("g"); sec != domain.MaxMusicCrossfadeSeconds {
+		t.Fatalf("crossfade = %d, want clamp to %d", sec, domain.MaxMusicCrossfadeSeconds)
+	}
+	if err := s.SetMusicGapless("g", true); err != nil {
+		t.Fatal(err)
+	}
+	if on, _ := s.MusicGapless("g"); !on {
+		t.Fatal("gapless not persisted")
+	}
+}
+
+func TestMusicFilterRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f, err := s.MusicFilter("g"); err != nil || f != "" {
+		t.Fatalf("default filter = %q, %v", f, err)
+	}
+	if err := s.SetMusicFilter("g", "nightcore"); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := s.MusicFilter("g"); f != "nightcore" {
+		t.Fatalf("filter = %q, want nightcore", f)
+	}
+}
@@ -73,6 +73,7 @@ func (s *Storage) getOrCreateGuildRecord(guildID string) (*domain.Record, error)
 	if len(record.CommandsHistory) > commandHistoryLimit {
 		record.CommandsHistory = record.CommandsHistory[len(record.CommandsHistory)-commandHistoryLimit:]
 	}
+	domain.NormalizePurgeJobs(record.PurgeJobs)
 
 	return &record, nil
 }
@@ -93,6 +94,7 @@ func (s *Storage) Records() map[string]domain.Record {
 		if !exists {
 			continue
 		}
+		domain.NormalizePurgeJobs(record.PurgeJobs)
 		mapStringRecord[key] = record
 	}
 	return mapStringRecord
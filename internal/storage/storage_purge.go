@@ -6,39 +6,36 @@ import (
 	st "github.com/keshon/server-domme/internal/domain"
 )
 
-func (s *Storage) SetDeletionJob(guildID, channelID, mode string, delayUntil time.Time, silent bool, olderThan ...string) error {
+// SetPurgeJob stores job under its key (see domain.PurgeJobKey), replacing a rule of the same name on
+// the same channel or category.
+func (s *Storage) SetPurgeJob(guildID string, job st.PurgeJob) error {
 	record, err := s.getOrCreateGuildRecord(guildID)
 	if err != nil {
 		return err
 	}
 
-	job := st.PurgeJob{
-		ChannelID:  channelID,
-		GuildID:    guildID,
-		Mode:       mode,
-		DelayUntil: delayUntil,
-		Silent:     silent,
-		StartedAt:  time.Now(),
+	job.GuildID = guildID
+	if job.StartedAt.IsZero() {
+		job.StartedAt = time.Now()
 	}
-
-	if len(olderThan) > 0 {
-		job.OlderThan = olderThan[0]
+	if record.PurgeJobs == nil {
+		record.PurgeJobs = make(map[string]st.PurgeJob)
 	}
-
-	record.PurgeJobs[channelID] = job
+	record.PurgeJobs[job.Key()] = job
 	return s.ds.Set(guildID, record)
 }
 
-func (s *Storage) ClearDeletionJob(guildID, channelID string) error {
+// ClearPurgeJob removes the rule with key.
+func (s *Storage) ClearPurgeJob(guildID, key string) error {
 	record, err := s.getOrCreateGuildRecord(guildID)
 	if err != nil {
 		return err
 	}
-	delete(record.PurgeJobs, channelID)
+	delete(record.PurgeJobs, key)
 	return s.ds.Set(guildID, record)
 }
 
-func (s *Storage) GetDeletionJobsList(guildID string) (map[string]st.PurgeJob, error) {
+func (s *Storage) GetPurgeJobsList(guildID string) (map[string]st.PurgeJob, error) {
 	record, err := s.getOrCreateGuildRecord(guildID)
 	if err != nil {
 		return nil, err
@@ -46,10 +43,31 @@ func (s *Storage) GetDeletionJobsList(guildID string) (map[string]st.PurgeJob, e
 	return record.PurgeJobs, nil
 }
 
-func (s *Storage) GetDeletionJob(guildID, channelID string) (st.PurgeJob, error) {
+// GetPurgeJob returns the rule with key; ok is false when there is none.
+func (s *Storage) GetPurgeJob(guildID, key string) (job st.PurgeJob, ok bool, err error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return st.PurgeJob{}, false, err
+	}
+	job, ok = record.PurgeJobs[key]
+	return job, ok, nil
+}
+
+// SetPurgeLogChannel sets where purge transcripts are posted; "" stops posting them.
+func (s *Storage) SetPurgeLogChannel(guildID, channelID string) error {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+	record.PurgeLogChannel = channelID
+	return s.ds.Set(guildID, record)
+}
+
+// GetPurgeLogChannel returns where purge transcripts are posted, or "".
+func (s *Storage) GetPurgeLogChannel(guildID string) (string, error) {
 	record, err := s.getOrCreateGuildRecord(guildID)
 	if err != nil {
-		return st.PurgeJob{}, err
+		return "", err
 	}
-	return record.PurgeJobs[channelID], nil
+	return record.PurgeLogChannel, nil
 }
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/keshon/server-domme/internal/domain"
+	"github.com/rs/zerolog"
+)
+
+func TestPurgeRulesPerChannel(t *testing.T) {
+	s, err := NewStorage(context.Background(), filepath.Join(t.TempDir(), "ds.json"), zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	daily := domain.PurgeJob{ChannelID: "c1", Name: "daily", Mode: "recurring", OlderThan: "1d"}
+	weekly := domain.PurgeJob{ChannelID: "c1", Name: "weekly", Mode: "recurring", OlderThan: "7d"}
+	for _, job := range []domain.PurgeJob{daily, weekly} {
+		if err := s.SetPurgeJob("g", job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	jobs, err := s.GetPurgeJobsList("g")
+	if err != nil || len(jobs) != 2 {
+		t.Fatalf("jobs = %v, err = %v", jobs, err)
+	}
+	got, ok, err := s.GetPurgeJob("g", weekly.Key())
+	if err != nil || !ok || got.OlderThan != "7d" || got.GuildID != "g" || got.StartedAt.IsZero() {
+		t.Fatalf("weekly = %+v, %v, %v", got, ok, err)
+	}
+
+	if err := s.ClearPurgeJob("g", daily.Key()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.GetPurgeJob("g", daily.Key()); ok {
+		t.Fatal("daily rule still stored")
+	}
+	if _, ok, _ := s.GetPurgeJob("g", weekly.Key()); !ok {
+		t.Fatal("clearing daily removed weekly")
+	}
+}
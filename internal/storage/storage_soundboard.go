@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/keshon/server-domme/internal/domain"
+)
+
+// soundboardClipLimit caps clips per guild; the button board shows at most 25 at a time anyway.
+var soundboardClipLimit = 50
+
+// SoundboardClipNameMaxRunes bounds clip names (they are autocomplete values and button labels).
+const SoundboardClipNameMaxRunes = 32
+
+var (
+	ErrSoundboardClipNotFound = errors.New("soundboard clip not found")
+	ErrSoundboardClipExists   = errors.New("a soundboard clip with that name already exists")
+	ErrSoundboardClipName     = fmt.Errorf("clip name must be 1-%d characters", SoundboardClipNameMaxRunes)
+	ErrSoundboardClipLimit    = errors.New("too many soundboard clips in this server")
+)
+
+func soundboardClipKey(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// AddSoundboardClip saves a named clip stored as file in the guild's soundboard directory. Names are
+// case-insensitive.
+func (s *Storage) AddSoundboardClip(guildID, name, file, addedBy string, at time.Time) error {
+	n := utf8.RuneCountInString(strings.TrimSpace(name))
+	if n == 0 || n > SoundboardClipNameMaxRunes {
+		return ErrSoundboardClipName
+	}
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return err
+	}
+
+	key := soundboardClipKey(name)
+	if _, ok := record.SoundboardClips[key]; ok {
+		return ErrSoundboardClipExists
+	}
+	if len(record.SoundboardClips) >= soundboardClipLimit {
+		return ErrSoundboardClipLimit
+	}
+	if record.SoundboardClips == nil {
+		record.SoundboardClips = make(map[string]domain.SoundboardClip)
+	}
+	record.SoundboardClips[key] = domain.SoundboardClip{
+		Name:    strings.TrimSpace(name),
+		File:    file,
+		AddedBy: addedBy,
+		AddedAt: at,
+	}
+	return s.ds.Set(guildID, record)
+}
+
+// RemoveSoundboardClip deletes a clip record and returns it; the caller removes the file.
+func (s *Storage) RemoveSoundboardClip(guildID, name string) (domain.SoundboardClip, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.SoundboardClip{}, err
+	}
+
+	key := soundboardClipKey(name)
+	clip, ok := record.SoundboardClips[key]
+	if !ok {
+		return domain.SoundboardClip{}, ErrSoundboardClipNotFound
+	}
+	delete(record.SoundboardClips, key)
+	return clip, s.ds.Set(guildID, record)
+}
+
+// SoundboardClip returns one clip by (case-insensitive) name.
+func (s *Storage) SoundboardClip(guildID, name string) (domain.SoundboardClip, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return domain.SoundboardClip{}, err
+	}
+	clip, ok := record.SoundboardClips[soundboardClipKey(name)]
+	if !ok {
+		return domain.SoundboardClip{}, ErrSoundboardClipNotFound
+	}
+	return clip, nil
+}
+
+// ListSoundboardClips returns the guild's clips sorted by name.
+func (s *Storage) ListSoundboardClips(guildID string) ([]domain.SoundboardClip, error) {
+	record, err := s.getOrCreateGuildRecord(guildID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]domain.SoundboardClip, 0, len(record.SoundboardClips))
+	for _, clip := range record.SoundboardClips {
+		out = append(out, clip)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return soundboardClipKey(out[i].Name) < soundboardClipKey(out[j].Name)
+	})
+	return out, nil
+}
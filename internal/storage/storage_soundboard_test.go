@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSoundboardClipLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ds.json")
+	s, err := NewStorage(context.Background(), path, zerolog.Nop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Intentionally omit s.Close(): datastore Close can block on autosave wait in tests.
+
+	const guild = "g1"
+	if err := s.AddSoundboardClip(guild, "Airhorn", "airhorn.mp3", "u1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddSoundboardClip(guild, "Applause", "applause.ogg", "u1", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddSoundboardClip(guild, "AIRHORN", "other.mp3", "u2", time.Now()); !errors.Is(err, ErrSoundboardClipExists) {
+		t.Fatalf("duplicate add err = %v", err)
+	}
+	if err := s.AddSoundboardClip(guild, "", "x.mp3", "u1", time.Now()); !errors.Is(err, ErrSoundboardClipName) {
+		t.Fatalf("blank name err = %v", err)
+	}
+
+	clip, err := s.SoundboardClip(guild, "airhorn")
+	if err != nil || clip.Name != "Airhorn" || clip.File != "airhorn.mp3" {
+		t.Fatalf("clip = %+v, %v", clip, err)
+	}
+	list, _ := s.ListSoundboardClips(guild)
+	if len(list) != 2 || list[0].Name != "Airhorn" || list[1].Name != "Applause" {
+		t.Fatalf("list = %+v", list)
+	}
+
+	removed, err := s.RemoveSoundboardClip(guild, "Applause")
+	if err != nil || removed.File != "applause.ogg" {
+		t.Fatalf("remove = %+v, %v", removed, err)
+	}
+	if _, err := s.SoundboardClip(guild, "applause"); !errors.Is(err, ErrSoundboardClipNotFound) {
+		t.Fatalf("lookup after remove err = %v", err)
+	}
+}
@@ -209,6 +209,16 @@ func (v *VoiceConnection) AddHandler(h VoiceSpeakingUpdateHandler) {
 	v.Cond.Broadcast()
 }
 
+// UserIDForSSRC returns the ID of the user sending audio with ssrc, as announced by the voice
+// server. ok is false until the user has spoken or connected since the bot joined.
+func (v *VoiceConnection) UserIDForSSRC(ssrc uint32) (userID string, ok bool) {
+	v.Cond.L.Lock()
+	defer v.Cond.L.Unlock()
+
+	userID, ok = v.ssrcToUserID[ssrc]
+	return
+}
+
 // VoiceSpeakingUpdate is a struct for a VoiceSpeakingUpdate event.
 type VoiceSpeakingUpdate struct {
 	UserID   string `json:"user_id"`
@@ -1185,6 +1195,12 @@ func (v *VoiceConnection) opusReceiver(ctx context.Context) {
 			p.Opus = decrypted
 		}
 
+		// The payload and extension may still point into recvbuf, which the next read overwrites.
+		p.Opus = append([]byte(nil), p.Opus...)
+		if p.Extension != nil {
+			p.Extension = append([]byte(nil), p.Extension...)
+		}
+
 		if ch != nil {
 			select {
 			case ch <- &p:
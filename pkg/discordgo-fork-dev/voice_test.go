@@ -1,6 +1,9 @@
 package discordgo
 
-import "testing"
+import (
+	"sync"
+	"testing"
+)
 
 func TestVoiceConnectionDeadChannelReturnsDead(t *testing.T) {
 	dead := make(chan struct{})
@@ -9,3 +12,14 @@ func TestVoiceConnectionDeadChannelReturnsDead(t *testing.T) {
 		t.Fatalf("DeadChannel() returned unexpected channel")
 	}
 }
+
+func TestVoiceConnectionUserIDForSSRC(t *testing.T) {
+	vc := &VoiceConnection{Cond: sync.NewCond(&sync.Mutex{})}
+	if _, ok := vc.UserIDForSSRC(42); ok {
+		t.Fatalf("UserIDForSSRC() found a user before any was announced")
+	}
+	vc.ssrcToUserID = map[uint32]string{42: "u1"}
+	if got, ok := vc.UserIDForSSRC(42); !ok || got != "u1" {
+		t.Fatalf("UserIDForSSRC() = %q, %v; want u1, true", got, ok)
+	}
+}
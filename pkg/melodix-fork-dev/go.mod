@@ -0,0 +1,29 @@
+module github.com/keshon/melodix
+
+go 1.26
+
+require (
+	github.com/ebitengine/oto/v3 v3.4.0
+	github.com/rs/zerolog v1.35.1
+)
+
+require (
+	github.com/ebitengine/purego v0.10.0 // indirect
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.22 // indirect
+	golang.org/x/sys v0.43.0 // indirect
+)
+
+require (
+	github.com/bitly/go-simplejson v0.5.1 // indirect
+	github.com/dlclark/regexp2 v1.12.0 // indirect
+	github.com/dop251/goja v0.0.0-20260311135729-065cd970411c // indirect
+	github.com/go-sourcemap/sourcemap v2.1.4+incompatible // indirect
+	github.com/google/pprof v0.0.0-20260402051712-545e8a4df936 // indirect
+	golang.org/x/text v0.36.0 // indirect
+)
+
+require (
+	github.com/kkdai/youtube/v2 v2.10.6
+	golang.org/x/net v0.53.0 // indirect
+)
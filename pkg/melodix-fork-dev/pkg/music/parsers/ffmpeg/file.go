@@ -0,0 +1,72 @@
+package ffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+)
+
+// FileStreamer decodes local files (file:// track URLs) straight from disk. It only opens audio files
+// inside track.LocalRoot, whatever path a queued or imported track names.
+type FileStreamer struct{}
+
+func (s *FileStreamer) LinkStream(track *parsers.TrackParse, seekSec float64) (io.ReadCloser, func(), error) {
+	path, ok := local.Path(track.URL)
+	if !ok {
+		return nil, nil, fmt.Errorf("[ffmpeg-file] not a local track: %s", track.URL)
+	}
+	if track.LocalRoot == "" || !local.Within(track.LocalRoot, path) {
+		return nil, nil, fmt.Errorf("[ffmpeg-file] file is outside the library: %s", track.URL)
+	}
+	if !local.IsAudioFile(path) {
+		return nil, nil, fmt.Errorf("[ffmpeg-file] unsupported audio file type: %s", track.URL)
+	}
+	if track.Duration <= 0 {
+		track.Duration = probeDuration(path)
+	}
+	return ffmpegFile(path, seekSec)
+}
+func (s *FileStreamer) PipeStream(track *parsers.TrackParse, seekSec float64) (io.ReadCloser, func(), error) {
+	return nil, nil, errors.New("pipe streaming not supported for local files")
+}
+func (s *FileStreamer) SupportsPipe() bool {
+	return false
+}
+
+// ffmpegFile is ffmpegLink without the HTTP reconnect options, which ffmpeg rejects for file inputs.
+func ffmpegFile(path string, seekSec float64) (io.ReadCloser, func(), error) {
+	var args []string
+	if seekSec > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", seekSec))
+	}
+	args = append(args,
+		"-i", path,
+		"-f", "s16le",
+		"-ar", fmt.Sprintf("%d", sampleRate),
+		"-ac", fmt.Sprintf("%d", channels),
+		"-loglevel", "warning",
+		"pipe:1",
+	)
+	cmd := exec.Command("ffmpeg", args...)
+
+	reader, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stdout pipe error: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("command start error: %w", err)
+	}
+
+	pr := NewProcessStream(cmd, reader)
+	cleanup := func() {
+		_ = cmd.Process.Kill()
+		_ = pr.WaitErr()
+	}
+
+	return pr, cleanup, nil
+}
@@ -0,0 +1,37 @@
+package ffmpeg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources/local"
+)
+
+func TestFileStreamerStaysInLocalRoot(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "library")
+	outside := filepath.Join(dir, "other", "song.ogg")
+	if err := os.MkdirAll(filepath.Dir(outside), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(outside, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, track := range []*parsers.TrackParse{
+		{URL: local.URI(outside), LocalRoot: root},
+		{URL: local.URI(filepath.Join(root, "..", "other", "song.ogg")), LocalRoot: root},
+		{URL: local.URI(outside)},
+	} {
+		_, _, err := (&FileStreamer{}).LinkStream(track, 0)
+		if err == nil || !strings.Contains(err.Error(), "outside the library") {
+			t.Fatalf("LinkStream(%s, root %q) = %v, want refusal", track.URL, track.LocalRoot, err)
+		}
+	}
+}
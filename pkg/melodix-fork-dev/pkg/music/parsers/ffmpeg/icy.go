@@ -0,0 +1,190 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+)
+
+// ICYStreamer fetches radio streams itself with "Icy-MetaData: 1", strips the in-band metadata blocks
+// and feeds the audio to ffmpeg on stdin. Song titles from the metadata go to track.OnStreamTitle.
+// Playlists (m3u, pls, HLS) are rejected so the next parser (ffmpeg-link) can take them.
+type ICYStreamer struct{}
+
+func (s *ICYStreamer) LinkStream(track *parsers.TrackParse, seekSec float64) (io.ReadCloser, func(), error) {
+	return ffmpegICY(track.URL, track.OnStreamTitle)
+}
+func (s *ICYStreamer) PipeStream(track *parsers.TrackParse, seekSec float64) (io.ReadCloser, func(), error) {
+	return nil, nil, errors.New("pipe streaming not supported for now")
+}
+func (s *ICYStreamer) SupportsPipe() bool {
+	return false
+}
+
+// icyPlaylistTypes are content types that describe a playlist rather than audio.
+var icyPlaylistTypes = []string{
+	"application/vnd.apple.mpegurl",
+	"application/x-mpegurl",
+	"audio/mpegurl",
+	"audio/x-mpegurl",
+	"audio/x-scpls",
+	"application/x-scpls",
+	"application/xspf+xml",
+}
+
+func ffmpegICY(url string, onTitle func(string)) (io.ReadCloser, func(), error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("request creation failed: %w", err)
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+	req.Header.Set("Icy-MetaData", "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("[ffmpeg-icy] request failed: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		resp.Body.Close()
+		cancel()
+		return nil, nil, fmt.Errorf("[ffmpeg-icy] unexpected status: %s", resp.Status)
+	}
+	ct := strings.ToLower(resp.Header.Get("Content-Type"))
+	for _, pt := range icyPlaylistTypes {
+		if strings.HasPrefix(ct, pt) {
+			resp.Body.Close()
+			cancel()
+			return nil, nil, fmt.Errorf("[ffmpeg-icy] playlist content type %q", ct)
+		}
+	}
+
+	var audio io.Reader = resp.Body
+	if metaint, err := strconv.Atoi(resp.Header.Get("Icy-Metaint")); err == nil && metaint > 0 {
+		audio = newICYReader(resp.Body, metaint, onTitle)
+	}
+
+	cmd := exec.Command("ffmpeg",
+		"-i", "pipe:0",
+		"-f", "s16le",
+		"-ar", fmt.Sprintf("%d", sampleRate),
+		"-ac", fmt.Sprintf("%d", channels),
+		"-loglevel", "warning",
+		"pipe:1",
+	)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, nil, fmt.Errorf("stdin pipe error: %w", err)
+	}
+	reader, err := cmd.StdoutPipe()
+	if err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, nil, fmt.Errorf("stdout pipe error: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		resp.Body.Close()
+		cancel()
+		return nil, nil, fmt.Errorf("command start error: %w", err)
+	}
+
+	// Our own copy loop (rather than cmd.Stdin) so Wait does not block on a stalled network read.
+	go func() {
+		_, _ = io.Copy(stdin, audio)
+		_ = stdin.Close()
+		_ = resp.Body.Close()
+	}()
+
+	pr := NewProcessStream(cmd, reader)
+	cleanup := func() {
+		cancel()
+		_ = cmd.Process.Kill()
+		_ = pr.WaitErr()
+	}
+
+	return pr, cleanup, nil
+}
+
+// icyReader removes the ICY metadata blocks interleaved every metaint audio bytes and reports StreamTitle
+// changes to onTitle.
+type icyReader struct {
+	r         io.Reader
+	metaint   int
+	remaining int // audio bytes left before the next metadata block
+	onTitle   func(string)
+	title     string
+}
+
+func newICYReader(r io.Reader, metaint int, onTitle func(string)) *icyReader {
+	return &icyReader{r: r, metaint: metaint, remaining: metaint, onTitle: onTitle}
+}
+
+func (ir *icyReader) Read(b []byte) (int, error) {
+	if ir.remaining == 0 {
+		if err := ir.readMetadata(); err != nil {
+			return 0, err
+		}
+		ir.remaining = ir.metaint
+	}
+	if len(b) > ir.remaining {
+		b = b[:ir.remaining]
+	}
+	n, err := ir.r.Read(b)
+	ir.remaining -= n
+	return n, err
+}
+
+// readMetadata consumes one block: a length byte (×16) followed by that many bytes of text.
+func (ir *icyReader) readMetadata() error {
+	var lenByte [1]byte
+	if _, err := io.ReadFull(ir.r, lenByte[:]); err != nil {
+		return err
+	}
+	size := int(lenByte[0]) * 16
+	if size == 0 {
+		return nil
+	}
+	meta := make([]byte, size)
+	if _, err := io.ReadFull(ir.r, meta); err != nil {
+		return err
+	}
+	title, ok := parseStreamTitle(string(meta))
+	if ok && title != ir.title {
+		ir.title = title
+		if ir.onTitle != nil {
+			ir.onTitle(title)
+		}
+	}
+	return nil
+}
+
+// parseStreamTitle extracts the StreamTitle value from ICY metadata, e.g. "StreamTitle='Artist - Song';".
+func parseStreamTitle(meta string) (string, bool) {
+	meta = strings.TrimRight(meta, "\x00")
+	const key = "StreamTitle='"
+	i := strings.Index(meta, key)
+	if i < 0 {
+		return "", false
+	}
+	rest := meta[i+len(key):]
+	// Titles may contain apostrophes; the value ends at the "';" that starts the next field or at the last quote.
+	end := strings.Index(rest, "';")
+	if end < 0 {
+		end = strings.LastIndex(rest, "'")
+	}
+	if end < 0 {
+		return "", false
+	}
+	return strings.TrimSpace(rest[:end]), true
+}
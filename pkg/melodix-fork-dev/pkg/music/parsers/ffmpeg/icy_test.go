@@ -0,0 +1,58 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// icyBlock encodes meta as an ICY metadata block (length byte ×16, zero padded).
+func icyBlock(meta string) []byte {
+	n := (len(meta) + 15) / 16
+	b := make([]byte, 1+n*16)
+	b[0] = byte(n)
+	copy(b[1:], meta)
+	return b
+}
+
+func TestICYReaderStripsMetadata(t *testing.T) {
+	var src bytes.Buffer
+	src.WriteString("aaaa")
+	src.Write(icyBlock("StreamTitle='Artist - Song';StreamUrl='';"))
+	src.WriteString("bbbb")
+	src.Write([]byte{0}) // empty block: no title change
+	src.WriteString("cccc")
+	src.Write(icyBlock("StreamTitle='Artist - Song';"))
+	src.WriteString("dd")
+
+	var titles []string
+	r := newICYReader(&src, 4, func(title string) { titles = append(titles, title) })
+	audio, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(audio) != "aaaabbbbccccdd" {
+		t.Fatalf("audio = %q", audio)
+	}
+	if len(titles) != 1 || titles[0] != "Artist - Song" {
+		t.Fatalf("titles = %q (repeated titles must be reported once)", titles)
+	}
+}
+
+func TestParseStreamTitle(t *testing.T) {
+	cases := map[string]string{
+		"StreamTitle='Don't Stop - Live';StreamUrl='';": "Don't Stop - Live",
+		"StreamTitle='Solo'\x00\x00":                    "Solo",
+		"StreamTitle='';":                               "",
+	}
+	for meta, want := range cases {
+		got, ok := parseStreamTitle(meta)
+		if !ok || got != want {
+			t.Fatalf("parseStreamTitle(%q) = %q, %v; want %q", meta, got, ok, want)
+		}
+	}
+	if _, ok := parseStreamTitle(strings.Repeat("x", 16)); ok {
+		t.Fatal("metadata without StreamTitle must not report a title")
+	}
+}
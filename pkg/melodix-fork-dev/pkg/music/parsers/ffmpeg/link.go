@@ -0,0 +1,44 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+)
+
+func ffmpegLink(url string, seekSec float64) (io.ReadCloser, func(), error) {
+	var args []string
+	// Live streams (radio) cannot seek; only pass -ss when a position was requested.
+	if seekSec > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", seekSec))
+	}
+	args = append(args,
+		"-reconnect", "1",
+		"-reconnect_streamed", "1",
+		"-reconnect_delay_max", "5",
+		"-i", url,
+		"-f", "s16le",
+		"-ar", fmt.Sprintf("%d", sampleRate),
+		"-ac", fmt.Sprintf("%d", channels),
+		"-loglevel", "warning",
+		"pipe:1",
+	)
+	cmd := exec.Command("ffmpeg", args...)
+
+	reader, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stdout pipe error: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("command start error: %w", err)
+	}
+
+	pr := NewProcessStream(cmd, reader)
+	cleanup := func() {
+		_ = cmd.Process.Kill()
+		_ = pr.WaitErr()
+	}
+
+	return pr, cleanup, nil
+}
@@ -0,0 +1,40 @@
+package ffmpeg
+
+import (
+	"context"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// probeTimeout bounds ffprobe, so a slow or unreachable link delays the track start by at most this.
+const probeTimeout = 10 * time.Second
+
+// probeDuration returns the duration ffprobe reads from input's container, or 0 when it is unknown
+// (live streams, ffprobe missing or failing). Files and attachments carry no duration of their own,
+// and the player needs one to bound seeks and to start gapless and crossfade transitions.
+func probeDuration(input string) time.Duration {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1",
+		input,
+	).Output()
+	if err != nil {
+		return 0
+	}
+	return parseProbeDuration(string(out))
+}
+
+// parseProbeDuration parses ffprobe's duration in seconds ("N/A" for streams without one).
+func parseProbeDuration(out string) time.Duration {
+	sec, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+	if err != nil || sec <= 0 || math.IsInf(sec, 0) || math.IsNaN(sec) {
+		return 0
+	}
+	return time.Duration(sec * float64(time.Second))
+}
@@ -0,0 +1,20 @@
+package ffmpeg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseProbeDuration(t *testing.T) {
+	for out, want := range map[string]time.Duration{
+		"183.456000\n": 183456 * time.Millisecond,
+		"N/A\n":        0,
+		"":             0,
+		"-1":           0,
+		"inf":          0,
+	} {
+		if got := parseProbeDuration(out); got != want {
+			t.Errorf("parseProbeDuration(%q) = %v, want %v", out, got, want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package ffmpeg
+
+import (
+	"errors"
+	"io"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+const (
+	channels   = 2
+	sampleRate = 48000
+)
+
+type Streamer struct{}
+
+func (s *Streamer) LinkStream(track *parsers.TrackParse, seekSec float64) (io.ReadCloser, func(), error) {
+	// Direct links (e.g. uploaded attachments) name no duration; radio is live and has none to probe.
+	if track.Duration <= 0 && track.SourceInfo.SourceName != sources.Radio {
+		track.Duration = probeDuration(track.URL)
+	}
+	return ffmpegLink(track.URL, seekSec)
+}
+func (s *Streamer) PipeStream(track *parsers.TrackParse, seekSec float64) (io.ReadCloser, func(), error) {
+	return nil, nil, errors.New("pipe streaming not supported for now")
+}
+func (s *Streamer) SupportsPipe() bool {
+	return false
+}
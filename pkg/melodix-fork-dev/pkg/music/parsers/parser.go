@@ -0,0 +1,26 @@
+// Package parsers defines the Streamer interface and track type for opening PCM streams from URLs.
+package parsers
+
+import (
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+type TrackParse struct {
+	URL                 string
+	Title               string
+	Artist              string
+	Duration            time.Duration
+	CurrentPlayDuration time.Duration
+	CurrentParser       string
+	SourceInfo          sources.TrackInfo
+	// RequestedBy identifies who queued the track (e.g. a Discord user ID); empty for CLI or autoplay.
+	RequestedBy string
+	// OnStreamTitle is called by parsers that read in-band stream metadata (ICY radio) when the song
+	// changes. The player sets it before opening the stream; nil means nobody listens.
+	OnStreamTitle func(title string)
+	// LocalRoot is the directory file:// tracks must lie in. The player sets it before opening the
+	// stream; empty refuses local files.
+	LocalRoot string
+}
@@ -0,0 +1,113 @@
+package player
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// LoopMode controls what happens when a track finishes naturally (not on skip or stop).
+type LoopMode string
+
+const (
+	// LoopOff plays the queue once and stops.
+	LoopOff LoopMode = "off"
+	// LoopTrack replays the finished track.
+	LoopTrack LoopMode = "track"
+	// LoopQueue appends the finished track to the end of the queue.
+	LoopQueue LoopMode = "queue"
+	// LoopAutoplay asks the AutoplaySource for a track whenever the queue runs dry.
+	LoopAutoplay LoopMode = "autoplay"
+)
+
+var ErrUnknownLoopMode = errors.New("unknown loop mode")
+
+// ParseLoopMode maps a stored or user-supplied name to a LoopMode ("" is LoopOff).
+func ParseLoopMode(s string) (LoopMode, error) {
+	switch m := LoopMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "", LoopOff:
+		return LoopOff, nil
+	case LoopTrack, LoopQueue, LoopAutoplay:
+		return m, nil
+	default:
+		return LoopOff, ErrUnknownLoopMode
+	}
+}
+
+// AutoplaySource suggests the next track when the queue is empty in LoopAutoplay mode.
+// last is the track that just finished. ok is false when there is nothing to suggest.
+type AutoplaySource interface {
+	NextAutoplay(guildID string, last parsers.TrackParse) (track sources.TrackInfo, ok bool)
+}
+
+// SetLoopMode sets what happens after a track finishes.
+func (p *Player) SetLoopMode(mode LoopMode) {
+	p.mu.Lock()
+	p.loopMode = mode
+	p.mu.Unlock()
+	p.log.Info().Str("mode", string(mode)).Msg("loop_mode_set")
+}
+
+// LoopMode returns the current loop mode.
+func (p *Player) LoopMode() LoopMode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.loopMode == "" {
+		return LoopOff
+	}
+	return p.loopMode
+}
+
+// SetAutoplaySource sets the source used by LoopAutoplay. Pass nil to disable autoplay suggestions.
+func (p *Player) SetAutoplaySource(src AutoplaySource) {
+	p.mu.Lock()
+	p.autoplay = src
+	p.mu.Unlock()
+}
+
+// requeueFinished applies the loop mode to a track that just finished playing.
+func (p *Player) requeueFinished(finished parsers.TrackParse) {
+	p.mu.Lock()
+	mode := p.loopMode
+	src := p.autoplay
+	gid := p.guildID
+	queueLen := len(p.queue)
+	p.mu.Unlock()
+
+	switch mode {
+	case LoopTrack:
+		p.mu.Lock()
+		p.queue = append([]parsers.TrackParse{finished}, p.queue...)
+		p.mu.Unlock()
+		p.log.Info().Str("title", finished.Title).Msg("loop_track_requeued")
+	case LoopQueue:
+		p.mu.Lock()
+		p.queue = append(p.queue, finished)
+		p.mu.Unlock()
+		p.log.Info().Str("title", finished.Title).Msg("loop_queue_requeued")
+	case LoopAutoplay:
+		if queueLen > 0 || src == nil {
+			return
+		}
+		next, ok := src.NextAutoplay(gid, finished)
+		if !ok {
+			p.log.Info().Msg("autoplay_no_suggestion")
+			return
+		}
+		if len(next.AvailableParsers) == 0 {
+			p.log.Warn().Str("title", next.Title).Msg("autoplay_skipped_no_parsers")
+			return
+		}
+		p.mu.Lock()
+		p.queue = append(p.queue, parsers.TrackParse{
+			URL:           next.URL,
+			Title:         next.Title,
+			CurrentParser: next.AvailableParsers[0],
+			SourceInfo:    next,
+		})
+		p.mu.Unlock()
+		p.log.Info().Str("title", next.Title).Str("url", next.URL).Msg("autoplay_track_queued")
+	}
+}
@@ -0,0 +1,64 @@
+package player
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// bytesPerSecond is the PCM byte rate of the s16le stream handed to sinks.
+const bytesPerSecond = stream.SampleRate * stream.Channels * 2
+
+// playbackReader sits between the RecoveryStream and the sink. It blocks reads while the player
+// is paused (the sink owns the read loop, so this is where pause takes effect) and counts the
+// bytes handed to the sink so the player can report the playback position.
+type playbackReader struct {
+	p      *Player
+	src    io.ReadCloser
+	stop   <-chan struct{}
+	offset time.Duration
+	read   atomic.Int64
+
+	// continued and crossfade are the run's transition settings, passed to a sink.TransitionSink.
+	continued bool
+	crossfade time.Duration
+	// next carries the preloaded next track to the sink when the crossfade begins (see Player.onProgress).
+	next chan io.Reader
+	// handedOff is set once next has been sent (guarded by Player.mu).
+	handedOff bool
+}
+
+func (r *playbackReader) Read(b []byte) (int, error) {
+	r.p.mu.Lock()
+	resume := r.p.resumeCh
+	r.p.mu.Unlock()
+	if resume != nil {
+		select {
+		case <-resume:
+		case <-r.stop:
+			return 0, stream.ErrPlaybackStopped
+		}
+	}
+
+	n, err := r.src.Read(b)
+	r.read.Add(int64(n))
+	r.p.onProgress(r)
+	return n, err
+}
+
+// Close is a no-op: runPlayback owns the underlying RecoveryStream.
+func (r *playbackReader) Close() error {
+	return nil
+}
+
+// position returns the seek offset plus the audio already handed to the sink.
+func (r *playbackReader) position() time.Duration {
+	return r.offset + r.played()
+}
+
+// played returns the duration of audio handed to the sink by this reader.
+func (r *playbackReader) played() time.Duration {
+	return time.Duration(float64(r.read.Load()) / bytesPerSecond * float64(time.Second))
+}
@@ -0,0 +1,829 @@
+// Package player provides a queue-based playback engine with pluggable sinks and resolvers.
+package player
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sink"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/stream"
+	"github.com/rs/zerolog"
+)
+
+type Status string
+
+const (
+	StatusPlaying Status = "Playing"
+	StatusAdded   Status = "Track(s) Added"
+	StatusStopped Status = "Playback Stopped"
+	StatusPaused  Status = "Playback Paused"
+	StatusResumed Status = "Playback Resumed"
+	StatusError   Status = "Error"
+	// StatusStreamTitle reports a new song on a live stream (see StreamTitle).
+	StatusStreamTitle Status = "Stream Title Changed"
+)
+
+var (
+	ErrNoTrackPlaying    = errors.New("no track is currently playing")
+	ErrNoTracksInQueue   = errors.New("no tracks in queue")
+	ErrNoParsersForTrack = errors.New("track has no available parsers")
+	ErrQueueIndexRange   = errors.New("queue index out of range")
+	ErrNotPaused         = errors.New("playback is not paused")
+	ErrSeekOutOfRange    = errors.New("seek position is beyond the end of the track")
+	// ErrSinkUnavailable indicates voice/sink could not be obtained (e.g. join timeout or no permission).
+	// When runPlayback returns this, the player skips calling PlayNext to avoid spinning through the queue.
+	ErrSinkUnavailable = errors.New("sink unavailable")
+)
+
+// Resolver resolves input (URL or search query) to track info. The player uses this to enqueue tracks.
+// Implementations can be the default resolver (pkg/music/resolve) or a mock/custom resolver.
+type Resolver interface {
+	Resolve(input, source, parser string) ([]sources.TrackInfo, error)
+}
+
+// PlaybackRecorder is called once per played track when it stops (finished, skipped or failed),
+// e.g. to persist guild playback history. Discord wiring sets guildID; CLI/examples leave recorder nil.
+type PlaybackRecorder interface {
+	Record(guildID string, play PlaybackRecord)
+}
+
+type Player struct {
+	// mu protects queue, currTrack, playing, starting, target, and the stop/playback fields below.
+	mu sync.Mutex
+	// playing is true while PCM is streaming after the stream has opened successfully.
+	playing bool
+	// starting is true while the current track is still resolving/opening; IsPlaying is playing || starting.
+	starting bool
+	// resumeCh is non-nil while paused; playbackReader blocks on it until Resume closes it.
+	resumeCh chan struct{}
+	// reader is the active run's playbackReader (nil when idle); used for Position.
+	reader *playbackReader
+	// playNextMu ensures only one goroutine at a time runs dequeue + startTrack (including the slow Open phase),
+	// so concurrent PlayNext or Resume cannot start two tracks.
+	playNextMu sync.Mutex
+	// currTrack is the track being opened or actively playing (nil when idle).
+	currTrack *parsers.TrackParse
+	// streamTitle is the song currently announced by currTrack's stream metadata (radio); "" when unknown.
+	streamTitle string
+	// queue holds tracks waiting to play (FIFO).
+	queue []parsers.TrackParse
+
+	// resolver turns user input into track metadata for enqueue.
+	resolver Resolver
+	// sinkProvider supplies the audio sink (e.g. Discord VC or speaker) for a target channel.
+	sinkProvider sink.Provider
+
+	// target is the voice channel ID for Discord playback, or "" for CLI/non-voice.
+	target string
+	// guildID is set by the Discord voice layer for playback recording; empty for CLI.
+	guildID string
+	// recorder persists finished plays (nil for CLI).
+	recorder PlaybackRecorder
+	// active is the play being recorded: set when a track starts, reported when it ends.
+	active *PlaybackRecord
+	// seeking is true while Seek restarts the current track, so the interrupted run does not end the play.
+	seeking bool
+	// loopMode decides what runPlayback does with a naturally finished track ("" means LoopOff).
+	loopMode LoopMode
+	// autoplay suggests tracks for LoopAutoplay (nil disables it).
+	autoplay AutoplaySource
+	// crossfade and gapless control transitions between tracks (see SetTransitions).
+	crossfade time.Duration
+	gapless   bool
+	// preload is the next track opened ahead of a transition (nil when none).
+	preload *preload
+	// continueNext marks the next started track as directly following a naturally finished one.
+	continueNext bool
+
+	log zerolog.Logger
+
+	// stopOnce closes stopPlayback at most once per playback run.
+	stopOnce sync.Once
+	// stopPlayback signals the active Stream loop to stop (skip, stop, or starting a new track).
+	stopPlayback chan struct{}
+	// playbackDone is closed when the runPlayback goroutine for the current run exits.
+	playbackDone chan struct{}
+	// PlayerStatus receives playback lifecycle updates for UI (buffered; drops if full).
+	PlayerStatus chan Status
+
+	transportRecoveryMode string
+	transportSoftAttempts int
+	localRoot             string
+}
+
+type Options struct {
+	// Logger is optional. If zero, the player logs nothing.
+	Logger zerolog.Logger
+	// TransportRecoveryMode controls behavior on stream.ErrVoiceTransport.
+	// Supported: "hard" (default), "soft".
+	TransportRecoveryMode string
+	// TransportSoftAttempts bounds how many soft retries we do before falling back to hard recovery.
+	// Applies to mode="soft" only. Default 1.
+	TransportSoftAttempts int
+	// LocalRoot is the only directory file:// tracks may be played from; empty refuses local files.
+	LocalRoot string
+}
+
+// New creates a new Player. target is set per playback via PlayNext(target).
+func New(sinkProvider sink.Provider, res Resolver) *Player {
+	return NewWithOptions(sinkProvider, res, Options{})
+}
+
+// NewWithOptions creates a new Player with custom options.
+func NewWithOptions(sinkProvider sink.Provider, res Resolver, opts Options) *Player {
+	mode := opts.TransportRecoveryMode
+	if mode == "" {
+		mode = "hard"
+	}
+	softAttempts := opts.TransportSoftAttempts
+	if softAttempts <= 0 {
+		softAttempts = 1
+	}
+
+	l := opts.Logger
+	if l.GetLevel() == zerolog.NoLevel {
+		l = zerolog.Nop()
+	}
+
+	return &Player{
+		resolver:              res,
+		sinkProvider:          sinkProvider,
+		queue:                 make([]parsers.TrackParse, 0),
+		stopPlayback:          make(chan struct{}),
+		playbackDone:          make(chan struct{}),
+		PlayerStatus:          make(chan Status, 10),
+		transportRecoveryMode: mode,
+		transportSoftAttempts: softAttempts,
+		localRoot:             opts.LocalRoot,
+		log:                   l,
+	}
+}
+
+// SetGuildID sets the Discord guild id for this player (used when invoking the playback recorder).
+func (p *Player) SetGuildID(guildID string) {
+	p.mu.Lock()
+	p.guildID = guildID
+	p.mu.Unlock()
+}
+
+// SetRecorder sets an optional callback invoked after a track successfully starts. Pass nil to disable.
+func (p *Player) SetRecorder(r PlaybackRecorder) {
+	p.mu.Lock()
+	p.recorder = r
+	p.mu.Unlock()
+}
+
+// Enqueue adds tracks to the queue
+func (p *Player) Enqueue(input string, source string, parser string) error {
+	p.log.Info().Str("input", input).Str("source", source).Str("parser", parser).Msg("enqueue_called")
+	tracksInfo, err := p.resolver.Resolve(input, source, parser)
+	if err != nil {
+		p.log.Warn().Err(err).Msg("resolve_tracks_failed")
+		p.emitStatus(StatusError)
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tracksParse := make([]parsers.TrackParse, 0, len(tracksInfo))
+	for _, trackInfo := range tracksInfo {
+		if len(trackInfo.AvailableParsers) == 0 {
+			p.log.Warn().Str("title", trackInfo.Title).Msg("track_skipped_no_parsers")
+			continue
+		}
+		tracksParse = append(tracksParse, parsers.TrackParse{
+			URL:           trackInfo.URL,
+			Title:         trackInfo.Title,
+			CurrentParser: trackInfo.AvailableParsers[0],
+			SourceInfo:    trackInfo,
+		})
+	}
+	if len(tracksParse) == 0 {
+		p.emitStatus(StatusError)
+		return ErrNoParsersForTrack
+	}
+
+	p.queue = append(p.queue, tracksParse...)
+	p.log.Info().Int("added", len(tracksParse)).Int("queue_len", len(p.queue)).Msg("queue_tracks_added")
+	if p.currTrack != nil {
+		p.emitStatus(StatusAdded)
+	}
+	return nil
+}
+
+// EnqueueTrackInfo enqueues a single pre-resolved track (avoids double resolve when caller already has TrackInfo).
+func (p *Player) EnqueueTrackInfo(trackInfo sources.TrackInfo) error {
+	return p.EnqueueTrackInfoFor(trackInfo, "")
+}
+
+// EnqueueTrackInfoFor is EnqueueTrackInfo with the requester recorded on the queued track.
+func (p *Player) EnqueueTrackInfoFor(trackInfo sources.TrackInfo, requestedBy string) error {
+	if len(trackInfo.AvailableParsers) == 0 {
+		p.emitStatus(StatusError)
+		return ErrNoParsersForTrack
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.queue = append(p.queue, parsers.TrackParse{
+		URL:           trackInfo.URL,
+		Title:         trackInfo.Title,
+		CurrentParser: trackInfo.AvailableParsers[0],
+		SourceInfo:    trackInfo,
+		RequestedBy:   requestedBy,
+	})
+	p.log.Info().Int("added", 1).Int("queue_len", len(p.queue)).Msg("queue_tracks_added")
+	if p.currTrack != nil {
+		p.emitStatus(StatusAdded)
+	}
+	return nil
+}
+
+// PlayNext stops current track (if any) and plays the next in queue.
+// target is the voice channel ID for Discord, or "" for CLI.
+func (p *Player) PlayNext(target string) error {
+	return p.playNextAt(target, 0)
+}
+
+// PlayNextAt is PlayNext with the first track opened at offset (e.g. restoring an interrupted session).
+// If it fails to start, the following tracks play from the beginning.
+func (p *Player) PlayNextAt(target string, offset time.Duration) error {
+	return p.playNextAt(target, offset)
+}
+
+func (p *Player) playNextAt(target string, offset time.Duration) error {
+	p.log.Info().Int("queue_len", len(p.queue)).Dur("offset", offset).Msg("play_next_called")
+	for {
+		if p.IsPlaying() {
+			p.log.Info().Msg("stopping_current_before_next")
+			_ = p.Stop(false)
+		}
+
+		p.playNextMu.Lock()
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			p.playNextMu.Unlock()
+			p.log.Info().Msg("queue_empty")
+			return ErrNoTracksInQueue
+		}
+
+		track := p.queue[0]
+		p.queue = p.queue[1:]
+		p.target = target
+		p.mu.Unlock()
+
+		p.log.Info().Str("title", track.Title).Str("url", track.URL).Msg("track_attempt_play")
+
+		p.mu.Lock()
+		p.active = &PlaybackRecord{Track: cloneTrackParse(track), StartedAt: time.Now(), ChannelID: target}
+		p.mu.Unlock()
+
+		var err error
+		if pl := p.takePreload(track); pl != nil && offset == 0 {
+			err = p.startPreloaded(pl)
+		} else {
+			if pl != nil {
+				_ = pl.rs.Close()
+			}
+			err = p.startTrack(&track, false, offset)
+		}
+		p.playNextMu.Unlock()
+
+		offset = 0
+		if err != nil {
+			p.log.Warn().Str("title", track.Title).Err(err).Msg("track_skipped_error")
+			p.endPlay(EndError)
+			continue
+		}
+
+		p.log.Info().Str("title", track.Title).Int("queue_len", len(p.Queue())).Msg("track_now_playing")
+		return nil
+	}
+}
+
+// Stop safely stops current playback. When disconnect is true, clears queue and releases the sink (e.g. leave VC).
+func (p *Player) Stop(disconnect bool) error {
+	p.log.Info().Bool("disconnect", disconnect).Msg("stop_called")
+
+	var doneCh chan struct{}
+	p.mu.Lock()
+	doneCh = p.playbackDone
+	p.clearPauseLocked()
+	p.stopOnce.Do(func() {
+		close(p.stopPlayback)
+	})
+	target := p.target
+	p.mu.Unlock()
+
+	if p.IsPlaying() && doneCh != nil {
+		select {
+		case <-doneCh:
+			p.log.Info().Msg("playback_goroutine_done")
+		case <-time.After(10 * time.Second):
+			p.log.Warn().Msg("stop_timeout_waiting_playback")
+		}
+	}
+
+	p.mu.Lock()
+	p.playing = false
+	p.starting = false
+	p.currTrack = nil
+	p.reader = nil
+	p.continueNext = false
+
+	if disconnect {
+		p.log.Info().Msg("disconnect_and_clear_queue")
+		p.queue = nil
+		p.target = ""
+		p.dropPreloadLocked()
+		p.sinkProvider.ReleaseSink(target)
+	}
+
+	p.stopPlayback = make(chan struct{})
+	p.playbackDone = make(chan struct{})
+	p.stopOnce = sync.Once{}
+	p.emitStatus(StatusStopped)
+	p.mu.Unlock()
+
+	p.log.Info().Msg("stop_finished")
+	return nil
+}
+
+// Pause holds the current track at its position. The sink keeps its read loop but receives no PCM until Resume.
+func (p *Player) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.playing || p.currTrack == nil {
+		return ErrNoTrackPlaying
+	}
+	if p.resumeCh != nil {
+		return nil
+	}
+	p.resumeCh = make(chan struct{})
+	p.log.Info().Str("title", p.currTrack.Title).Msg("playback_paused")
+	p.emitStatus(StatusPaused)
+	return nil
+}
+
+// Resume continues a paused track from where it was held.
+func (p *Player) Resume() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.resumeCh == nil {
+		return ErrNotPaused
+	}
+	p.clearPauseLocked()
+	p.log.Info().Msg("playback_resumed")
+	p.emitStatus(StatusResumed)
+	return nil
+}
+
+// clearPauseLocked releases a blocked playbackReader. Caller must hold p.mu.
+func (p *Player) clearPauseLocked() {
+	if p.resumeCh != nil {
+		close(p.resumeCh)
+		p.resumeCh = nil
+	}
+}
+
+// IsPaused reports whether the current track is paused.
+func (p *Player) IsPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.resumeCh != nil
+}
+
+// Position returns the approximate playback position of the current track (0 when idle).
+func (p *Player) Position() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.reader == nil {
+		return 0
+	}
+	return p.reader.position()
+}
+
+// PlayStartedAt returns when the current play of a track began (zero when idle). Seeks and stream
+// restarts keep it, so together with the track URL it identifies the play.
+func (p *Player) PlayStartedAt() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.active == nil {
+		return time.Time{}
+	}
+	return p.active.StartedAt
+}
+
+// Seek restarts the current track at pos. The already-resolved track is reopened through its parser
+// with an ffmpeg start offset; nothing is re-resolved and playback history is not recorded again.
+// A paused track resumes at the new position.
+func (p *Player) Seek(pos time.Duration) error {
+	if pos < 0 {
+		pos = 0
+	}
+
+	p.playNextMu.Lock()
+	defer p.playNextMu.Unlock()
+
+	p.mu.Lock()
+	curr := p.currTrack
+	playing := p.playing
+	p.mu.Unlock()
+	if curr == nil || !playing {
+		return ErrNoTrackPlaying
+	}
+	if curr.Duration > 0 && pos >= curr.Duration {
+		return ErrSeekOutOfRange
+	}
+
+	track := cloneTrackParse(*curr)
+	p.log.Info().Str("title", track.Title).Dur("pos", pos).Msg("seek_called")
+	p.mu.Lock()
+	p.seeking = true
+	p.mu.Unlock()
+	_ = p.Stop(false)
+	// The handover is planned from the old position; plan it again from the new one.
+	p.mu.Lock()
+	p.dropPreloadLocked()
+	p.mu.Unlock()
+
+	err := p.startTrack(&track, false, pos)
+	p.mu.Lock()
+	p.seeking = false
+	p.mu.Unlock()
+	if err != nil {
+		p.endPlay(EndError)
+	}
+	return err
+}
+
+// IsPlaying returns true while a track is opening or playing (a paused track still counts as playing).
+func (p *Player) IsPlaying() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.playing || p.starting
+}
+
+// CurrentTrack returns currently playing track (nil if none)
+func (p *Player) CurrentTrack() *parsers.TrackParse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.currTrack
+}
+
+// Queue returns a copy of current queue
+func (p *Player) Queue() []parsers.TrackParse {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.queue)
+}
+
+// RemoveFromQueue removes the track at index (0-based) from the queue and returns it.
+func (p *Player) RemoveFromQueue(index int) (parsers.TrackParse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if index < 0 || index >= len(p.queue) {
+		return parsers.TrackParse{}, ErrQueueIndexRange
+	}
+	track := p.queue[index]
+	p.queue = slices.Delete(p.queue, index, index+1)
+	p.log.Info().Int("index", index).Int("queue_len", len(p.queue)).Msg("queue_track_removed")
+	return track, nil
+}
+
+// MoveInQueue moves the track at from to position to (both 0-based), shifting the tracks in between.
+func (p *Player) MoveInQueue(from, to int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if from < 0 || from >= len(p.queue) || to < 0 || to >= len(p.queue) {
+		return ErrQueueIndexRange
+	}
+	if from == to {
+		return nil
+	}
+	track := p.queue[from]
+	p.queue = slices.Delete(p.queue, from, from+1)
+	p.queue = slices.Insert(p.queue, to, track)
+	p.log.Info().Int("from", from).Int("to", to).Msg("queue_track_moved")
+	return nil
+}
+
+// ClearQueue drops all queued tracks (the current track keeps playing) and returns how many were removed.
+func (p *Player) ClearQueue() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := len(p.queue)
+	p.queue = p.queue[:0]
+	p.log.Info().Int("removed", n).Msg("queue_cleared")
+	return n
+}
+
+// ShuffleQueue randomizes the order of queued tracks.
+func (p *Player) ShuffleQueue() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	rand.Shuffle(len(p.queue), func(i, j int) {
+		p.queue[i], p.queue[j] = p.queue[j], p.queue[i]
+	})
+	p.log.Info().Int("queue_len", len(p.queue)).Msg("queue_shuffled")
+}
+
+func cloneTrackParse(tp parsers.TrackParse) parsers.TrackParse {
+	out := tp
+	if len(tp.SourceInfo.AvailableParsers) > 0 {
+		out.SourceInfo.AvailableParsers = slices.Clone(tp.SourceInfo.AvailableParsers)
+	}
+	return out
+}
+
+// startTrack launches playback goroutine. offset is the position the stream opens at (0 for a fresh start).
+func (p *Player) startTrack(track *parsers.TrackParse, resumed bool, offset time.Duration) error {
+	p.log.Info().
+		Str("title", track.Title).
+		Str("url", track.URL).
+		Str("parser", track.CurrentParser).
+		Int("queue_len", len(p.queue)).
+		Msg("playback_preparing")
+
+	p.mu.Lock()
+	p.stopPlayback = make(chan struct{})
+	p.playbackDone = make(chan struct{})
+	p.stopOnce = sync.Once{}
+	p.starting = true
+	p.playing = false
+	p.currTrack = track
+	p.streamTitle = ""
+	p.mu.Unlock()
+
+	track.OnStreamTitle = func(title string) { p.setStreamTitle(track, title) }
+	track.LocalRoot = p.localRoot
+	rs := stream.NewRecoveryStreamWithLogger(track, p.log)
+	if err := rs.Open(offset.Seconds()); err != nil {
+		p.log.Error().Err(err).Msg("stream_open_failed")
+		p.mu.Lock()
+		p.starting = false
+		p.currTrack = nil
+		p.mu.Unlock()
+		return err
+	}
+
+	p.launchTrack(track, rs, resumed, offset)
+	return nil
+}
+
+// startPreloaded starts a track whose stream was opened ahead of time, continuing after the part a
+// crossfade already played.
+func (p *Player) startPreloaded(pl *preload) error {
+	track := pl.track
+	p.log.Info().Str("title", track.Title).Str("parser", track.CurrentParser).Dur("offset", pl.played()).Msg("playback_preloaded")
+
+	p.mu.Lock()
+	p.stopPlayback = make(chan struct{})
+	p.playbackDone = make(chan struct{})
+	p.stopOnce = sync.Once{}
+	p.starting = true
+	p.playing = false
+	p.currTrack = track
+	p.streamTitle = ""
+	if p.active != nil {
+		p.active.Track = cloneTrackParse(*track)
+	}
+	p.mu.Unlock()
+
+	p.launchTrack(track, pl.rs, false, pl.played())
+	return nil
+}
+
+// launchTrack reports the opened track and starts its playback goroutine.
+func (p *Player) launchTrack(track *parsers.TrackParse, rs *stream.RecoveryStream, resumed bool, offset time.Duration) {
+	if resumed {
+		p.emitStatus(StatusResumed)
+		p.log.Info().Str("title", track.Title).Msg("track_resuming")
+	} else {
+		p.emitStatus(StatusPlaying)
+		p.log.Info().Str("title", track.Title).Msg("track_starting")
+	}
+
+	p.mu.Lock()
+	p.starting = false
+	p.playing = true
+	p.currTrack = track
+	stopCh := p.stopPlayback
+	doneCh := p.playbackDone
+	reader := &playbackReader{
+		p:         p,
+		src:       rs,
+		stop:      stopCh,
+		offset:    offset,
+		continued: p.continueNext,
+		crossfade: p.crossfade,
+		next:      make(chan io.Reader, 1),
+	}
+	p.continueNext = false
+	p.reader = reader
+	p.mu.Unlock()
+
+	go func() {
+		err := p.runPlayback(rs, reader, stopCh, doneCh)
+		if err != nil {
+			p.log.Warn().Str("title", track.Title).Err(err).Msg("playback_error")
+			if errors.Is(err, ErrSinkUnavailable) {
+				return
+			}
+			if errors.Is(err, stream.ErrVoiceTransport) {
+				return
+			}
+			if errors.Is(err, stream.ErrPlaybackStopped) {
+				return
+			}
+		}
+
+		p.mu.Lock()
+		target := p.target
+		p.continueNext = err == nil && p.transitionsLocked()
+		p.mu.Unlock()
+		nextErr := p.PlayNext(target)
+		if errors.Is(nextErr, ErrNoTracksInQueue) {
+			_ = p.Stop(true)
+			return
+		}
+		if nextErr != nil {
+			p.log.Warn().Err(nextErr).Msg("play_next_after_track_failed")
+		}
+	}()
+}
+
+// maxVoiceTransportAttempts bounds Sink rejoin + Opus transport retries for one track
+// (Discord gateway/voice), distinct from RecoveryStream media recovery.
+const maxVoiceTransportAttempts = 3
+
+// runPlayback streams to the sink. stopCh and doneCh are for this run only.
+func (p *Player) runPlayback(rs *stream.RecoveryStream, reader *playbackReader, stopCh, doneCh chan struct{}) (runErr error) {
+	defer rs.Close()
+	defer close(doneCh)
+	// Runs before doneCh closes, so Stop (and Seek) observe the play as already accounted for.
+	defer func() { p.endRun(reader, runErr) }()
+
+	p.mu.Lock()
+	ct := p.currTrack
+	target := p.target
+	recoveryMode := p.transportRecoveryMode
+	softAttempts := p.transportSoftAttempts
+	p.mu.Unlock()
+
+	title := "(unknown)"
+	if ct != nil {
+		title = ct.Title
+	}
+	p.log.Info().Str("title", title).Msg("playback_running")
+
+	var err error
+	softUsed := 0
+	for attempt := 1; attempt <= maxVoiceTransportAttempts; attempt++ {
+		var audioSink sink.AudioSink
+		audioSink, err = p.sinkProvider.Sink(target)
+		if err != nil {
+			p.log.Warn().Int("attempt", attempt).Int("max", maxVoiceTransportAttempts).Err(err).Msg("sink_get_failed")
+			p.sinkProvider.InvalidateSink()
+			if attempt == maxVoiceTransportAttempts {
+				p.mu.Lock()
+				p.playing = false
+				p.currTrack = nil
+				p.mu.Unlock()
+				p.emitStatus(StatusError)
+				return errors.Join(ErrSinkUnavailable, fmt.Errorf("get sink: %w", err))
+			}
+			time.Sleep(time.Duration(attempt) * 400 * time.Millisecond)
+			continue
+		}
+
+		if ts, ok := audioSink.(sink.TransitionSink); ok && attempt == 1 && (reader.continued || reader.crossfade > 0) {
+			err = ts.StreamTransition(reader, stopCh, sink.Transition{
+				Continued: reader.continued,
+				Crossfade: reader.crossfade,
+				Next:      reader.next,
+			})
+		} else {
+			err = audioSink.Stream(reader, stopCh)
+		}
+		if err == nil {
+			break
+		}
+		if errors.Is(err, stream.ErrPlaybackStopped) {
+			p.mu.Lock()
+			p.playing = false
+			p.currTrack = nil
+			p.mu.Unlock()
+			p.log.Info().Msg("playback_stopped_by_user")
+			p.emitStatus(StatusStopped)
+			return err
+		}
+		if errors.Is(err, stream.ErrVoiceTransport) {
+			p.log.Warn().Int("attempt", attempt).Int("max", maxVoiceTransportAttempts).Err(err).Msg("voice_transport_error")
+
+			softTry := recoveryMode == "soft" && softUsed < softAttempts
+			mode := "hard"
+			if softTry {
+				softUsed++
+				mode = "soft"
+				p.log.Info().Int("used", softUsed).Int("max", softAttempts).Msg("transport_recovery_soft_reopen_stream")
+			} else {
+				p.log.Info().Msg("transport_recovery_hard_invalidate_sink")
+				p.sinkProvider.InvalidateSink()
+			}
+			if obs, ok := p.sinkProvider.(sink.RecoveryObserver); ok {
+				obs.TransportRecovery(target, mode)
+			}
+
+			if reopenErr := rs.ReopenAfterTransportFailure(); reopenErr != nil {
+				p.mu.Lock()
+				p.playing = false
+				p.currTrack = nil
+				p.mu.Unlock()
+				p.emitStatus(StatusError)
+				return fmt.Errorf("voice transport failed, could not reopen stream: %w", reopenErr)
+			}
+			if attempt == maxVoiceTransportAttempts {
+				p.mu.Lock()
+				p.playing = false
+				p.currTrack = nil
+				p.mu.Unlock()
+				p.emitStatus(StatusError)
+				return err
+			}
+			time.Sleep(time.Duration(attempt) * 400 * time.Millisecond)
+			continue
+		}
+		break
+	}
+
+	p.mu.Lock()
+	p.playing = false
+	p.currTrack = nil
+	p.mu.Unlock()
+
+	if err != nil {
+		p.emitStatus(StatusError)
+		p.log.Warn().Err(err).Msg("playback_finished_error")
+		return fmt.Errorf("playback error: %w", err)
+	}
+	p.log.Info().Msg("playback_stopped")
+	p.emitStatus(StatusStopped)
+
+	if ct != nil {
+		p.requeueFinished(cloneTrackParse(*ct))
+	}
+
+	if len(p.Queue()) == 0 {
+		p.log.Info().Msg("queue_empty_auto_stop")
+		_ = p.Stop(true)
+	}
+
+	return nil
+}
+
+// setStreamTitle records a metadata title for track, ignoring late reports from a stream that is no longer current.
+func (p *Player) setStreamTitle(track *parsers.TrackParse, title string) {
+	p.mu.Lock()
+	if p.currTrack != track || p.streamTitle == title {
+		p.mu.Unlock()
+		return
+	}
+	p.streamTitle = title
+	p.mu.Unlock()
+	p.log.Info().Str("stream_title", title).Msg("stream_title_changed")
+	p.emitStatus(StatusStreamTitle)
+}
+
+// StreamTitle returns the song announced by the current stream's metadata (live radio), or "" when unknown.
+func (p *Player) StreamTitle() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.currTrack == nil {
+		return ""
+	}
+	return p.streamTitle
+}
+
+func (p *Player) emitStatus(status Status) {
+	select {
+	case p.PlayerStatus <- status:
+	default:
+		p.log.Debug().Str("status", string(status)).Msg("player_status_dropped")
+	}
+}
+
+// ChannelID returns the current target (voice channel ID for Discord, "" for CLI).
+func (p *Player) ChannelID() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.target
+}
@@ -0,0 +1,228 @@
+package player
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+func queueTitles(p *Player) []string {
+	var out []string
+	for _, t := range p.Queue() {
+		out = append(out, t.Title)
+	}
+	return out
+}
+
+func newQueuedPlayer(titles ...string) *Player {
+	p := New(nil, nil)
+	for _, t := range titles {
+		p.queue = append(p.queue, parsers.TrackParse{Title: t})
+	}
+	return p
+}
+
+func TestRemoveFromQueue(t *testing.T) {
+	t.Parallel()
+	p := newQueuedPlayer("a", "b", "c")
+	got, err := p.RemoveFromQueue(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "b" {
+		t.Fatalf("removed %q, want b", got.Title)
+	}
+	if titles := queueTitles(p); !slices.Equal(titles, []string{"a", "c"}) {
+		t.Fatalf("queue = %v", titles)
+	}
+	if _, err := p.RemoveFromQueue(2); !errors.Is(err, ErrQueueIndexRange) {
+		t.Fatalf("err = %v, want ErrQueueIndexRange", err)
+	}
+}
+
+func TestMoveInQueue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		from, to int
+		want     []string
+	}{
+		{"forward", 0, 2, []string{"b", "c", "a", "d"}},
+		{"backward", 3, 1, []string{"a", "d", "b", "c"}},
+		{"same", 2, 2, []string{"a", "b", "c", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := newQueuedPlayer("a", "b", "c", "d")
+			if err := p.MoveInQueue(tt.from, tt.to); err != nil {
+				t.Fatal(err)
+			}
+			if titles := queueTitles(p); !slices.Equal(titles, tt.want) {
+				t.Fatalf("queue = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+
+	p := newQueuedPlayer("a")
+	if err := p.MoveInQueue(0, 1); !errors.Is(err, ErrQueueIndexRange) {
+		t.Fatalf("err = %v, want ErrQueueIndexRange", err)
+	}
+}
+
+func TestClearAndShuffleQueue(t *testing.T) {
+	t.Parallel()
+	p := newQueuedPlayer("a", "b", "c", "d", "e")
+	p.ShuffleQueue()
+	if n := len(p.Queue()); n != 5 {
+		t.Fatalf("shuffle changed length to %d", n)
+	}
+	if n := p.ClearQueue(); n != 5 {
+		t.Fatalf("ClearQueue = %d, want 5", n)
+	}
+	if n := len(p.Queue()); n != 0 {
+		t.Fatalf("queue len after clear = %d", n)
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	t.Parallel()
+	p := New(nil, nil)
+	if err := p.Pause(); !errors.Is(err, ErrNoTrackPlaying) {
+		t.Fatalf("Pause idle: err = %v, want ErrNoTrackPlaying", err)
+	}
+
+	p.playing = true
+	p.currTrack = &parsers.TrackParse{Title: "a"}
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	if !p.IsPaused() || !p.IsPlaying() {
+		t.Fatalf("paused=%v playing=%v, want both true", p.IsPaused(), p.IsPlaying())
+	}
+	if err := p.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	if p.IsPaused() {
+		t.Fatal("still paused after Resume")
+	}
+	if err := p.Resume(); !errors.Is(err, ErrNotPaused) {
+		t.Fatalf("Resume twice: err = %v, want ErrNotPaused", err)
+	}
+}
+
+func TestPlaybackReaderBlocksWhilePaused(t *testing.T) {
+	t.Parallel()
+	p := New(nil, nil)
+	p.playing = true
+	p.currTrack = &parsers.TrackParse{Title: "a"}
+
+	stop := make(chan struct{})
+	pcm := make([]byte, bytesPerSecond*2)
+	r := &playbackReader{p: p, src: io.NopCloser(bytes.NewReader(pcm)), stop: stop, offset: 10 * time.Second}
+
+	buf := make([]byte, bytesPerSecond)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.position(); got != 11*time.Second {
+		t.Fatalf("position = %v, want 11s", got)
+	}
+
+	if err := p.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Read(buf)
+		done <- err
+	}()
+	select {
+	case <-done:
+		t.Fatal("read returned while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(stop)
+	if err := <-done; !errors.Is(err, stream.ErrPlaybackStopped) {
+		t.Fatalf("err = %v, want ErrPlaybackStopped", err)
+	}
+}
+
+type stubAutoplay struct {
+	track sources.TrackInfo
+	ok    bool
+}
+
+func (s stubAutoplay) NextAutoplay(string, parsers.TrackParse) (sources.TrackInfo, bool) {
+	return s.track, s.ok
+}
+
+func TestRequeueFinished(t *testing.T) {
+	t.Parallel()
+	suggestion := sources.TrackInfo{Title: "auto", AvailableParsers: []string{"ytdlp-link"}}
+	tests := []struct {
+		name     string
+		mode     LoopMode
+		queued   []string
+		autoplay AutoplaySource
+		want     []string
+	}{
+		{"off", LoopOff, []string{"b"}, nil, []string{"b"}},
+		{"track", LoopTrack, []string{"b"}, nil, []string{"done", "b"}},
+		{"queue", LoopQueue, []string{"b"}, nil, []string{"b", "done"}},
+		{"autoplay empty queue", LoopAutoplay, nil, stubAutoplay{suggestion, true}, []string{"auto"}},
+		{"autoplay non-empty queue", LoopAutoplay, []string{"b"}, stubAutoplay{suggestion, true}, []string{"b"}},
+		{"autoplay no suggestion", LoopAutoplay, nil, stubAutoplay{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := newQueuedPlayer(tt.queued...)
+			p.SetLoopMode(tt.mode)
+			p.SetAutoplaySource(tt.autoplay)
+			p.requeueFinished(parsers.TrackParse{Title: "done"})
+			if titles := queueTitles(p); !slices.Equal(titles, tt.want) {
+				t.Fatalf("queue = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLoopMode(t *testing.T) {
+	t.Parallel()
+	for in, want := range map[string]LoopMode{"": LoopOff, "Track": LoopTrack, " queue ": LoopQueue, "autoplay": LoopAutoplay} {
+		if got, err := ParseLoopMode(in); err != nil || got != want {
+			t.Fatalf("ParseLoopMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseLoopMode("forever"); !errors.Is(err, ErrUnknownLoopMode) {
+		t.Fatalf("err = %v, want ErrUnknownLoopMode", err)
+	}
+}
+
+func TestSetStreamTitleIgnoresStaleTrack(t *testing.T) {
+	p := New(nil, nil)
+	current := &parsers.TrackParse{Title: "Station"}
+	stale := &parsers.TrackParse{Title: "Old station"}
+	p.currTrack = current
+
+	p.setStreamTitle(stale, "Old song")
+	if got := p.StreamTitle(); got != "" {
+		t.Fatalf("stale title leaked: %q", got)
+	}
+	p.setStreamTitle(current, "Artist - Song")
+	if got := p.StreamTitle(); got != "Artist - Song" {
+		t.Fatalf("StreamTitle = %q", got)
+	}
+	if st := <-p.PlayerStatus; st != StatusStreamTitle {
+		t.Fatalf("status = %q", st)
+	}
+}
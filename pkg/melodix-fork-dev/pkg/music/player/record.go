@@ -0,0 +1,75 @@
+package player
+
+import (
+	"errors"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// EndReason says why a track stopped playing.
+type EndReason string
+
+const (
+	// EndFinished means the track played to its end.
+	EndFinished EndReason = "finished"
+	// EndSkipped means playback was stopped early (skip, stop or disconnect).
+	EndSkipped EndReason = "skipped"
+	// EndError means the track failed to start or broke off with an error.
+	EndError EndReason = "error"
+)
+
+// PlaybackRecord describes one play of a track, as reported to the PlaybackRecorder when it ends.
+type PlaybackRecord struct {
+	Track     parsers.TrackParse
+	StartedAt time.Time
+	// ChannelID is the target the track played in (voice channel ID for Discord).
+	ChannelID string
+	// Listened is the audio actually streamed: pauses and parts skipped by Seek are not counted.
+	Listened  time.Duration
+	EndReason EndReason
+}
+
+func endReasonFor(err error) EndReason {
+	switch {
+	case err == nil:
+		return EndFinished
+	case errors.Is(err, stream.ErrPlaybackStopped):
+		return EndSkipped
+	default:
+		return EndError
+	}
+}
+
+// endRun adds a playback run's streamed audio to the active play and ends the play, unless the run
+// was only interrupted by Seek (the play then continues in the next run).
+func (p *Player) endRun(reader *playbackReader, err error) {
+	p.mu.Lock()
+	seeking := p.seeking
+	if p.active != nil {
+		p.active.Listened += reader.played()
+	}
+	p.mu.Unlock()
+	if seeking {
+		return
+	}
+	p.endPlay(endReasonFor(err))
+}
+
+// endPlay reports the active play (if any) to the recorder.
+func (p *Player) endPlay(reason EndReason) {
+	p.mu.Lock()
+	play := p.active
+	p.active = nil
+	gid := p.guildID
+	rec := p.recorder
+	p.mu.Unlock()
+	if play == nil {
+		return
+	}
+	play.EndReason = reason
+	if rec != nil && gid != "" {
+		rec.Record(gid, *play)
+	}
+}
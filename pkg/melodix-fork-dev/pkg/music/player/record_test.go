@@ -0,0 +1,66 @@
+package player
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+type recorderFunc func(guildID string, play PlaybackRecord)
+
+func (f recorderFunc) Record(guildID string, play PlaybackRecord) { f(guildID, play) }
+
+func readerWithPlayed(d time.Duration) *playbackReader {
+	r := &playbackReader{}
+	r.read.Store(int64(d.Seconds() * bytesPerSecond))
+	return r
+}
+
+func TestEndRunAccumulatesAcrossSeek(t *testing.T) {
+	t.Parallel()
+	var got []PlaybackRecord
+	p := New(nil, nil)
+	p.SetGuildID("g")
+	p.SetRecorder(recorderFunc(func(_ string, play PlaybackRecord) { got = append(got, play) }))
+	started := time.Now()
+	p.active = &PlaybackRecord{Track: parsers.TrackParse{Title: "a"}, ChannelID: "vc", StartedAt: started}
+
+	p.seeking = true
+	p.endRun(readerWithPlayed(10*time.Second), stream.ErrPlaybackStopped)
+	if len(got) != 0 {
+		t.Fatalf("seek ended the play: %+v", got)
+	}
+	if !p.PlayStartedAt().Equal(started) {
+		t.Fatalf("PlayStartedAt = %v after seek, want %v", p.PlayStartedAt(), started)
+	}
+
+	p.seeking = false
+	p.endRun(readerWithPlayed(5*time.Second), nil)
+	if len(got) != 1 {
+		t.Fatalf("recorded %d plays, want 1", len(got))
+	}
+	if got[0].Listened != 15*time.Second || got[0].EndReason != EndFinished || got[0].ChannelID != "vc" {
+		t.Fatalf("play = %+v", got[0])
+	}
+	if p.active != nil {
+		t.Fatal("active play not cleared")
+	}
+}
+
+func TestEndReasonFor(t *testing.T) {
+	t.Parallel()
+	cases := map[error]EndReason{
+		nil:                       EndFinished,
+		stream.ErrPlaybackStopped: EndSkipped,
+		errors.New("boom"):        EndError,
+		stream.ErrVoiceTransport:  EndError,
+	}
+	for err, want := range cases {
+		if got := endReasonFor(err); got != want {
+			t.Fatalf("endReasonFor(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package player
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// MaxCrossfade bounds the crossfade length accepted by SetTransitions.
+const MaxCrossfade = 12 * time.Second
+
+// preloadLead is how long before a transition the next track's stream is opened, so slow resolvers
+// (yt-dlp) are done before the current track ends.
+const preloadLead = 10 * time.Second
+
+// preload is the head of the queue opened ahead of time for a gapless or crossfaded transition.
+type preload struct {
+	track *parsers.TrackParse
+	// ready is closed once the open attempt finished; rs and err are set before.
+	ready chan struct{}
+	rs    *stream.RecoveryStream
+	err   error
+	// read counts PCM bytes consumed by a crossfade before the track took over.
+	read atomic.Int64
+	// dropped tells the open goroutine to close the stream itself (guarded by Player.mu).
+	dropped bool
+}
+
+func (pl *preload) Read(b []byte) (int, error) {
+	n, err := pl.rs.Read(b)
+	pl.read.Add(int64(n))
+	return n, err
+}
+
+func (pl *preload) played() time.Duration {
+	return time.Duration(float64(pl.read.Load()) / bytesPerSecond * float64(time.Second))
+}
+
+func (pl *preload) opened() bool {
+	select {
+	case <-pl.ready:
+		return pl.err == nil
+	default:
+		return false
+	}
+}
+
+// SetTransitions sets how finished tracks hand over to the next one. With gapless (or any crossfade) the next
+// track is opened before the current one ends and the sink keeps its encoder across the join; crossfade
+// additionally overlaps the last crossfade of the track with the start of the next. Both need a known track
+// duration to plan the handover; live streams and tracks without one just start back to back.
+func (p *Player) SetTransitions(crossfade time.Duration, gapless bool) {
+	crossfade = min(max(crossfade, 0), MaxCrossfade)
+	p.mu.Lock()
+	p.crossfade = crossfade
+	p.gapless = gapless
+	p.mu.Unlock()
+	p.log.Info().Dur("crossfade", crossfade).Bool("gapless", gapless).Msg("transitions_set")
+}
+
+// Transitions returns the crossfade length and whether gapless playback is on.
+func (p *Player) Transitions() (crossfade time.Duration, gapless bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.crossfade, p.gapless
+}
+
+func (p *Player) transitionsLocked() bool {
+	return p.gapless || p.crossfade > 0
+}
+
+// onProgress runs after every read of the sink from r: it opens the next track once the current one is within
+// preloadLead (plus crossfade) of its end, and hands it to the sink when the crossfade should begin.
+func (p *Player) onProgress(r *playbackReader) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.transitionsLocked() || r.handedOff || p.reader != r || p.currTrack == nil || p.currTrack.Duration <= 0 {
+		return
+	}
+	remaining := p.currTrack.Duration - r.position()
+
+	if p.preload == nil {
+		if remaining > r.crossfade+preloadLead || len(p.queue) == 0 || p.loopMode == LoopTrack || p.seeking {
+			return
+		}
+		p.startPreloadLocked(p.queue[0])
+		return
+	}
+
+	if r.crossfade > 0 && remaining <= r.crossfade && p.preload.opened() {
+		r.handedOff = true
+		r.next <- p.preload
+		p.log.Info().Str("title", p.preload.track.Title).Dur("remaining", remaining).Msg("crossfade_handover")
+	}
+}
+
+// startPreloadLocked opens next in the background. Caller must hold p.mu.
+func (p *Player) startPreloadLocked(next parsers.TrackParse) {
+	t := cloneTrackParse(next)
+	t.LocalRoot = p.localRoot
+	pl := &preload{track: &t, ready: make(chan struct{})}
+	p.preload = pl
+	p.log.Info().Str("title", t.Title).Msg("preload_started")
+
+	go func() {
+		rs := stream.NewRecoveryStreamWithLogger(pl.track, p.log)
+		err := rs.Open(0)
+
+		p.mu.Lock()
+		pl.rs, pl.err = rs, err
+		close(pl.ready)
+		dropped := pl.dropped
+		p.mu.Unlock()
+
+		if err != nil {
+			p.log.Warn().Str("title", pl.track.Title).Err(err).Msg("preload_failed")
+			return
+		}
+		if dropped {
+			_ = rs.Close()
+		}
+	}()
+}
+
+// dropPreloadLocked discards the preloaded track, closing its stream. Only call it while no sink reads the
+// stream (after the playback run ended). Caller must hold p.mu.
+func (p *Player) dropPreloadLocked() {
+	pl := p.preload
+	if pl == nil {
+		return
+	}
+	p.preload = nil
+	select {
+	case <-pl.ready:
+		if pl.err == nil {
+			_ = pl.rs.Close()
+		}
+	default:
+		pl.dropped = true
+	}
+}
+
+// takePreload returns the preloaded stream if it belongs to track (the dequeued head of the queue); otherwise
+// the preload is discarded and nil returned. It waits for an open still in progress.
+func (p *Player) takePreload(track parsers.TrackParse) *preload {
+	p.mu.Lock()
+	pl := p.preload
+	p.preload = nil
+	p.mu.Unlock()
+	if pl == nil {
+		return nil
+	}
+
+	<-pl.ready
+	if pl.err != nil {
+		return nil
+	}
+	if pl.track.URL != track.URL || pl.track.RequestedBy != track.RequestedBy {
+		p.log.Info().Str("title", pl.track.Title).Msg("preload_discarded")
+		_ = pl.rs.Close()
+		return nil
+	}
+	return pl
+}
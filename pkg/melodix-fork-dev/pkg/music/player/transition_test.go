@@ -0,0 +1,73 @@
+package player
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/parsers"
+	"github.com/keshon/melodix/pkg/music/stream"
+)
+
+// openedPreload returns a preload for track that reports a finished, successful open.
+func openedPreload(track parsers.TrackParse) *preload {
+	pl := &preload{track: &track, ready: make(chan struct{}), rs: stream.NewRecoveryStream(&track)}
+	close(pl.ready)
+	return pl
+}
+
+func TestCrossfadeHandsOverInTail(t *testing.T) {
+	t.Parallel()
+	p := New(nil, nil)
+	p.SetTransitions(2*time.Second, false)
+	p.playing = true
+	p.currTrack = &parsers.TrackParse{Title: "a", Duration: 3 * time.Second}
+	p.preload = openedPreload(parsers.TrackParse{Title: "b"})
+
+	pcm := make([]byte, bytesPerSecond*3)
+	r := &playbackReader{
+		p:         p,
+		src:       io.NopCloser(bytes.NewReader(pcm)),
+		stop:      make(chan struct{}),
+		crossfade: 2 * time.Second,
+		next:      make(chan io.Reader, 1),
+	}
+	p.reader = r
+
+	half := make([]byte, bytesPerSecond/2)
+	if _, err := io.ReadFull(r, half); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.next) != 0 {
+		t.Fatal("handed over 2.5s before the end, want within the 2s crossfade")
+	}
+	if _, err := io.ReadFull(r, make([]byte, bytesPerSecond)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case next := <-r.next:
+		if next.(*preload).track.Title != "b" {
+			t.Fatalf("handed over %q", next.(*preload).track.Title)
+		}
+	default:
+		t.Fatal("no handover inside the crossfade window")
+	}
+}
+
+func TestTakePreloadChecksQueueHead(t *testing.T) {
+	t.Parallel()
+	p := New(nil, nil)
+	p.preload = openedPreload(parsers.TrackParse{URL: "https://x.test/b", Title: "b"})
+	if pl := p.takePreload(parsers.TrackParse{URL: "https://x.test/c"}); pl != nil {
+		t.Fatal("preload of another track was used")
+	}
+	if p.preload != nil {
+		t.Fatal("mismatched preload was kept")
+	}
+
+	p.preload = openedPreload(parsers.TrackParse{URL: "https://x.test/b", Title: "b"})
+	if pl := p.takePreload(parsers.TrackParse{URL: "https://x.test/b"}); pl == nil || pl.track.Title != "b" {
+		t.Fatalf("takePreload = %+v, want the preloaded b", pl)
+	}
+}
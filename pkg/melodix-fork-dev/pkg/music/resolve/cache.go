@@ -0,0 +1,148 @@
+package resolve
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// Cache keeps resolver results by key (see CacheKey) so repeated lookups skip the network.
+type Cache interface {
+	Get(key string) ([]sources.TrackInfo, bool)
+	Put(key string, tracks []sources.TrackInfo)
+}
+
+// CacheKey builds the cache key of a lookup: kind ("resolve" or "search"), the selected source and
+// parser, and input normalised so the same query or URL typed differently shares an entry. Queries are
+// case-folded with whitespace collapsed; URLs keep their case (video IDs are case-sensitive) but get a
+// lower-case host and lose their fragment.
+func CacheKey(kind, source, parser, input string) string {
+	input = strings.TrimSpace(input)
+	if isURL(input) {
+		if u, err := url.Parse(input); err == nil {
+			u.Host = strings.ToLower(u.Host)
+			u.Fragment = ""
+			input = u.String()
+		}
+	} else {
+		input = strings.ToLower(strings.Join(strings.Fields(input), " "))
+	}
+	return strings.Join([]string{kind, source, parser, input}, "\x00")
+}
+
+// FileCache is a Cache stored as one JSON file per key in a directory. Entries older than the TTL are
+// misses and are removed when read; Put also sweeps the directory once per TTL, removing expired entries
+// that are never read again and temp files left by an interrupted write. Write errors are ignored: a
+// cache that cannot be written only costs a lookup.
+type FileCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+
+	mu     sync.Mutex
+	pruned time.Time // last sweep; zero until the first Put
+}
+
+// NewFileCache returns a cache in dir (created on the first Put) whose entries live for ttl.
+func NewFileCache(dir string, ttl time.Duration) *FileCache {
+	return &FileCache{dir: dir, ttl: ttl, now: time.Now}
+}
+
+type cacheEntry struct {
+	Stored time.Time           `json:"stored"`
+	Tracks []sources.TrackInfo `json:"tracks"`
+}
+
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *FileCache) Get(key string) ([]sources.TrackInfo, bool) {
+	path := c.path(key)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(b, &e); err != nil || len(e.Tracks) == 0 || c.now().Sub(e.Stored) > c.ttl {
+		_ = os.Remove(path)
+		return nil, false
+	}
+	return e.Tracks, true
+}
+
+func (c *FileCache) Put(key string, tracks []sources.TrackInfo) {
+	if len(tracks) == 0 {
+		return
+	}
+	b, err := json.Marshal(cacheEntry{Stored: c.now(), Tracks: tracks})
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return
+	}
+	c.pruneIfDue()
+	// Write then rename, so a concurrent Get never reads half an entry.
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(b)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil || os.Rename(tmp.Name(), c.path(key)) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// staleTempAge is how old an entry-*.tmp file must be before a sweep takes it for a leftover; a Put
+// renames or removes its own within moments.
+const staleTempAge = time.Minute
+
+// pruneIfDue sweeps the directory if the last sweep is at least a TTL ago.
+func (c *FileCache) pruneIfDue() {
+	now := c.now()
+	c.mu.Lock()
+	if !c.pruned.IsZero() && now.Sub(c.pruned) < c.ttl {
+		c.mu.Unlock()
+		return
+	}
+	c.pruned = now
+	c.mu.Unlock()
+	c.prune(now)
+}
+
+// prune removes entries stored more than a TTL before now (judged by modification time, which is
+// when Put wrote them) and stale temp files.
+func (c *FileCache) prune(now time.Time) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		name := e.Name()
+		var maxAge time.Duration
+		switch {
+		case strings.HasSuffix(name, ".json"):
+			maxAge = c.ttl
+		case strings.HasPrefix(name, "entry-") && strings.HasSuffix(name, ".tmp"):
+			maxAge = staleTempAge
+		default:
+			continue
+		}
+		info, err := e.Info()
+		if err != nil || now.Sub(info.ModTime()) <= maxAge {
+			continue
+		}
+		_ = os.Remove(filepath.Join(c.dir, name))
+	}
+}
@@ -0,0 +1,137 @@
+package resolve
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+)
+
+// countingSource is a YouTube stand-in that counts its lookups.
+type countingSource struct {
+	resolves, searches int
+}
+
+func (s *countingSource) Match(input string) bool    { return false }
+func (s *countingSource) SourceName() string         { return sources.YouTube }
+func (s *countingSource) AvailableParsers() []string { return []string{"ytdlp-pipe"} }
+
+func (s *countingSource) Resolve(input string, selectedParser string) ([]sources.TrackInfo, error) {
+	s.resolves++
+	return []sources.TrackInfo{{URL: "https://example.com/1", Title: input, SourceName: sources.YouTube}}, nil
+}
+
+func (s *countingSource) Search(query string, selectedParser string, limit int) ([]sources.TrackInfo, error) {
+	s.searches++
+	var out []sources.TrackInfo
+	for i := range limit {
+		out = append(out, sources.TrackInfo{URL: "https://example.com/" + string(rune('a'+i)), SourceName: sources.YouTube})
+	}
+	return out, nil
+}
+
+func TestCacheKeyNormalisesInput(t *testing.T) {
+	same := [][2]string{
+		{"  Daft   Punk  Around ", "daft punk around"},
+		{" https://WWW.YouTube.com/watch?v=AbC#t=10", "https://www.youtube.com/watch?v=AbC"},
+	}
+	for _, pair := range same {
+		if CacheKey("resolve", "", "", pair[0]) != CacheKey("resolve", "", "", pair[1]) {
+			t.Fatalf("%q and %q should share a key", pair[0], pair[1])
+		}
+	}
+	if CacheKey("resolve", "", "", "https://youtu.be/AbC") == CacheKey("resolve", "", "", "https://youtu.be/abc") {
+		t.Fatal("URL paths must keep their case")
+	}
+	if CacheKey("resolve", "youtube", "", "x") == CacheKey("search", "youtube", "", "x") {
+		t.Fatal("kinds must not share keys")
+	}
+}
+
+func TestFileCacheExpires(t *testing.T) {
+	c := NewFileCache(t.TempDir(), time.Hour)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	tracks := []sources.TrackInfo{{URL: "https://example.com/1", Title: "One"}}
+	c.Put("k", tracks)
+	got, ok := c.Get("k")
+	if !ok || len(got) != 1 || got[0].URL != tracks[0].URL || got[0].Title != tracks[0].Title {
+		t.Fatalf("Get = %+v, %v", got, ok)
+	}
+	if _, ok := c.Get("other"); ok {
+		t.Fatal("unknown key was a hit")
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expired entry was a hit")
+	}
+}
+
+func TestFileCachePutPrunesStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	c := NewFileCache(dir, time.Hour)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	tracks := []sources.TrackInfo{{URL: "https://example.com/1"}}
+	c.Put("old", tracks)
+	leftover := filepath.Join(dir, "entry-123.tmp")
+	if err := os.WriteFile(leftover, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Within the TTL nothing is swept, however many entries are written.
+	c.Put("fresh", tracks)
+	if _, err := os.Stat(c.path("old")); err != nil {
+		t.Fatal("entry removed before it expired")
+	}
+
+	now = now.Add(2 * time.Hour)
+	c.Put("new", tracks)
+	for _, path := range []string{c.path("old"), c.path("fresh"), leftover} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Fatalf("%s survived the sweep", filepath.Base(path))
+		}
+	}
+	for _, path := range []string{c.path("new"), other} {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("%s was swept: %v", filepath.Base(path), err)
+		}
+	}
+}
+
+func TestResolverUsesCache(t *testing.T) {
+	src := &countingSource{}
+	r := &Resolver{Sources: map[string]sources.Source{sources.YouTube: src}}
+	r.SetCache(NewFileCache(t.TempDir(), time.Hour))
+
+	for _, q := range []string{"some song", "Some  Song"} {
+		if _, err := r.Resolve(q, "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if src.resolves != 1 {
+		t.Fatalf("resolves = %d, want 1", src.resolves)
+	}
+
+	for range 2 {
+		tracks, err := r.Search("some song", "", "", 3)
+		if err != nil || len(tracks) != 3 {
+			t.Fatalf("Search = %d tracks, %v", len(tracks), err)
+		}
+	}
+	if _, err := r.Search("some song", "", "", 5); err != nil {
+		t.Fatal(err)
+	}
+	if src.searches != 2 {
+		t.Fatalf("searches = %d, want 2 (one per limit)", src.searches)
+	}
+}
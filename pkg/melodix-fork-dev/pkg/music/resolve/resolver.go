@@ -0,0 +1,174 @@
+// Package resolve resolves URLs and search queries to track metadata using configurable sources (YouTube, SoundCloud, radio,
+// and local files when a library root is added).
+package resolve
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/keshon/melodix/pkg/music/sources"
+	"github.com/keshon/melodix/pkg/music/sources/radio"
+	"github.com/keshon/melodix/pkg/music/sources/soundcloud"
+	"github.com/keshon/melodix/pkg/music/sources/youtube"
+)
+
+type Resolver struct {
+	Sources map[string]sources.Source
+	cache   Cache
+}
+
+func New() *Resolver {
+	youtubeSource := youtube.New()
+	soundcloudSource := soundcloud.New()
+	radioSource := radio.New()
+
+	return &Resolver{
+		Sources: map[string]sources.Source{
+			youtubeSource.SourceName():    youtubeSource,
+			soundcloudSource.SourceName(): soundcloudSource,
+			radioSource.SourceName():      radioSource,
+		},
+	}
+}
+
+// AddSource registers src under its SourceName, replacing any source with the same name
+// (e.g. local.New(root) to enable a file library).
+func (r *Resolver) AddSource(src sources.Source) {
+	r.Sources[src.SourceName()] = src
+}
+
+// SetCache makes Resolve and Search reuse results stored in c. Server library files are never cached,
+// as they change on disk.
+func (r *Resolver) SetCache(c Cache) {
+	r.cache = c
+}
+
+// Resolve turns a URL or title query into tracks, from the cache when possible.
+func (r *Resolver) Resolve(input, selectedSource, selectedParser string) ([]sources.TrackInfo, error) {
+	return r.cached(CacheKey("resolve", selectedSource, selectedParser, input), selectedSource, func() ([]sources.TrackInfo, error) {
+		return r.resolve(input, selectedSource, selectedParser)
+	})
+}
+
+// Search returns up to limit tracks matching the title query, best first, from the cache when possible.
+// selectedSource defaults to YouTube; a source without multi-result search (sources.Searcher) returns
+// its single best match.
+func (r *Resolver) Search(query, selectedSource, selectedParser string, limit int) ([]sources.TrackInfo, error) {
+	if isURL(query) || selectedSource == sources.Local || selectedSource == sources.Radio {
+		return r.Resolve(query, selectedSource, selectedParser)
+	}
+	if selectedSource == "" {
+		selectedSource = sources.YouTube
+	}
+	src, ok := r.Sources[selectedSource]
+	if !ok {
+		return nil, errors.New("unknown source: " + selectedSource)
+	}
+	searcher, ok := src.(sources.Searcher)
+	if !ok {
+		return r.Resolve(query, selectedSource, selectedParser)
+	}
+	key := CacheKey("search", selectedSource, selectedParser, query) + "\x00" + strconv.Itoa(limit)
+	return r.cached(key, selectedSource, func() ([]sources.TrackInfo, error) {
+		return searcher.Search(query, selectedParser, limit)
+	})
+}
+
+func (r *Resolver) cached(key, selectedSource string, lookup func() ([]sources.TrackInfo, error)) ([]sources.TrackInfo, error) {
+	if r.cache == nil || selectedSource == sources.Local {
+		return lookup()
+	}
+	if tracks, ok := r.cache.Get(key); ok {
+		return tracks, nil
+	}
+	tracks, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tracks {
+		if t.SourceName == sources.Local {
+			return tracks, nil
+		}
+	}
+	r.cache.Put(key, tracks)
+	return tracks, nil
+}
+
+func (r *Resolver) resolve(input, selectedSource, selectedParser string) ([]sources.TrackInfo, error) {
+	// Direct source selection
+	if selectedSource != "" {
+		src, ok := r.Sources[selectedSource]
+		if !ok {
+			return nil, errors.New("unknown source: " + selectedSource)
+		}
+		selectedParser, err := ensureParser(src, selectedParser)
+		if err != nil {
+			return nil, err
+		}
+
+		if !isURL(input) && selectedSource != sources.Local {
+			if selectedSource != sources.YouTube && selectedSource != sources.SoundCloud {
+				return nil, errors.New("title search is only supported on " + sources.YouTube + " and " + sources.SoundCloud)
+			}
+			return src.Resolve(input, selectedParser)
+		}
+		if !src.Match(input) {
+			return nil, errors.New("input does not match selected source: " + selectedSource)
+		}
+		return src.Resolve(input, selectedParser)
+	}
+
+	// Automatic detection
+	if localSrc, ok := r.Sources[sources.Local]; ok && localSrc.Match(input) {
+		selectedParser, err := ensureParser(localSrc, selectedParser)
+		if err != nil {
+			return nil, err
+		}
+		return localSrc.Resolve(input, selectedParser)
+	}
+	if !isURL(input) {
+		yt, ok := r.Sources[sources.YouTube]
+		if !ok {
+			return nil, errors.New(youtube.Name + " source not available for title search")
+		}
+		selectedParser, err := ensureParser(yt, selectedParser)
+		if err != nil {
+			return nil, err
+		}
+		return yt.Resolve(input, selectedParser)
+	}
+
+	for typ, s := range r.Sources {
+		if typ == sources.Radio || typ == sources.Local {
+			continue
+		}
+		if s.Match(input) {
+			selectedParser, err := ensureParser(s, selectedParser)
+			if err != nil {
+				return nil, err
+			}
+			return s.Resolve(input, selectedParser)
+		}
+	}
+
+	if radioSrc, ok := r.Sources[sources.Radio]; ok {
+		selectedParser, err := ensureParser(radioSrc, selectedParser)
+		if err != nil {
+			return nil, err
+		}
+		return radioSrc.Resolve(input, selectedParser)
+	}
+
+	return nil, errors.New("no matching source found")
+}
+
+func ensureParser(src sources.Source, selected string) (string, error) {
+	if selected != "" {
+		return selected, nil
+	}
+	parsers := src.AvailableParsers()
+	if len(parsers) == 0 {
+		return "", errors.New("no parsers available for " + src.SourceName())
+	}
+	return parsers[0], nil
+}
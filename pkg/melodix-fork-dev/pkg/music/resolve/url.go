@@ -0,0 +1,7 @@
+package resolve
+
+import "strings"
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
@@ -0,0 +1,53 @@
+// Package sink defines interfaces and implementations for consuming PCM audio (e.g. speaker, or custom Discord sink).
+package sink
+
+import (
+	"io"
+	"time"
+)
+
+// AudioSink consumes a PCM stream (e.g. encode-and-send to Discord VC, or play to speaker).
+// The sink owns the read loop; Stream returns when the stream ends or stop is closed.
+type AudioSink interface {
+	Stream(stream io.ReadCloser, stop <-chan struct{}) error
+}
+
+// Transition describes how a track joins the one before it and the one after it.
+type Transition struct {
+	// Continued means the stream directly follows the previous Stream call's track (it finished naturally):
+	// the sink keeps its encoder state and does not trim leading silence, so the join is gapless.
+	Continued bool
+	// Crossfade is how long the tail of the stream overlaps the next track; 0 disables mixing.
+	Crossfade time.Duration
+	// Next delivers the next track's PCM stream, already opened, once the current stream is within Crossfade
+	// of its end. Nil or silent when there is nothing to mix in.
+	Next <-chan io.Reader
+}
+
+// TransitionSink is an AudioSink that can join tracks without a gap. StreamTransition behaves like Stream;
+// in addition, once a reader arrives on t.Next the sink mixes it in, fading the current stream out and the next
+// one in over t.Crossfade. The next reader is left partly read: the player streams the rest of it in the
+// following call (with Continued set). Sinks that do not implement it get plain Stream calls.
+type TransitionSink interface {
+	AudioSink
+	StreamTransition(stream io.ReadCloser, stop <-chan struct{}, t Transition) error
+}
+
+// Provider returns an AudioSink for a given target.
+// For Discord, target is the voice channel ID; for CLI, target is typically "".
+type Provider interface {
+	Sink(target string) (AudioSink, error)
+	// ReleaseSink is called when the player disconnects (e.g. Stop(true)).
+	// Discord uses it to leave the voice channel; CLI can no-op.
+	ReleaseSink(target string)
+	// InvalidateSink drops any cached voice/transport state so the next Sink re-acquires it
+	// (e.g. after gateway reconnect or Opus send failure).
+	InvalidateSink()
+}
+
+// RecoveryObserver is a Provider that wants to know how the player recovered from a voice transport error
+// (stream.ErrVoiceTransport) on target: mode is "soft" (the stream was reopened on the same sink) or "hard"
+// (the sink was invalidated first). Providers that do not implement it are not told.
+type RecoveryObserver interface {
+	TransportRecovery(target, mode string)
+}
@@ -0,0 +1,21 @@
+package sources
+
+type Source interface {
+	// Match checks if this source can handle the given input
+	Match(input string) bool
+
+	// Resolve turns an input into one or more playable tracks
+	Resolve(input string, selectedParser string) ([]TrackInfo, error)
+
+	// SourceName returns the string identifier ("youtube", "radio", etc.)
+	SourceName() string
+
+	// AvailableParsers returns the list of parsers supported by this source
+	AvailableParsers() []string
+}
+
+// Searcher is a Source that can list several matches for a title search, best first. Sources without it
+// only offer their single best match (Resolve).
+type Searcher interface {
+	Search(query string, selectedParser string, limit int) ([]TrackInfo, error)
+}
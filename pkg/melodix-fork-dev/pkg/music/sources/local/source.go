@@ -0,0 +1,148 @@
+// Package local resolves audio files on disk, addressed by file:// URIs, below a root directory.
+package local
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	source "github.com/keshon/melodix/pkg/music/sources"
+)
+
+const Name = "local"
+
+// Scheme prefixes the URL of local tracks.
+const Scheme = "file://"
+
+// audioExtensions are the file types offered from a library directory; ffmpeg decodes all of them.
+var audioExtensions = []string{".mp3", ".ogg", ".opus", ".flac", ".wav", ".m4a", ".aac", ".webm"}
+
+type Source struct {
+	root string
+}
+
+// New returns a source that only resolves files below root, so user input cannot reach the rest of the disk.
+func New(root string) *Source {
+	return &Source{root: root}
+}
+
+func (s *Source) Match(input string) bool {
+	_, err := s.file(input)
+	return err == nil
+}
+
+func (s *Source) Resolve(input string, selectedParser string) ([]source.TrackInfo, error) {
+	parsers := s.AvailableParsers()
+
+	if selectedParser == "" {
+		selectedParser = parsers[0]
+	}
+
+	if !slices.Contains(parsers, selectedParser) {
+		return nil, errors.New(Name + " source does not support " + selectedParser + " parser")
+	}
+
+	path, err := s.file(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return []source.TrackInfo{
+		{
+			URL:              URI(path),
+			Title:            Title(path),
+			SourceName:       Name,
+			AvailableParsers: source.PreferParser(parsers, selectedParser),
+		},
+	}, nil
+}
+
+func (s *Source) SourceName() string {
+	return Name
+}
+
+func (s *Source) AvailableParsers() []string {
+	return []string{"ffmpeg-file"}
+}
+
+// file returns the absolute path of the audio file input points to, or an error when it is not a
+// file:// URI of a regular audio file inside the root.
+func (s *Source) file(input string) (string, error) {
+	path, ok := Path(input)
+	if !ok {
+		return "", errors.New("not a " + Scheme + " URI: " + input)
+	}
+	if !Within(s.root, path) {
+		return "", errors.New("file is outside the " + Name + " library")
+	}
+	if !IsAudioFile(path) {
+		return "", errors.New("unsupported audio file type: " + filepath.Ext(path))
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", errors.New("not a regular file: " + path)
+	}
+	return path, nil
+}
+
+// URI returns the file:// URI of path (made absolute).
+func URI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return Scheme + filepath.ToSlash(path)
+}
+
+// Path returns the cleaned absolute path of a file:// URI.
+func Path(uri string) (string, bool) {
+	rest, ok := strings.CutPrefix(uri, Scheme)
+	if !ok || rest == "" {
+		return "", false
+	}
+	path, err := filepath.Abs(filepath.FromSlash(rest))
+	if err != nil {
+		return "", false
+	}
+	return path, true
+}
+
+// Within reports whether path lies inside root once both are made absolute and symlinks are resolved.
+func Within(root, path string) bool {
+	root, err := resolvePath(root)
+	if err != nil {
+		return false
+	}
+	path, err = resolvePath(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+func resolvePath(p string) (string, error) {
+	p, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(p)
+}
+
+// IsAudioFile reports whether name has one of the supported audio extensions.
+func IsAudioFile(name string) bool {
+	return slices.Contains(audioExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// Title derives a display title from a file name: the base name without its extension.
+func Title(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
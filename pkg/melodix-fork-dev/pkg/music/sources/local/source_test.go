@@ -0,0 +1,71 @@
+package local
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveInsideRoot(t *testing.T) {
+	root := t.TempDir()
+	track := filepath.Join(root, "g1", "Rain Ambience.mp3")
+	writeFile(t, track)
+
+	s := New(root)
+	tracks, err := s.Resolve(URI(track), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracks) != 1 || tracks[0].Title != "Rain Ambience" || tracks[0].SourceName != Name {
+		t.Fatalf("tracks: %+v", tracks)
+	}
+	if got, _ := Path(tracks[0].URL); got != track {
+		t.Fatalf("path = %q, want %q", got, track)
+	}
+}
+
+func TestMatchRejectsOutsideRootAndNonAudio(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "library")
+	outside := filepath.Join(base, "secret.mp3")
+	notes := filepath.Join(root, "notes.txt")
+	writeFile(t, outside)
+	writeFile(t, notes)
+
+	s := New(root)
+	for _, input := range []string{
+		URI(outside),
+		Scheme + filepath.ToSlash(filepath.Join(root, "..", "secret.mp3")),
+		URI(notes),
+		"https://example.com/a.mp3",
+	} {
+		if s.Match(input) {
+			t.Fatalf("Match(%q) = true", input)
+		}
+	}
+}
+
+func TestWithinFollowsSymlinks(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "library")
+	outside := filepath.Join(base, "secret.mp3")
+	writeFile(t, outside)
+	writeFile(t, filepath.Join(root, "keep.mp3"))
+	link := filepath.Join(root, "link.mp3")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	if Within(root, link) {
+		t.Fatal("symlink escaping the root must not count as inside")
+	}
+}
@@ -0,0 +1,17 @@
+// Package sources defines the Source interface and track types used by the resolver.
+package sources
+
+const (
+	Auto       = "auto"
+	YouTube    = "youtube"
+	Radio      = "radio"
+	SoundCloud = "soundcloud"
+	Local      = "local"
+)
+
+type TrackInfo struct {
+	URL              string
+	Title            string
+	SourceName       string
+	AvailableParsers []string
+}
@@ -0,0 +1,115 @@
+package youtube
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	watchURLPattern   = regexp.MustCompile(`"url":"/watch\?v=([a-zA-Z0-9_-]{11})`)
+	videoIDPattern    = regexp.MustCompile(`^"videoId":"([a-zA-Z0-9_-]{11})"`)
+	videoTitlePattern = regexp.MustCompile(`"title":\{"runs":\[\{"text":"((?:[^"\\]|\\.)*)"`)
+	ErrNoVideoMatch   = errors.New("no video found for the given title")
+	ErrEmptyPlaylist  = errors.New("no video URLs found in the playlist")
+)
+
+// Searcher turns a text query into a YouTube watch URL.
+type Searcher struct {
+	BaseURL string
+	Client  *http.Client
+}
+
+func NewSearcher() *Searcher {
+	return &Searcher{
+		BaseURL: "https://www.youtube.com",
+		Client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+func (r *Searcher) results(query string) (string, error) {
+	searchURL := fmt.Sprintf("%s/results?search_query=%s", r.BaseURL, url.QueryEscape(query))
+
+	resp, err := r.Client.Get(searchURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("YouTube search failed with status code %v", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func (r *Searcher) SearchFirstVideoURL(query string) (string, error) {
+	body, err := r.results(query)
+	if err != nil {
+		return "", err
+	}
+
+	// Only match video IDs without playlist
+	matches := watchURLPattern.FindAllStringSubmatch(body, -1)
+	if len(matches) == 0 {
+		return "", ErrNoVideoMatch
+	}
+
+	videoID := matches[0][1] // first match only
+	resultURL := fmt.Sprintf("%s/watch?v=%s", r.BaseURL, videoID)
+	return resultURL, nil
+}
+
+// Video is one search result.
+type Video struct {
+	URL   string
+	Title string
+}
+
+// SearchVideos returns up to limit videos for query in the order YouTube ranks them.
+func (r *Searcher) SearchVideos(query string, limit int) ([]Video, error) {
+	body, err := r.results(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each result is a videoRenderer object; the text up to the next one holds its title.
+	var videos []Video
+	seen := make(map[string]bool)
+	chunks := strings.Split(body, `"videoRenderer":{`)
+	for _, chunk := range chunks[1:] {
+		if len(videos) == limit {
+			break
+		}
+		id := videoIDPattern.FindStringSubmatch(chunk)
+		if id == nil || seen[id[1]] {
+			continue
+		}
+		seen[id[1]] = true
+		title := ""
+		if m := videoTitlePattern.FindStringSubmatch(chunk); m != nil {
+			// The title is a JSON string literal; a broken escape leaves it empty.
+			_ = json.Unmarshal([]byte(`"`+m[1]+`"`), &title)
+		}
+		videos = append(videos, Video{
+			URL:   fmt.Sprintf("%s/watch?v=%s", r.BaseURL, id[1]),
+			Title: title,
+		})
+	}
+	if len(videos) == 0 {
+		return nil, ErrNoVideoMatch
+	}
+	return videos, nil
+}
@@ -0,0 +1,53 @@
+package youtube
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const resultsPage = `<script>var ytInitialData = {"contents":[` +
+	`{"videoRenderer":{"videoId":"aaaaaaaaaaa","thumbnail":{},"title":{"runs":[{"text":"First \"song\" & more"}]}}},` +
+	`{"videoRenderer":{"videoId":"bbbbbbbbbbb","title":{"runs":[{"text":"AC\/DC live"}]}}},` +
+	`{"videoRenderer":{"videoId":"aaaaaaaaaaa","title":{"runs":[{"text":"duplicate"}]}}},` +
+	`{"videoRenderer":{"videoId":"ccccccccccc","title":{"runs":[{"text":"Third"}]}}}` +
+	`]};</script>`
+
+func TestSearchVideos(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/results" || r.URL.Query().Get("search_query") != "some song" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(resultsPage))
+	}))
+	defer srv.Close()
+	s := &Searcher{BaseURL: srv.URL, Client: srv.Client()}
+
+	videos, err := s.SearchVideos("some song", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Video{
+		{URL: srv.URL + "/watch?v=aaaaaaaaaaa", Title: `First "song" & more`},
+		{URL: srv.URL + "/watch?v=bbbbbbbbbbb", Title: "AC/DC live"},
+	}
+	if len(videos) != len(want) {
+		t.Fatalf("got %d videos, want %d", len(videos), len(want))
+	}
+	for i := range want {
+		if videos[i] != want[i] {
+			t.Fatalf("video %d = %+v, want %+v", i, videos[i], want[i])
+		}
+	}
+
+	// Duplicates are skipped, so the third distinct video is still found.
+	videos, err = s.SearchVideos("some song", 5)
+	if err != nil || len(videos) != 3 || videos[2].Title != "Third" {
+		t.Fatalf("videos = %+v, err = %v", videos, err)
+	}
+
+	if _, err := s.SearchVideos("nothing", 5); err == nil {
+		t.Fatal("expected an error for a page without results")
+	}
+}